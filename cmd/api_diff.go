@@ -0,0 +1,71 @@
+// cmd/api_diff.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/soyuz43/prbuddy-go/internal/apidiff"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apiDiffBase            string
+	apiDiffIncludeInternal bool
+	apiDiffJSON            bool
+)
+
+var apiDiffCmd = &cobra.Command{
+	Use:   "api-diff",
+	Short: "Report breaking changes to the exported Go API since the base branch",
+	Long: `Compares the exported API of every library package at the merge-base of the base
+branch and HEAD. Removed or renamed functions and types, changed signatures, removed
+or retyped struct fields and interface method-set changes are reported as breaking.
+
+Exits with status 1 when breaking changes are found and 2 on error, so it can gate CI.
+PR drafts get a Breaking Changes section when PRBUDDY_API_DIFF=1 is set for the
+post-commit hook.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		base := apiDiffBase
+		if base == "" {
+			var err error
+			if base, err = apidiff.DefaultBase(); err != nil {
+				fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Error: %v\n", err)
+				os.Exit(2)
+			}
+		}
+
+		report, err := apidiff.Diff(base, apidiff.Options{IncludeInternal: apiDiffIncludeInternal})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		if apiDiffJSON {
+			out, err := utils.MarshalJSON(report)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Error: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Println(out)
+		} else if report.HasBreakingChanges() {
+			fmt.Print(report.Markdown())
+		} else {
+			fmt.Printf("[PRBuddy-Go] No breaking API changes since %s.\n", base)
+		}
+
+		if report.HasBreakingChanges() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	apiDiffCmd.Flags().StringVar(&apiDiffBase, "base", "", "Base branch to compare against (default: origin's HEAD, main or master)")
+	apiDiffCmd.Flags().BoolVar(&apiDiffIncludeInternal, "include-internal", false, "Also compare packages under internal/")
+	apiDiffCmd.Flags().BoolVar(&apiDiffJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(apiDiffCmd)
+}
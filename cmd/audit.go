@@ -0,0 +1,105 @@
+// cmd/audit.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/audit"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditLimit int
+	auditJSON  bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of LLM calls",
+	Long: `Every LLM call is recorded in .git/pr_buddy_db/audit/ with its command, model,
+endpoint, prompt and response hashes, token counts, latency and the redactions applied.
+Set ` + audit.PayloadsEnv + `=1 to also record the full prompts and responses.`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent LLM calls",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := audit.List()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if auditLimit > 0 && len(entries) > auditLimit {
+			entries = entries[len(entries)-auditLimit:]
+		}
+
+		if auditJSON {
+			out, err := utils.MarshalJSON(entries)
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Println(out)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("[PRBuddy-Go] No LLM calls recorded yet.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTIME\tCOMMAND\tMODEL\tTOKENS (IN/OUT)\tLATENCY\tREDACTIONS\tSTATUS")
+		for _, e := range entries {
+			status := "ok"
+			if e.Error != "" {
+				status = "error"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%d\t%s\n",
+				e.ID, e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Command, valueOr(e.Model, "-"),
+				e.PromptTokens, e.CompletionTokens, time.Duration(e.LatencyMS)*time.Millisecond,
+				len(e.Redactions), status)
+		}
+		w.Flush()
+	},
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show one LLM call (an ID prefix is enough)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := audit.Find(args[0])
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		out, err := utils.MarshalJSON(entry)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Println(out)
+	},
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func init() {
+	auditListCmd.Flags().IntVarP(&auditLimit, "limit", "n", 20, "Show only the most recent N calls (0 for all)")
+	auditListCmd.Flags().BoolVar(&auditJSON, "json", false, "Print entries as JSON")
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	rootCmd.AddCommand(auditCmd)
+}
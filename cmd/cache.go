@@ -0,0 +1,38 @@
+// cmd/cache.go
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/soyuz43/prbuddy-go/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the LLM response cache",
+	Long: `PR drafts and change summaries are cached in the user cache directory, keyed by
+model, options and prompt, so re-running a command on unchanged input is instant.
+Entries expire after 7 days (PRBUDDY_CACHE_TTL) and the cache is capped at 50 MB
+(PRBUDDY_CACHE_MAX_BYTES). Use --no-cache or PRBUDDY_NO_CACHE=1 to bypass it.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached LLM responses",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := cache.Clear()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error clearing cache: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] Removed %d cached response(s).\n", removed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
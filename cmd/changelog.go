@@ -0,0 +1,70 @@
+// cmd/changelog.go
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/changelog"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogPrepend       string
+	changelogVersion       string
+	changelogIncludeChores bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <from>..<to>",
+	Short: "Generate Keep-a-Changelog release notes for a commit range",
+	Long: `Walks the commits in a range (e.g. v1.2.0..v1.3.0, or v1.2.0 for v1.2.0..HEAD),
+classifies them as features, fixes, breaking changes or chores from their Conventional
+Commits messages, falling back to the LLM (with any stored PR drafts) for the rest,
+and renders a Keep-a-Changelog section.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rangeSpec := args[0]
+
+		commits, err := changelog.CollectCommits(rangeSpec)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if len(commits) == 0 {
+			fmt.Printf("[PRBuddy-Go] No commits in %s.\n", changelog.NormalizeRange(rangeSpec))
+			return
+		}
+
+		opts := changelog.Options{Version: changelogVersion, IncludeChores: changelogIncludeChores}
+		end := changelog.RangeEnd(rangeSpec)
+		if opts.Version == "" && end != "HEAD" {
+			opts.Version = end
+		}
+		if date, err := utils.ExecGit("log", "-1", "--format=%cs", end); err == nil {
+			opts.Date = strings.TrimSpace(date)
+		}
+
+		section := changelog.Render(llm.ClassifyCommits(commits), opts)
+
+		if changelogPrepend == "" {
+			fmt.Print(section)
+			return
+		}
+		if err := changelog.Prepend(changelogPrepend, section); err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] Added %d commit(s) to %s\n", len(commits), changelogPrepend)
+	},
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogPrepend, "prepend", "", "Insert the section at the top of this changelog file (e.g. CHANGELOG.md)")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "", "Section title; defaults to the end of the range, or Unreleased for HEAD")
+	changelogCmd.Flags().BoolVar(&changelogIncludeChores, "include-chores", false, "List chores (docs, tests, tooling) under Changed")
+	rootCmd.AddCommand(changelogCmd)
+}
@@ -0,0 +1,57 @@
+// cmd/commit_msg.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var commitMsgCmd = &cobra.Command{
+	Use:   "commit-msg [msg-file [source [sha]]]",
+	Short: "Draft a Conventional Commits message from the staged changes",
+	Long: `Generates a Conventional Commits message (type, scope, subject, body) from the staged
+diff. The scope is inferred from the touched package directories.
+
+Without arguments the message is printed. With the arguments git passes to the
+prepare-commit-msg hook, the message is written into the message file unless the
+commit is a merge, squash or amend, or the user already supplied a message.`,
+	Args: cobra.MaximumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			msg, err := llm.GenerateCommitMessage()
+			if err != nil {
+				if errors.Is(err, llm.ErrNothingStaged) {
+					fmt.Println("[PRBuddy-Go] No staged changes. Stage files with 'git add' first.")
+					return
+				}
+				fmt.Printf("[PRBuddy-Go] Error generating commit message: %v\n", err)
+				return
+			}
+			fmt.Print(msg.String())
+			return
+		}
+
+		// Hook mode: never fail the commit, just leave the message file untouched.
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+		written, err := llm.PrepareCommitMessageFile(args[0], source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Could not draft commit message: %v\n", err)
+			return
+		}
+		if written {
+			fmt.Fprintln(os.Stderr, "[PRBuddy-Go] Drafted commit message from staged changes.")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commitMsgCmd)
+}
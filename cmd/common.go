@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/audit"
+	"github.com/soyuz43/prbuddy-go/internal/cache"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -24,12 +27,26 @@ var (
 	bold    = utils.Bold
 )
 
+// noCache bypasses the on-disk response cache for PR drafts and summaries.
+var noCache bool
+
 // Root command
 var rootCmd = &cobra.Command{
 	Use:   "prbuddy-go",
 	Short: "PRBuddy-Go: Enhance your pull request workflow.",
 	Long:  `PRBuddy-Go helps automate pull request generation, manage Git hooks, and provide insightful feedback predictions.`,
 	Run:   runRootCommand,
+	// Label LLM calls in the audit log with the command that made them.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		audit.SetCommand(strings.TrimPrefix(cmd.CommandPath(), "prbuddy-go "))
+		if noCache {
+			cache.Disable()
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always query the LLM instead of reusing cached responses")
 }
 
 // Execute executes the root command.
@@ -0,0 +1,86 @@
+// cmd/guide.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/guide"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	guideOut   string
+	guideNoLLM bool
+	guideTop   int
+)
+
+var guideCmd = &cobra.Command{
+	Use:   "guide",
+	Short: "Generate an architecture guide for the repository",
+	Long: `Builds the project metadata and project map, then writes a markdown guide with
+Mermaid diagrams: an architecture overview, every package with its key types and
+most-called functions, the entry points (main functions, cobra commands and HTTP
+handlers), the most-called functions across the project and the data flow between
+packages. Packages are summarized by the LLM bottom-up, each with the summaries of the
+packages it imports, and the overview is written from those summaries.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error retrieving repository path: %v\n", err)
+			return
+		}
+		toStdout := guideOut == "-"
+
+		gd, err := guide.Analyze(repoPath)
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+
+		if !guideNoLLM {
+			progress := func(dir string) {
+				if !toStdout {
+					fmt.Printf("[PRBuddy-Go] Summarizing %s...\n", dir)
+				}
+			}
+			if err := llm.SummarizeGuide(gd, progress); err != nil {
+				color.Red("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+		}
+
+		doc := gd.Markdown(guideTop)
+		if toStdout {
+			fmt.Print(doc)
+			return
+		}
+
+		out := guideOut
+		if !filepath.IsAbs(out) {
+			out = filepath.Join(repoPath, out)
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if err := os.WriteFile(out, []byte(doc), 0644); err != nil {
+			color.Red("[PRBuddy-Go] Error writing guide: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] Wrote a guide to %d package(s) to %s\n", len(gd.Graph.Packages), guideOut)
+	},
+}
+
+func init() {
+	guideCmd.Flags().StringVar(&guideOut, "out", filepath.Join("docs", "guide.md"), "File to write, relative to the repository root, or - for stdout")
+	guideCmd.Flags().BoolVar(&guideNoLLM, "no-llm", false, "Skip the LLM summaries and only render the project structure")
+	guideCmd.Flags().IntVar(&guideTop, "top", guide.DefaultTop, "Number of most-called functions to list")
+	rootCmd.AddCommand(guideCmd)
+}
@@ -0,0 +1,122 @@
+// cmd/health.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/apidiff"
+	"github.com/soyuz43/prbuddy-go/internal/health"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthThresholds = health.DefaultThresholds
+	healthDiff       bool
+	healthBase       string
+	healthJSON       bool
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Report unused, long, complex and deeply nested functions",
+	Long: `Checks every Go function in the working tree and lists unexported functions nothing
+calls, functions over --max-lines, cyclomatic complexity over --max-complexity
+(1 + branches, cases and && / || in the syntax tree), nesting deeper than --max-nesting
+and more than --max-returns return statements. Test files count as callers but are
+not checked.
+
+With --diff, compares the merge-base of the base branch with HEAD instead and lists
+only the findings the branch adds or resolves. PR drafts get the same section when
+PRBUDDY_CODE_HEALTH=1 is set for the post-commit hook.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if healthDiff {
+			runHealthDiff()
+			return
+		}
+
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		functions, err := health.WorkingTree(repoPath)
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		report := health.Check(functions, healthThresholds)
+
+		if healthJSON {
+			printHealthJSON(report)
+			return
+		}
+		if len(report.Findings) == 0 {
+			fmt.Printf("[PRBuddy-Go] No findings in %d function(s).\n", report.Functions)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tFUNCTION\tLOCATION\tVALUE\tLIMIT")
+		for _, f := range report.Findings {
+			value, limit := "-", "-"
+			if f.Kind != health.KindUnused {
+				value, limit = fmt.Sprint(f.Value), fmt.Sprint(f.Limit)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s:%d\t%s\t%s\n", f.Kind, f.Function, f.File, f.Line, value, limit)
+		}
+		w.Flush()
+		fmt.Printf("[PRBuddy-Go] %d finding(s) in %d function(s).\n", len(report.Findings), report.Functions)
+	},
+}
+
+// runHealthDiff prints the findings the branch adds or resolves relative to its base.
+func runHealthDiff() {
+	base := healthBase
+	if base == "" {
+		var err error
+		if base, err = apidiff.DefaultBase(); err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+	}
+	delta, err := health.Diff(base, healthThresholds)
+	if err != nil {
+		color.Red("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+
+	switch {
+	case healthJSON:
+		printHealthJSON(delta)
+	case delta.HasChanges():
+		fmt.Print(delta.Markdown())
+	default:
+		fmt.Printf("[PRBuddy-Go] No code health changes since %s.\n", base)
+	}
+}
+
+func printHealthJSON(v interface{}) {
+	out, err := utils.MarshalJSON(v)
+	if err != nil {
+		color.Red("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	fmt.Println(out)
+}
+
+func init() {
+	healthCmd.Flags().IntVar(&healthThresholds.Lines, "max-lines", health.DefaultThresholds.Lines, "Report functions longer than this many lines")
+	healthCmd.Flags().IntVar(&healthThresholds.Complexity, "max-complexity", health.DefaultThresholds.Complexity, "Report functions over this cyclomatic complexity")
+	healthCmd.Flags().IntVar(&healthThresholds.Nesting, "max-nesting", health.DefaultThresholds.Nesting, "Report functions nested deeper than this")
+	healthCmd.Flags().IntVar(&healthThresholds.ReturnPaths, "max-returns", health.DefaultThresholds.ReturnPaths, "Report functions with more return statements than this")
+	healthCmd.Flags().BoolVar(&healthDiff, "diff", false, "Only list findings added or resolved since the base branch")
+	healthCmd.Flags().StringVar(&healthBase, "base", "", "Base branch for --diff (default: origin's HEAD, main or master)")
+	healthCmd.Flags().BoolVar(&healthJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(healthCmd)
+}
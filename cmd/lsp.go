@@ -0,0 +1,45 @@
+// cmd/lsp.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server over stdio",
+	Long: `Speaks LSP over stdin/stdout so editors such as Neovim, Helix and Zed can use
+PRBuddy-Go without the VS Code extension. It provides:
+
+  - hover with the function's signature, location, calls and callers from the project map
+  - code actions to explain the function under the cursor, generate a test for it,
+    and review the selected lines (findings are published as diagnostics)
+  - a "Draft PR" command for the latest commit
+
+Send {"reviewOnSave": true} as initializationOptions to review a file's uncommitted
+changes each time it is saved.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// stdout carries the protocol; send everything else to stderr.
+		protocolOut := os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+		logrus.SetOutput(os.Stderr)
+
+		if err := lsp.NewServer(os.Stdin, protocolOut).Run(); err != nil {
+			return fmt.Errorf("lsp server: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
@@ -4,17 +4,34 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/diagram"
 	"github.com/soyuz43/prbuddy-go/internal/treesitter"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var (
+	mapFormat  string
+	mapPackage string
+	mapFunc    string
+	mapDepth   int
+	mapOut     string
+)
+
 var mapCmd = &cobra.Command{
 	Use:   "map",
 	Short: "Generate project scaffolds using tree-sitter parsing",
-	Long:  "Scans the repository using the Go parser, builds project metadata and a project map, and saves the results to scaffold files.",
+	Long: `Scans the repository using the Go parser, builds project metadata and a project map, and saves the results to scaffold files.
+
+With --format mermaid or dot it also renders a diagram: the package dependency graph by
+default, or the call graph around a package (--package) or function (--func) up to
+--depth calls away. --format html writes a single-file viewer that works offline, for
+searching functions and following their callers and callees.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// 1. Get repository root directory
 		repoPath, err := utils.GetRepoPath()
@@ -58,10 +75,69 @@ var mapCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println("Project scaffolds generated successfully.")
+		if mapFormat == "" || mapFormat == "json" {
+			fmt.Println("Project scaffolds generated successfully.")
+			return
+		}
+
+		// 7. Render the requested diagram
+		graph := codegraph.Build(repoPath, metadata.SourceFiles, projectMap.Functions)
+		if err := renderMap(graph, repoPath); err != nil {
+			fmt.Printf("Error rendering project map: %v\n", err)
+		}
 	},
 }
 
+// renderMap writes the graph in the format selected by --format.
+func renderMap(graph *codegraph.Graph, repoPath string) error {
+	var output []byte
+	switch mapFormat {
+	case diagram.FormatMermaid, diagram.FormatDOT:
+		d := diagram.Packages(graph)
+		if mapFunc != "" || mapPackage != "" {
+			var err error
+			d, err = diagram.Calls(graph, diagram.Scope{Package: mapPackage, Function: mapFunc, Depth: mapDepth})
+			if err != nil {
+				return err
+			}
+		}
+		if mapFormat == diagram.FormatMermaid {
+			output = []byte(d.Mermaid())
+		} else {
+			output = []byte(d.DOT())
+		}
+	case diagram.FormatHTML:
+		page, err := diagram.HTML(graph, filepath.Base(repoPath)+" project map", repoPath)
+		if err != nil {
+			return err
+		}
+		output = page
+		if mapOut == "" {
+			mapOut = filepath.Join(repoPath, ".git", "pr_buddy_db", "scaffold", "project_map.html")
+		}
+	default:
+		return fmt.Errorf("unknown format %q (use json, mermaid, dot or html)", mapFormat)
+	}
+
+	if mapOut == "" || mapOut == "-" {
+		fmt.Print(string(output))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(mapOut), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(mapOut, output, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("[PRBuddy-Go] Wrote %s\n", mapOut)
+	return nil
+}
+
 func init() {
+	mapCmd.Flags().StringVar(&mapFormat, "format", "json", "Output format: json (scaffolds only), mermaid, dot or html")
+	mapCmd.Flags().StringVar(&mapPackage, "package", "", "Draw the call graph of this package directory (e.g. internal/llm)")
+	mapCmd.Flags().StringVar(&mapFunc, "func", "", "Draw the call graph around this function (Name or Type.Method)")
+	mapCmd.Flags().IntVar(&mapDepth, "depth", diagram.DefaultDepth, "How many calls away from --package or --func to follow")
+	mapCmd.Flags().StringVarP(&mapOut, "out", "o", "", "File to write; mermaid and dot default to stdout, html to .git/pr_buddy_db/scaffold/project_map.html")
 	rootCmd.AddCommand(mapCmd)
 }
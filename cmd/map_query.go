@@ -0,0 +1,105 @@
+// cmd/map_query.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/mapquery"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryFilter mapquery.Filter
+	queryBranch string
+	queryJSON   bool
+)
+
+var mapQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query the saved project map",
+	Long: `Loads the latest project map saved for the branch (running map first if there is none)
+and lists the functions matching every given filter:
+
+  --func Name|Type.Method   functions with this name (* and ? wildcards allowed)
+  --file path               functions in this file or directory
+  --calls X                 functions that call X
+  --called-by X             functions X calls
+  --returns type            functions with a result of this type, e.g. error
+  --unused                  functions nothing in the project calls
+
+Calls resolve to the caller's own package first, then to exported functions of the
+packages its file imports, so calls into the standard library are not counted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := queryBranch
+		if branch == "" {
+			current, err := utils.ExecGit("rev-parse", "--abbrev-ref", "HEAD")
+			if err != nil {
+				color.Red("[PRBuddy-Go] Error: failed to get current branch: %v\n", err)
+				return
+			}
+			branch = strings.TrimSpace(current)
+		}
+
+		projectMap, _, err := treesitter.LoadProjectMap(branch)
+		if errors.Is(err, treesitter.ErrNoProjectMap) && queryBranch == "" {
+			fmt.Println("[PRBuddy-Go] No saved project map for this branch; generating one...")
+			mapCmd.Run(mapCmd, nil)
+			projectMap, _, err = treesitter.LoadProjectMap(branch)
+		}
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: failed to get repository path: %v\n", err)
+			return
+		}
+		results := mapquery.New(repoPath, projectMap.Functions).Query(queryFilter)
+		if queryJSON {
+			out, err := utils.MarshalJSON(results)
+			if err != nil {
+				color.Red("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Println(out)
+			return
+		}
+		if len(results) == 0 {
+			fmt.Println("[PRBuddy-Go] No matching functions.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FUNCTION\tLOCATION\tRETURNS\tCALLERS\tCALLEES")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s:%d\t%s\t%d\t%d\n",
+				codegraph.DisplayName(r.FunctionInfo), r.File, r.StartLine,
+				valueOr(strings.Join(r.Returns, ", "), "-"), len(r.Callers), len(r.Callees))
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	mapQueryCmd.Flags().StringVar(&queryFilter.Func, "func", "", "Functions with this name (Name or Type.Method)")
+	mapQueryCmd.Flags().StringVar(&queryFilter.File, "file", "", "Functions in this file or directory")
+	mapQueryCmd.Flags().StringVar(&queryFilter.Calls, "calls", "", "Functions that call this function")
+	mapQueryCmd.Flags().StringVar(&queryFilter.CalledBy, "called-by", "", "Functions called by this function")
+	mapQueryCmd.Flags().StringVar(&queryFilter.Returns, "returns", "", "Functions returning this type (e.g. error)")
+	mapQueryCmd.Flags().BoolVar(&queryFilter.Unused, "unused", false, "Functions with no callers in the project")
+	mapQueryCmd.Flags().StringVar(&queryBranch, "branch", "", "Branch whose saved map to query (default: current branch)")
+	mapQueryCmd.Flags().BoolVar(&queryJSON, "json", false, "Print results as JSON")
+	mapCmd.AddCommand(mapQueryCmd)
+}
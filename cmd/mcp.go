@@ -0,0 +1,55 @@
+// cmd/mcp.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpRepo string
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server over stdio",
+	Long: `Serves PRBuddy-Go's repository knowledge as MCP tools over stdin/stdout, so other
+assistants and agents can use it:
+
+  what_changed   uncommitted changes or the last commit, as a diff
+  project_map    every Go function with its location, signature and calls
+  find_function  a function's source, calls and callers
+  task_list      the task list of the current DCE session
+  draft_pr       a PR title and description for the latest commit
+  saved_drafts   saved PR drafts, or the draft conversation for one commit
+
+MCP clients often start servers outside the project, so pass --repo to choose it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mcpRepo != "" {
+			if err := os.Chdir(mcpRepo); err != nil {
+				return fmt.Errorf("failed to enter repository %s: %w", mcpRepo, err)
+			}
+		}
+
+		// stdout carries the protocol; send everything else to stderr.
+		protocolOut := os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+		logrus.SetOutput(os.Stderr)
+
+		if err := mcp.NewServer(os.Stdin, protocolOut).Run(); err != nil {
+			return fmt.Errorf("mcp server: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	mcpCmd.Flags().StringVar(&mcpRepo, "repo", "", "Repository to serve (default: current directory)")
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,291 @@
+// cmd/models.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modelsJSON        bool
+	modelsTask        string
+	modelsClear       bool
+	modelsUseAfter    bool
+	modelsPullTimeout time.Duration
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List, pull and select LLM models",
+	Long: `Manages the Ollama models PRBuddy-Go uses. The selection is saved in
+.git/pr_buddy_db/` + models.ConfigFile + ` and can route each task to its own model, e.g. a small fast
+model for commit messages and a larger one for PR drafts and reviews.
+
+Tasks: ` + taskNames() + `
+
+A model set through the extension or PRBUDDY_LLM_MODEL overrides the saved selection.`,
+}
+
+var modelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed models and the tasks routed to them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		installed, err := models.List(models.Endpoint())
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		cfg := loadModelConfig()
+
+		if modelsJSON {
+			out, err := utils.MarshalJSON(map[string]interface{}{"models": installed, "selection": cfg})
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Println(out)
+			return
+		}
+		if len(installed) == 0 {
+			fmt.Println("[PRBuddy-Go] No models installed. Run 'prbuddy-go models pull <name>'.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tPARAMS\tQUANT\tMODIFIED\tUSED FOR")
+		for _, m := range installed {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				m.Name, formatSize(m.Size), valueOr(m.Details.ParameterSize, "-"),
+				valueOr(m.Details.QuantizationLevel, "-"), m.ModifiedAt.Local().Format("2006-01-02 15:04"),
+				valueOr(strings.Join(usedFor(cfg, m.Name), ", "), "-"))
+		}
+		w.Flush()
+	},
+}
+
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull <name>",
+	Short: "Download a model and wait until it is ready",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		endpoint := models.Endpoint()
+		// Check --task before a download that may take minutes.
+		if modelsTask != "" {
+			if _, err := models.ParseTask(modelsTask); err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v (tasks: %s)\n", err, taskNames())
+				return
+			}
+		}
+
+		lastStatus := ""
+		err := models.Pull(endpoint, name, func(p models.PullProgress) {
+			if p.Total > 0 {
+				fmt.Printf("\r[PRBuddy-Go] %s %3d%%", p.Status, p.Completed*100/p.Total)
+				lastStatus = "progress"
+				return
+			}
+			if lastStatus == "progress" {
+				fmt.Println()
+			}
+			if p.Status != lastStatus {
+				fmt.Printf("[PRBuddy-Go] %s\n", p.Status)
+			}
+			lastStatus = p.Status
+		})
+		if lastStatus == "progress" {
+			fmt.Println()
+		}
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("[PRBuddy-Go] Loading %s...\n", name)
+		if err := models.WaitReady(endpoint, name, modelsPullTimeout); err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] %s is ready.\n", name)
+
+		if modelsUseAfter {
+			selectModel(name)
+		}
+	},
+}
+
+var modelsUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Select the default model, or the model for one task with --task",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if modelsClear {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if modelsClear {
+			selectModel("")
+			return
+		}
+		name := args[0]
+		if installed, err := models.List(models.Endpoint()); err == nil && !models.Installed(installed, name) {
+			fmt.Printf("[PRBuddy-Go] Warning: %s is not installed. Run 'prbuddy-go models pull %s'.\n", name, name)
+		}
+		selectModel(name)
+	},
+}
+
+var modelsInfoCmd = &cobra.Command{
+	Use:   "info [name]",
+	Short: "Show details for a model (defaults to the one a task would use)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		task := models.TaskDefault
+		if modelsTask != "" {
+			var err error
+			if task, err = models.ParseTask(modelsTask); err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v (tasks: %s)\n", err, taskNames())
+				return
+			}
+		}
+		name, endpoint := llm.ModelFor(task)
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		info, err := models.Show(endpoint, name)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if modelsJSON {
+			out, err := utils.MarshalJSON(info)
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Println(out)
+			return
+		}
+
+		fmt.Printf("Model:          %s\n", info.Name)
+		fmt.Printf("Family:         %s\n", valueOr(info.Details.Family, "-"))
+		fmt.Printf("Parameters:     %s\n", valueOr(info.Details.ParameterSize, "-"))
+		fmt.Printf("Quantization:   %s\n", valueOr(info.Details.QuantizationLevel, "-"))
+		if info.ContextLength > 0 {
+			fmt.Printf("Context length: %d\n", info.ContextLength)
+		}
+		fmt.Printf("Used for:       %s\n", valueOr(strings.Join(usedFor(loadModelConfig(), info.Name), ", "), "-"))
+		if info.Parameters != "" {
+			fmt.Printf("\nDefaults:\n%s\n", info.Parameters)
+		}
+	},
+}
+
+// selectModel saves name as the default model, or as the --task route. An empty name
+// removes the selection.
+func selectModel(name string) {
+	cfg := loadModelConfig()
+	target := "default model"
+
+	if modelsTask == "" {
+		cfg.Default = name
+	} else {
+		task, err := models.ParseTask(modelsTask)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v (tasks: %s)\n", err, taskNames())
+			return
+		}
+		if cfg.Tasks == nil {
+			cfg.Tasks = make(map[models.Task]string)
+		}
+		if name == "" {
+			delete(cfg.Tasks, task)
+		} else {
+			cfg.Tasks[task] = name
+		}
+		target = fmt.Sprintf("model for %s", task)
+	}
+
+	if err := models.SaveConfig(cfg); err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	if name == "" {
+		fmt.Printf("[PRBuddy-Go] Cleared the %s.\n", target)
+		return
+	}
+	fmt.Printf("[PRBuddy-Go] Set the %s to %s.\n", target, name)
+}
+
+func loadModelConfig() models.Config {
+	cfg, err := models.LoadConfig()
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: %v\n", err)
+	}
+	return cfg
+}
+
+// usedFor lists the selections that point at name.
+func usedFor(cfg models.Config, name string) []string {
+	var uses []string
+	if cfg.Default == name || cfg.Default+":latest" == name {
+		uses = append(uses, "default")
+	}
+	for _, task := range cfg.RoutedTasks() {
+		if model := cfg.Tasks[task]; model == name || model+":latest" == name {
+			uses = append(uses, string(task))
+		}
+	}
+	return uses
+}
+
+func taskNames() string {
+	names := make([]string, len(models.Tasks))
+	for i, t := range models.Tasks {
+		names[i] = string(t)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func formatSize(bytes int64) string {
+	const gb, mb = 1 << 30, 1 << 20
+	switch {
+	case bytes >= gb:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/gb)
+	case bytes >= mb:
+		return fmt.Sprintf("%.0f MB", float64(bytes)/mb)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+func init() {
+	modelsListCmd.Flags().BoolVar(&modelsJSON, "json", false, "Print the models and selection as JSON")
+	modelsInfoCmd.Flags().BoolVar(&modelsJSON, "json", false, "Print the model details as JSON")
+	modelsInfoCmd.Flags().StringVar(&modelsTask, "task", "", "Show the model routed to this task")
+	modelsUseCmd.Flags().StringVar(&modelsTask, "task", "", "Route only this task to the model")
+	modelsUseCmd.Flags().BoolVar(&modelsClear, "clear", false, "Remove the selection instead of setting it")
+	modelsPullCmd.Flags().StringVar(&modelsTask, "task", "", "With --use, route only this task to the model")
+	modelsPullCmd.Flags().BoolVar(&modelsUseAfter, "use", false, "Select the model once it is ready")
+	modelsPullCmd.Flags().DurationVar(&modelsPullTimeout, "timeout", 5*time.Minute, "How long to wait for the model to load")
+
+	rootCmd.AddCommand(modelsCmd)
+	modelsCmd.AddCommand(modelsListCmd)
+	modelsCmd.AddCommand(modelsPullCmd)
+	modelsCmd.AddCommand(modelsUseCmd)
+	modelsCmd.AddCommand(modelsInfoCmd)
+}
@@ -8,10 +8,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/soyuz43/prbuddy-go/internal/apidiff"
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/health"
 	"github.com/soyuz43/prbuddy-go/internal/llm"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
@@ -20,6 +24,7 @@ import (
 var (
 	extensionActive   bool
 	nonInteractive    bool
+	judgeTasks        bool
 	extensionAttempts = 3
 	extensionDelay    = 500 * time.Millisecond
 )
@@ -43,6 +48,8 @@ func init() {
 		"Indicates extension connectivity check")
 	postCommitCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false,
 		"Disable interactive prompts")
+	postCommitCmd.Flags().BoolVar(&judgeTasks, "judge-tasks", false,
+		"Ask the LLM whether the commit satisfies each matching DCE task")
 	rootCmd.AddCommand(postCommitCmd)
 }
 
@@ -51,12 +58,20 @@ func runPostCommit(cmd *cobra.Command, args []string) {
 		fmt.Println("[PRBuddy-Go] Starting post-commit workflow...")
 	}
 
+	reconcileCommitTasks()
+
 	branchName, commitHash, draftPR, err := generateDraftPR()
 	if err != nil {
 		handleGenerationError(err)
 		return
 	}
 
+	// Keep the draft so later commands (e.g. changelog) can reuse it.
+	draftContext := []contextpkg.Message{{Role: "assistant", Content: draftPR}}
+	if saveErr := llm.SaveDraftContext(branchName, commitHash, draftContext); saveErr != nil {
+		fmt.Printf("[PRBuddy-Go] Draft save error: %v\n", saveErr)
+	}
+
 	if extensionActive {
 		if commErr := communicateWithExtension(branchName, commitHash, draftPR); commErr != nil {
 			handleExtensionFailure(draftPR, commErr)
@@ -98,10 +113,67 @@ func generateDraftPR() (string, string, string, error) {
 	if err != nil {
 		return "", "", "", fmt.Errorf("draft generation failed: %w", err)
 	}
+	draftPR = appendBreakingChanges(draftPR)
+	draftPR = appendCodeHealth(draftPR)
 
 	return strings.TrimSpace(branchName), strings.TrimSpace(commitHash), draftPR, nil
 }
 
+// appendBreakingChanges adds a Breaking Changes section when apidiff.PostCommitEnv is set
+// and the branch changes the exported API relative to its base. Without a resolvable
+// base the draft is unchanged.
+func appendBreakingChanges(draftPR string) string {
+	if enabled, _ := strconv.ParseBool(os.Getenv(apidiff.PostCommitEnv)); !enabled {
+		return draftPR
+	}
+	base, err := apidiff.DefaultBase()
+	if err != nil {
+		return draftPR
+	}
+	report, err := apidiff.Diff(base, apidiff.Options{})
+	if err != nil || !report.HasBreakingChanges() {
+		return draftPR
+	}
+	return apidiff.InsertSection(draftPR, report.Markdown())
+}
+
+// appendCodeHealth adds a Code Health section when health.PostCommitEnv is set and the
+// branch adds or resolves health findings relative to its base. Without a resolvable
+// base the draft is unchanged.
+func appendCodeHealth(draftPR string) string {
+	if enabled, _ := strconv.ParseBool(os.Getenv(health.PostCommitEnv)); !enabled {
+		return draftPR
+	}
+	base, err := apidiff.DefaultBase()
+	if err != nil {
+		return draftPR
+	}
+	delta, err := health.Diff(base, health.DefaultThresholds)
+	if err != nil || !delta.HasChanges() {
+		return draftPR
+	}
+	return apidiff.InsertSection(draftPR, delta.Markdown())
+}
+
+// reconcileCommitTasks closes or advances persisted DCE tasks touched by HEAD.
+// Failures are reported but never block draft generation.
+func reconcileCommitTasks() {
+	commitHash, err := utils.GetLatestCommit()
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Task tracking skipped: %v\n", err)
+		return
+	}
+
+	result, err := dce.ReconcileStoredTasks(commitHash, judgeTasks)
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Task tracking error: %v\n", err)
+		return
+	}
+	if result != nil {
+		fmt.Printf("[PRBuddy-Go] %s\n", result.Summary())
+	}
+}
+
 func communicateWithExtension(branch, hash, draft string) error {
 	if err := activateExtension(); err != nil {
 		return fmt.Errorf("extension activation: %w", err)
@@ -112,7 +184,12 @@ func communicateWithExtension(branch, hash, draft string) error {
 		return fmt.Errorf("port retrieval: %w", err)
 	}
 
-	return retryCommunication(port, branch, hash, draft)
+	token, err := utils.ReadTokenFile()
+	if err != nil {
+		return fmt.Errorf("token retrieval: %w", err)
+	}
+
+	return retryCommunication(port, token, branch, hash, draft)
 }
 
 func activateExtension() error {
@@ -120,7 +197,7 @@ func activateExtension() error {
 	return cmd.Run()
 }
 
-func retryCommunication(port int, branch, hash, draft string) error {
+func retryCommunication(port int, token, branch, hash, draft string) error {
 	client := http.Client{Timeout: 2 * time.Second}
 	payload := map[string]interface{}{
 		"branch":    branch,
@@ -135,14 +212,22 @@ func retryCommunication(port int, branch, hash, draft string) error {
 	}
 
 	for i := 0; i < extensionAttempts; i++ {
-		resp, err := client.Post(
+		req, err := http.NewRequest(http.MethodPost,
 			fmt.Sprintf("http://localhost:%d/extension", port),
-			"application/json",
 			strings.NewReader(string(jsonPayload)),
 		)
-
-		if err == nil && resp.StatusCode == http.StatusOK {
-			return nil
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
 		}
 
 		time.Sleep(extensionDelay)
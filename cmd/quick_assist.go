@@ -47,6 +47,7 @@ func handleSingleQuickAssist(query string) {
 	// Display assistant response
 	fmt.Println("\nQuickAssist Response:")
 	color.Cyan(response)
+	offerSuggestedPatch(bufio.NewReader(os.Stdin), response)
 }
 
 // StartInteractiveQuickAssist starts the interactive chat session.
@@ -90,6 +91,7 @@ func StartInteractiveQuickAssist() {
 		// Display assistant response
 		color.Blue("Assistant:")
 		color.Cyan(response)
+		offerSuggestedPatch(reader, response)
 	}
 }
 
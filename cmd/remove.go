@@ -28,6 +28,11 @@ var removeCmd = &cobra.Command{
 			fmt.Println("[PRBuddy-Go] Removed the post-commit hook.")
 		}
 
+		// 1b. Remove PRBuddy's prepare-commit-msg hook logic, if installed
+		if err := hooks.RemovePrepareCommitMsgHook(); err != nil {
+			fmt.Printf("[PRBuddy-Go] Error removing prepare-commit-msg hook: %v\n", err)
+		}
+
 		// 2. Remove the .git/pr_buddy_db directory
 		repoPath, err := utils.GetRepoPath()
 		if err != nil {
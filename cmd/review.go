@@ -0,0 +1,107 @@
+// cmd/review.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/review"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewRange  string
+	reviewBranch string
+	reviewFormat string
+	reviewOutput string
+	reviewFailOn string
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review staged changes, a commit range or a branch with the LLM",
+	Long: `Reviews code changes with the LLM and reports findings anchored to lines of the diff.
+Findings that reference lines outside the diff are discarded.
+
+By default the staged changes are reviewed. Use --range for a commit range
+(e.g. v1.0..HEAD) or --branch to review the current branch against a base branch.
+
+Output formats: terminal (default), sarif, rdjson (reviewdog).`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reviewRange != "" && reviewBranch != "" {
+			fmt.Println("[PRBuddy-Go] Use either --range or --branch, not both.")
+			os.Exit(2)
+		}
+		switch reviewFailOn {
+		case "", review.SeverityError, review.SeverityWarning, review.SeverityInfo:
+		default:
+			fmt.Printf("[PRBuddy-Go] Invalid --fail-on %q: use error, warning or info.\n", reviewFailOn)
+			os.Exit(2)
+		}
+		scope := review.Scope{Range: reviewRange, Branch: reviewBranch}
+
+		// Keep machine-readable output clean on stdout.
+		logf := func(format string, a ...interface{}) {
+			if reviewFormat == review.FormatTerminal || reviewOutput != "" {
+				fmt.Printf(format, a...)
+			} else {
+				fmt.Fprintf(os.Stderr, format, a...)
+			}
+		}
+
+		logf("[PRBuddy-Go] Reviewing %s...\n", scope.Describe())
+		diff, err := review.LoadDiff(scope)
+		if err != nil {
+			logf("[PRBuddy-Go] Error: %v\n", err)
+			os.Exit(2)
+		}
+		if strings.TrimSpace(diff) == "" {
+			logf("[PRBuddy-Go] Nothing to review.\n")
+			return
+		}
+
+		report, err := llm.ReviewDiff(diff)
+		if err != nil {
+			logf("[PRBuddy-Go] Error reviewing changes: %v\n", err)
+			os.Exit(2)
+		}
+		if len(report.Dropped) > 0 {
+			logf("[PRBuddy-Go] Discarded %d finding(s) not anchored to the diff.\n", len(report.Dropped))
+		}
+
+		out, err := review.Render(report, reviewFormat)
+		if err != nil {
+			logf("[PRBuddy-Go] Error: %v\n", err)
+			os.Exit(2)
+		}
+		if reviewOutput != "" {
+			if err := os.WriteFile(reviewOutput, []byte(out+"\n"), 0644); err != nil {
+				logf("[PRBuddy-Go] Error writing %s: %v\n", reviewOutput, err)
+				os.Exit(2)
+			}
+			logf("[PRBuddy-Go] Wrote %d finding(s) to %s\n", len(report.Findings), reviewOutput)
+		} else {
+			fmt.Println(out)
+		}
+
+		if reviewFailOn != "" {
+			max := report.MaxSeverity()
+			if max != "" && review.SeverityRank(max) >= review.SeverityRank(reviewFailOn) {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	reviewCmd.Flags().StringVar(&reviewRange, "range", "", "Commit range to review, e.g. main..HEAD")
+	reviewCmd.Flags().StringVar(&reviewBranch, "branch", "", "Base branch; review the current branch's changes since it diverged")
+	reviewCmd.Flags().StringVarP(&reviewFormat, "format", "f", review.FormatTerminal, "Output format: terminal, sarif or rdjson")
+	reviewCmd.Flags().StringVarP(&reviewOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	reviewCmd.Flags().StringVar(&reviewFailOn, "fail-on", "", "Exit with status 1 if a finding is at least this severe (error, warning, info)")
+	rootCmd.AddCommand(reviewCmd)
+}
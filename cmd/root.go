@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/audit"
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
 	"github.com/soyuz43/prbuddy-go/internal/dce"
 	"github.com/soyuz43/prbuddy-go/internal/llm"
@@ -67,6 +68,7 @@ func runInteractiveSession() {
 
 		command := strings.ToLower(parts[0])
 		args := parts[1:]
+		audit.SetCommand(command)
 
 		switch command {
 		case "generate", "gen", "pr":
@@ -140,6 +142,7 @@ func singleQueryResponse(query string) {
 	color.Yellow("\nQuickAssist Response:\n")
 	color.Cyan(resp)
 	fmt.Println()
+	offerSuggestedPatch(bufio.NewReader(os.Stdin), resp)
 }
 
 func startInteractiveQuickAssist(reader *bufio.Reader) {
@@ -177,6 +180,7 @@ func startInteractiveQuickAssist(reader *bufio.Reader) {
 		color.Blue("\nAssistant:\n")
 		color.Cyan(resp)
 		fmt.Println()
+		offerSuggestedPatch(reader, resp)
 
 		if conv, exists := contextpkg.ConversationManagerInstance.GetConversation(conversationID); exists {
 			conv.AddMessage("assistant", resp)
@@ -224,6 +228,13 @@ func handleDCECommand() {
 		return
 	}
 
+	// Surface proactive suggestions as the monitor finds them
+	if littleguy, ok := dce.GetDCEContextManager().GetContext(conversationID); ok {
+		littleguy.SetQueryCallback(func(msg string) {
+			color.Yellow("\n[Suggestion] %s (type /suggestions to review)", msg)
+		})
+	}
+
 	// Interactive loop
 	color.Green("DCE is active. Type your queries or DCE commands (/task, /status, etc.)")
 	for {
@@ -256,6 +267,7 @@ func handleDCECommand() {
 
 		color.Cyan("Assistant:")
 		fmt.Println(response)
+		offerSuggestedPatch(reader, response)
 	}
 
 	// Deactivate DCE
@@ -0,0 +1,114 @@
+// cmd/split.go
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/split"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitYes    bool
+	splitDryRun bool
+	splitNoLLM  bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split large staged changes into a sequence of logical commits",
+	Long: `Groups the hunks of the staged changes into logical commits using package proximity
+and an LLM clustering pass, proposes commit messages, and after confirmation commits
+each group with 'git apply --cached'. Only the index is used, so the working tree
+(including unstaged changes) is left byte-for-byte identical. The post-commit hook runs
+only after the last commit, so one PR draft is produced for the split.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		diff, err := split.StagedDiff()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		units := split.CollectUnits(diff)
+		if len(units) == 0 {
+			fmt.Println("[PRBuddy-Go] No staged changes to split. Stage files with 'git add' first.")
+			return
+		}
+
+		var plan *split.Plan
+		if !splitNoLLM {
+			fmt.Printf("[PRBuddy-Go] Clustering %d change unit(s) into commits...\n", len(units))
+			plan, err = llm.ProposeSplit(units)
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] LLM clustering failed (%v) - falling back to package grouping\n", err)
+			}
+		}
+		if plan == nil {
+			plan = split.ProximityGroups(units)
+		}
+
+		printSplitPlan(plan, units)
+		if splitDryRun {
+			return
+		}
+		if len(plan.Groups) < 2 {
+			fmt.Println("[PRBuddy-Go] The staged changes already form a single logical commit.")
+			return
+		}
+
+		if !splitYes {
+			fmt.Printf("[PRBuddy-Go] Create these %d commits? [y/N] ", len(plan.Groups))
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.TrimSpace(strings.ToLower(answer))
+			if answer != "y" && answer != "yes" {
+				fmt.Println("[PRBuddy-Go] Aborted. Nothing was committed.")
+				return
+			}
+		}
+
+		shas, err := split.Apply(plan, units)
+		for i, sha := range shas {
+			color.Green("[PRBuddy-Go] %s %s\n", sha[:7], plan.Groups[i].Message)
+		}
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] Created %d commits. Working tree unchanged.\n", len(shas))
+	},
+}
+
+// printSplitPlan lists each proposed commit with the files and hunks it contains.
+func printSplitPlan(plan *split.Plan, units []split.Unit) {
+	byID := make(map[string]split.Unit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+
+	fmt.Println("\n=== Proposed Commits ===")
+	for i, g := range plan.Groups {
+		color.Cyan("%d. %s\n", i+1, g.Message)
+		for _, id := range g.Units {
+			u := byID[id]
+			if u.Whole {
+				fmt.Printf("     %s\n", u.File)
+			} else {
+				fmt.Printf("     %s %s\n", u.File, u.Diff.Hunks[0].Header)
+			}
+		}
+	}
+	fmt.Println("========================")
+}
+
+func init() {
+	splitCmd.Flags().BoolVarP(&splitYes, "yes", "y", false, "Create the commits without asking for confirmation")
+	splitCmd.Flags().BoolVar(&splitDryRun, "dry-run", false, "Only print the proposed commits")
+	splitCmd.Flags().BoolVar(&splitNoLLM, "no-llm", false, "Group by package proximity only")
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,71 @@
+// cmd/stats.go
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/audit"
+	"github.com/soyuz43/prbuddy-go/internal/metrics"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince time.Duration
+	statsJSON  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show token and latency statistics per model and command",
+	Long: `Aggregates the LLM calls recorded in the audit log: call and error counts, prompt
+and output tokens, prompt and generation speed (tokens/s, from Ollama's own timings)
+and average latency, per model and per command.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := audit.List()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+
+		registry := metrics.NewRegistry()
+		cutoff := time.Now().Add(-statsSince)
+		for _, e := range entries {
+			if statsSince > 0 && e.Timestamp.Before(cutoff) {
+				continue
+			}
+			registry.Record(metrics.Call{
+				Command:            e.Command,
+				Model:              e.Model,
+				PromptTokens:       e.PromptTokens,
+				CompletionTokens:   e.CompletionTokens,
+				LoadDuration:       time.Duration(e.LoadMS) * time.Millisecond,
+				PromptEvalDuration: time.Duration(e.PromptEvalMS) * time.Millisecond,
+				EvalDuration:       time.Duration(e.EvalMS) * time.Millisecond,
+				Latency:            time.Duration(e.LatencyMS) * time.Millisecond,
+				Failed:             e.Error != "",
+			})
+		}
+		snapshot := registry.Snapshot()
+
+		if statsJSON {
+			out, err := utils.MarshalJSON(snapshot)
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Println(out)
+			return
+		}
+		fmt.Print(metrics.FormatTable(snapshot))
+	},
+}
+
+func init() {
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "Only include calls from this far back, e.g. 24h")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,56 @@
+// cmd/test_gen.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testGenIterations int
+	testGenOut        string
+	testGenForce      bool
+)
+
+var testGenCmd = &cobra.Command{
+	Use:   "test-gen <func>",
+	Short: "Generate a runnable table-driven test for a function",
+	Long: `Generates a table-driven _test.go file for a Go function using the LLM,
+writes it next to the source file and runs it with 'go test -run'. Compiler and
+test failures are fed back to the model for a bounded number of repairs.
+
+The function may be given as Name, Type.Method, or path/to/file.go:Name.
+Exits with status 1 when no passing test could be generated.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("[PRBuddy-Go] Generating tests for %s...\n", args[0])
+
+		result, err := llm.GenerateTests(args[0], llm.TestGenOptions{
+			MaxIterations: testGenIterations,
+			OutputPath:    testGenOut,
+			Force:         testGenForce,
+		})
+		if err != nil {
+			if result != nil && result.Output != "" {
+				fmt.Println(result.Output)
+			}
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.Green("[PRBuddy-Go] Wrote %s (%d test(s), passed after %d attempt(s))\n",
+			result.File, len(result.Tests), result.Iterations)
+	},
+}
+
+func init() {
+	testGenCmd.Flags().IntVar(&testGenIterations, "iterations", llm.DefaultTestGenIterations, "Maximum repair attempts after the first draft (0 disables repairs)")
+	testGenCmd.Flags().StringVar(&testGenOut, "out", "", "Repo-relative path of the _test.go file to write")
+	testGenCmd.Flags().BoolVar(&testGenForce, "force", false, "Overwrite the test file if it already exists")
+	rootCmd.AddCommand(testGenCmd)
+}
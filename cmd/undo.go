@@ -0,0 +1,97 @@
+// cmd/undo.go
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/patch"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var undoForce bool
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last code change applied from quickassist or DCE",
+	Long: `Restores the files touched by the most recently applied suggestion from the snapshot
+taken before it was applied. Run it again to walk back through earlier suggestions.
+Files edited since the suggestion was applied are left alone unless --force is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error retrieving repository path: %v\n", err)
+			return
+		}
+
+		snap, err := patch.Undo(repoPath, undoForce)
+		switch {
+		case errors.Is(err, patch.ErrNothingToUndo):
+			fmt.Println("[PRBuddy-Go] Nothing to undo.")
+			return
+		case errors.Is(err, patch.ErrChangedSinceApply):
+			color.Red("[PRBuddy-Go] %v\n", err)
+			fmt.Println("[PRBuddy-Go] Re-run with --force to restore them anyway and discard those edits.")
+			return
+		case err != nil:
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+
+		for _, f := range snap.Files {
+			if f.Existed {
+				color.Green("[PRBuddy-Go] Restored %s\n", f.Path)
+			} else {
+				color.Green("[PRBuddy-Go] Removed %s\n", f.Path)
+			}
+		}
+		fmt.Printf("[PRBuddy-Go] Undid the changes applied at %s.\n", snap.CreatedAt.Format("2006-01-02 15:04:05"))
+	},
+}
+
+// offerSuggestedPatch looks for unified diffs or search/replace blocks in an assistant
+// response and, if they apply cleanly, previews them and applies them on confirmation.
+func offerSuggestedPatch(reader *bufio.Reader, response string) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return
+	}
+	p, err := patch.FromResponse(repoPath, response)
+	if errors.Is(err, patch.ErrNoPatch) {
+		return
+	}
+	if err == nil {
+		err = p.Check()
+	}
+	if err != nil {
+		color.Yellow("\n[PRBuddy-Go] The suggested changes cannot be applied: %v\n", err)
+		return
+	}
+
+	color.Yellow("\n[PRBuddy-Go] Suggested changes to %d file(s):\n", len(p.Files))
+	patch.Preview(color.Output, p.Diff)
+	fmt.Print("[PRBuddy-Go] Apply these changes? [y/N] ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer != "y" && answer != "yes" {
+		fmt.Println("[PRBuddy-Go] Changes not applied.")
+		return
+	}
+
+	if _, err := p.Apply(); err != nil {
+		color.Red("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	color.Green("[PRBuddy-Go] Applied changes to %s. Run 'prbuddy-go undo' to revert them.\n", strings.Join(p.Files, ", "))
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Restore files even if they were edited after the changes were applied")
+	rootCmd.AddCommand(undoCmd)
+}
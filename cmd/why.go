@@ -0,0 +1,87 @@
+// cmd/why.go
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/history"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whyMaxCommits  int
+	whyHistoryOnly bool
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <file>:<line> | <func>",
+	Short: "Explain why a piece of code looks the way it does",
+	Long: `Locates the function at <file>:<line>, or the named function (Name, Type.Method or
+path/to/file.go:Name), follows its lines through history with 'git log -L' and
+'git blame', and asks the LLM to narrate how it evolved from the commit messages and
+any PR drafts PRBuddy saved for those commits.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error retrieving repository path: %v\n", err)
+			return
+		}
+
+		target, err := history.Locate(repoPath, args[0])
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		commits, err := history.Collect(target, whyMaxCommits)
+		if err != nil {
+			color.Red("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if len(commits) == 0 {
+			fmt.Printf("[PRBuddy-Go] %s has no committed history yet.\n", target)
+			return
+		}
+
+		var narrative string
+		if !whyHistoryOnly {
+			source, err := history.Source(repoPath, target)
+			if err != nil {
+				color.Red("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			fmt.Printf("[PRBuddy-Go] Explaining %s from %d commit(s)...\n", target, len(commits))
+			narrative, err = llm.ExplainHistory(target, source, commits)
+			if err != nil {
+				color.Red("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+		}
+
+		color.Cyan("\nHistory of %s:\n", target)
+		for _, c := range commits {
+			note := ""
+			if c.BlamedLines > 0 {
+				note = fmt.Sprintf(" (%d current line(s))", c.BlamedLines)
+			}
+			if c.Draft != "" {
+				note += " [PR draft]"
+			}
+			fmt.Printf("  %s %s %-16s %s%s\n", c.SHA[:7], c.Date, c.Author, c.Subject, note)
+		}
+		if narrative != "" {
+			fmt.Println()
+			fmt.Println(narrative)
+		}
+	},
+}
+
+func init() {
+	whyCmd.Flags().IntVar(&whyMaxCommits, "max-commits", history.DefaultMaxCommits, "Maximum commits of 'git log -L' history to include")
+	whyCmd.Flags().BoolVar(&whyHistoryOnly, "history-only", false, "List the commits without asking the LLM")
+	rootCmd.AddCommand(whyCmd)
+}
@@ -0,0 +1,61 @@
+// internal/agent/sandbox.go
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// resolve turns a model-supplied path into a repo-relative slash path and its absolute
+// location. Paths outside the repository, the .git directory, git-ignored files and
+// paths on the redaction denylist are refused.
+func (t *Toolbox) resolve(path string) (rel string, abs string, err error) {
+	rel, abs, err = utils.ResolveRepoPath(t.root, path)
+	if err != nil {
+		return "", "", err
+	}
+	if rel != "" && utils.IsGitIgnored(t.root, rel) {
+		return "", "", fmt.Errorf("%s is ignored by git and not available to tools", path)
+	}
+	if rel != "" && redact.Default().Denied(rel) {
+		return "", "", fmt.Errorf("%s is on the redaction denylist and not available to tools", path)
+	}
+	return rel, abs, nil
+}
+
+// requireFile resolves path and checks that it names a regular file.
+func (t *Toolbox) requireFile(path string) (string, string, error) {
+	rel, abs, err := t.resolve(path)
+	if err != nil {
+		return "", "", err
+	}
+	if rel == "" {
+		return "", "", errors.New("path is required")
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read %s: no such file", rel)
+	}
+	if info.IsDir() {
+		return "", "", fmt.Errorf("%s is a directory", rel)
+	}
+	return rel, abs, nil
+}
+
+// truncate caps tool output so a single call cannot flood the model's context.
+func truncate(output string, limit int) string {
+	if len(output) <= limit {
+		return output
+	}
+	cut := strings.LastIndex(output[:limit], "\n")
+	if cut <= 0 {
+		cut = limit
+	}
+	return fmt.Sprintf("%s\n... [truncated %d bytes; narrow the request]", output[:cut], len(output)-cut)
+}
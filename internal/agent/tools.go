@@ -0,0 +1,425 @@
+// internal/agent/tools.go
+
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// DefaultMaxOutputBytes caps what a single tool call returns to the model.
+const DefaultMaxOutputBytes = 12000
+
+// Limits on individual tools.
+const (
+	maxReadLines   = 400
+	maxLogEntries  = 50
+	defaultLogSize = 10
+)
+
+// Definition describes a tool to the model. Parameters is a JSON Schema object.
+type Definition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// Toolbox runs read-only repository tools on behalf of the model. Every path is
+// confined to the repository root.
+type Toolbox struct {
+	root           string
+	MaxOutputBytes int // DefaultMaxOutputBytes if zero
+}
+
+// NewToolbox returns a toolbox for the repository at root.
+func NewToolbox(root string) (*Toolbox, error) {
+	resolved, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository root: %w", err)
+	}
+	return &Toolbox{root: resolved}, nil
+}
+
+type toolFunc func(t *Toolbox, args json.RawMessage) (string, error)
+
+type toolSpec struct {
+	Definition
+	run toolFunc
+}
+
+var toolSpecs = []toolSpec{
+	{Definition{"read_file", "Read a text file from the repository, with line numbers. Use start_line/end_line for large files.",
+		schema(props{
+			"path":       {"string", "Repo-relative file path"},
+			"start_line": {"integer", "First line to return (1-based, default 1)"},
+			"end_line":   {"integer", fmt.Sprintf("Last line to return (default start_line+%d)", maxReadLines-1)},
+		}, "path")}, (*Toolbox).readFile},
+	{Definition{"grep", "Search tracked files for a regular expression (git grep -E). Returns line:text matches under a File: header per file.",
+		schema(props{
+			"pattern": {"string", "Extended regular expression"},
+			"path":    {"string", "Optional repo-relative directory or file to search in"},
+		}, "pattern")}, (*Toolbox).grep},
+	{Definition{"list_functions", "List Go functions and methods with their file, line range and signature.",
+		schema(props{
+			"path": {"string", "Optional repo-relative directory or file prefix"},
+		})}, (*Toolbox).listFunctions},
+	{Definition{"git_log", "Show recent commits, optionally only those touching a path.",
+		schema(props{
+			"path":      {"string", "Optional repo-relative path"},
+			"max_count": {"integer", fmt.Sprintf("Number of commits (default %d, at most %d)", defaultLogSize, maxLogEntries)},
+		})}, (*Toolbox).gitLog},
+	{Definition{"git_blame", "Show who last changed each line of a file, and in which commit.",
+		schema(props{
+			"path":       {"string", "Repo-relative file path"},
+			"start_line": {"integer", "First line (1-based)"},
+			"end_line":   {"integer", "Last line"},
+		}, "path", "start_line", "end_line")}, (*Toolbox).gitBlame},
+	{Definition{"show_diff", "Show uncommitted changes, or the changes made by one commit.",
+		schema(props{
+			"commit": {"string", "Optional commit (hash, branch or HEAD~n); omit for uncommitted changes"},
+			"path":   {"string", "Optional repo-relative path to limit the diff to"},
+		})}, (*Toolbox).showDiff},
+}
+
+type props map[string][2]string // name -> {JSON type, description}
+
+func schema(properties props, required ...string) map[string]interface{} {
+	p := make(map[string]interface{}, len(properties))
+	for name, spec := range properties {
+		p[name] = map[string]string{"type": spec[0], "description": spec[1]}
+	}
+	s := map[string]interface{}{"type": "object", "properties": p}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// Definitions returns the tools offered to the model.
+func (t *Toolbox) Definitions() []Definition {
+	defs := make([]Definition, len(toolSpecs))
+	for i, spec := range toolSpecs {
+		defs[i] = spec.Definition
+	}
+	return defs
+}
+
+// Execute runs one tool call. Failures are returned as text for the model to read,
+// so a bad path or pattern lets it correct itself instead of ending the conversation.
+// Output is redacted here as well as by the LLM client, so no caller of the toolbox
+// sees secrets.
+func (t *Toolbox) Execute(call contextpkg.ToolCall) string {
+	limit := t.MaxOutputBytes
+	if limit <= 0 {
+		limit = DefaultMaxOutputBytes
+	}
+	for _, spec := range toolSpecs {
+		if spec.Name != call.Function.Name {
+			continue
+		}
+		args := call.Function.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		output, err := spec.run(t, args)
+		if err != nil {
+			return "Error: " + err.Error()
+		}
+		if strings.TrimSpace(output) == "" {
+			output = "(no output)"
+		}
+		output, _ = redact.Default().Redact(output)
+		return truncate(output, limit)
+	}
+	return fmt.Sprintf("Error: unknown tool %q", call.Function.Name)
+}
+
+func decode(raw json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid arguments: %v", err)
+	}
+	return nil
+}
+
+// git runs a read-only git command in the repository.
+func (t *Toolbox) git(args ...string) (string, error) {
+	return utils.ExecGit(append([]string{"-C", t.root}, args...)...)
+}
+
+// safeRevision rejects revisions that git could parse as options.
+var safeRevision = regexp.MustCompile(`^[A-Za-z0-9_./~^@{}-]+$`)
+
+// ------------------------------------------------------------------------------
+// Tools
+// ------------------------------------------------------------------------------
+
+func (t *Toolbox) readFile(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	rel, abs, err := t.requireFile(args.Path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %v", rel, err)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", fmt.Errorf("%s is a binary file", rel)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	start := args.StartLine
+	if start < 1 {
+		start = 1
+	}
+	end := args.EndLine
+	if end < start || end-start >= maxReadLines {
+		end = start + maxReadLines - 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if start > len(lines) {
+		return "", fmt.Errorf("%s has only %d lines", rel, len(lines))
+	}
+
+	var b strings.Builder
+	// The File: header lets redaction apply per-file rules such as .env values.
+	fmt.Fprintf(&b, "File: %s\nLines %d-%d of %d\n", rel, start, end, len(lines))
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "%5d  %s\n", i, lines[i-1])
+	}
+	return b.String(), nil
+}
+
+func (t *Toolbox) grep(raw json.RawMessage) (string, error) {
+	var args struct {
+		Pattern string `json:"pattern"`
+		Path    string `json:"path"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	if args.Pattern == "" {
+		return "", errors.New("pattern is required")
+	}
+	rel, _, err := t.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	gitArgs := []string{"grep", "-n", "-z", "-I", "-E", "--no-color", "-e", args.Pattern}
+	if rel != "" {
+		gitArgs = append(gitArgs, "--", rel)
+	}
+	output, err := t.git(gitArgs...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "No matches.", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Group matches under File: headers so redaction sees which file each comes from,
+	// and leave out files on the denylist.
+	redactor := redact.Default()
+	var b strings.Builder
+	current := ""
+	for _, line := range utils.SplitLines(output) {
+		// With -z, "file\0line\0text".
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 || redactor.Denied(parts[0]) {
+			continue
+		}
+		file, match := parts[0], parts[1]+":"+parts[2]
+		if file != current {
+			fmt.Fprintf(&b, "File: %s\n", file)
+			current = file
+		}
+		b.WriteString(match + "\n")
+	}
+	if b.Len() == 0 {
+		return "No matches.", nil
+	}
+	return b.String(), nil
+}
+
+func (t *Toolbox) listFunctions(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	rel, _, err := t.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := t.git("ls-files", "--cached", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return "", err
+	}
+	var selected []string
+	for _, file := range utils.SplitLines(files) {
+		if strings.HasPrefix(file, rel) {
+			selected = append(selected, file)
+		}
+	}
+	functions, err := treesitter.ParseGoFiles(t.root, selected)
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		if functions[i].File != functions[j].File {
+			return functions[i].File < functions[j].File
+		}
+		return functions[i].StartLine < functions[j].StartLine
+	})
+
+	var b strings.Builder
+	for _, fn := range functions {
+		signature := fn.Signature
+		if signature == "" {
+			signature = "func " + fn.Name
+		}
+		fmt.Fprintf(&b, "%s:%d-%d  %s\n", fn.File, fn.StartLine, fn.EndLine, signature)
+	}
+	if b.Len() == 0 {
+		return "No Go functions found.", nil
+	}
+	return b.String(), nil
+}
+
+func (t *Toolbox) gitLog(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path     string `json:"path"`
+		MaxCount int    `json:"max_count"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	rel, _, err := t.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+	count := args.MaxCount
+	if count <= 0 {
+		count = defaultLogSize
+	}
+	if count > maxLogEntries {
+		count = maxLogEntries
+	}
+
+	gitArgs := []string{"log", "--no-color", "--date=short", "--format=%h %ad %an%n    %s", fmt.Sprintf("-n%d", count)}
+	if rel != "" {
+		gitArgs = append(gitArgs, "--", rel)
+	}
+	return t.git(gitArgs...)
+}
+
+func (t *Toolbox) gitBlame(raw json.RawMessage) (string, error) {
+	var args struct {
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	rel, _, err := t.requireFile(args.Path)
+	if err != nil {
+		return "", err
+	}
+	if args.StartLine < 1 || args.EndLine < args.StartLine {
+		return "", errors.New("start_line and end_line must be a valid 1-based range")
+	}
+	if args.EndLine-args.StartLine >= maxReadLines {
+		args.EndLine = args.StartLine + maxReadLines - 1
+	}
+	return t.git("blame", "--date=short", "-L", fmt.Sprintf("%d,%d", args.StartLine, args.EndLine), "--", rel)
+}
+
+func (t *Toolbox) showDiff(raw json.RawMessage) (string, error) {
+	var args struct {
+		Commit string `json:"commit"`
+		Path   string `json:"path"`
+	}
+	if err := decode(raw, &args); err != nil {
+		return "", err
+	}
+	rel, _, err := t.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var gitArgs []string
+	if args.Commit == "" {
+		gitArgs = []string{"diff", "HEAD", "--no-color"}
+	} else {
+		if strings.HasPrefix(args.Commit, "-") || !safeRevision.MatchString(args.Commit) {
+			return "", fmt.Errorf("invalid commit %q", args.Commit)
+		}
+		gitArgs = []string{"show", "--no-color", "--format=%h %an %ad%n%n%B", "--date=short", args.Commit}
+	}
+	if rel != "" {
+		gitArgs = append(gitArgs, "--", rel)
+	}
+	output, err := t.git(gitArgs...)
+	if err != nil {
+		return "", err
+	}
+	output = withoutDeniedFiles(output, redact.Default())
+	if output == "" && args.Commit == "" {
+		return "No uncommitted changes.", nil
+	}
+	return output, nil
+}
+
+// withoutDeniedFiles drops the per-file sections of git diff or git show output whose
+// old or new path is on the redaction denylist, as grep does for its matches. Text
+// before the first file, such as a commit header, is kept.
+func withoutDeniedFiles(output string, redactor *redact.Redactor) string {
+	var b strings.Builder
+	start := 0
+	keep := func(end int) {
+		section := output[start:end]
+		files := utils.ParseUnifiedDiff(section)
+		if len(files) == 0 || !(redactor.Denied(files[0].Path()) || redactor.Denied(files[0].OldPath)) {
+			b.WriteString(section)
+		}
+		start = end
+	}
+	for offset, line := 0, ""; offset < len(output); offset += len(line) {
+		line = output[offset:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		if strings.HasPrefix(line, "diff --git ") {
+			keep(offset)
+		}
+	}
+	keep(len(output))
+	return b.String()
+}
@@ -0,0 +1,292 @@
+// internal/apidiff/apidiff.go
+
+package apidiff
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// PostCommitEnv enables the Breaking Changes section of post-commit PR drafts, which
+// parses every library Go file at the merge-base and at HEAD on each commit.
+const PostCommitEnv = "PRBUDDY_API_DIFF"
+
+// Change kinds.
+const (
+	ChangeRemoved   = "removed"
+	ChangeRenamed   = "renamed"
+	ChangeSignature = "signature"
+	ChangeKind      = "kind"
+	ChangeMembers   = "members"
+)
+
+// Change is one breaking difference in the exported API.
+type Change struct {
+	Package string   `json:"package"`
+	Symbol  string   `json:"symbol"`
+	Kind    string   `json:"kind"`
+	Old     string   `json:"old,omitempty"`
+	New     string   `json:"new,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+// Report lists the breaking changes between two revisions.
+type Report struct {
+	Base    string   `json:"base"`
+	Head    string   `json:"head"`
+	Changes []Change `json:"changes"`
+}
+
+// Options controls which packages are compared.
+type Options struct {
+	IncludeInternal bool // Also compare packages under internal/, which outside modules cannot import
+}
+
+// DefaultBase guesses the branch a PR would target: origin's HEAD, then main, then master.
+func DefaultBase() (string, error) {
+	if ref, err := utils.ExecGit("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil && ref != "" {
+		return ref, nil
+	}
+	for _, candidate := range []string{"main", "master"} {
+		if _, err := utils.ExecGit("rev-parse", "--verify", "--quiet", candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine a base branch; pass one explicitly")
+}
+
+// Diff compares the exported API at the merge-base of base and HEAD against HEAD.
+func Diff(base string, opts Options) (*Report, error) {
+	mergeBase, err := utils.ExecGit("merge-base", base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge-base with %s: %w", base, err)
+	}
+	head, err := utils.GetLatestCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	report := &Report{Base: mergeBase, Head: head}
+	if mergeBase == head {
+		return report, nil
+	}
+
+	oldAPI, err := Snapshot(mergeBase, opts)
+	if err != nil {
+		return nil, err
+	}
+	newAPI, err := Snapshot(head, opts)
+	if err != nil {
+		return nil, err
+	}
+	report.Changes = Compare(oldAPI, newAPI)
+	return report, nil
+}
+
+// Snapshot collects the exported API of every library package at a revision, keyed by
+// "<package dir>.<symbol>". Test files, main packages and (by default) internal
+// packages are skipped.
+func Snapshot(rev string, opts Options) (map[string]treesitter.APISymbol, error) {
+	out, err := utils.ExecGit("ls-tree", "-r", "--name-only", rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", rev, err)
+	}
+
+	var files []string
+	for _, file := range utils.SplitLines(out) {
+		if isLibraryFile(file, opts) {
+			files = append(files, file)
+		}
+	}
+	blobs, err := utils.ReadBlobs(rev, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files at %s: %w", rev, err)
+	}
+
+	api := make(map[string]treesitter.APISymbol)
+	for _, file := range files {
+		content, ok := blobs[file]
+		if !ok {
+			continue
+		}
+		pkgName, symbols, err := treesitter.ParseAPI(path.Dir(file), file, content)
+		if err != nil || pkgName == "main" {
+			continue
+		}
+		for _, sym := range symbols {
+			api[sym.Package+"."+sym.Name] = sym
+		}
+	}
+	return api, nil
+}
+
+// isLibraryFile reports whether a file can contribute to the importable API.
+func isLibraryFile(file string, opts Options) bool {
+	if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	for _, segment := range strings.Split(path.Dir(file), "/") {
+		switch segment {
+		case "testdata", "vendor":
+			return false
+		case "internal":
+			if !opts.IncludeInternal {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Compare returns the breaking changes from oldAPI to newAPI. A removed symbol whose
+// exact shape reappears under a new name in the same package is reported as renamed.
+// Added struct fields are compatible; any interface method-set change is not, since
+// added methods break implementations and removed ones break callers.
+func Compare(oldAPI, newAPI map[string]treesitter.APISymbol) []Change {
+	added := make(map[string]treesitter.APISymbol)
+	for key, sym := range newAPI {
+		if _, ok := oldAPI[key]; !ok {
+			added[key] = sym
+		}
+	}
+
+	var changes []Change
+	for key, old := range oldAPI {
+		cur, ok := newAPI[key]
+		if !ok {
+			if renamed, found := findRename(old, added); found {
+				delete(added, renamed.Package+"."+renamed.Name)
+				changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeRenamed, Old: old.Name, New: renamed.Name})
+			} else {
+				changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeRemoved, Old: old.Signature})
+			}
+			continue
+		}
+
+		switch {
+		case old.Kind != cur.Kind:
+			changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeKind, Old: old.Kind, New: cur.Kind})
+		case old.Signature != cur.Signature:
+			changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeSignature, Old: old.Signature, New: cur.Signature})
+		case old.Kind == treesitter.APIKindStruct:
+			if details := removedMembers(old.Members, cur.Members, "field"); len(details) > 0 {
+				changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeMembers, Details: details})
+			}
+		case old.Kind == treesitter.APIKindInterface:
+			details := removedMembers(old.Members, cur.Members, "method")
+			for _, m := range cur.Members {
+				if !utils.StringSliceContains(old.Members, m) {
+					details = append(details, "added method "+m)
+				}
+			}
+			if len(details) > 0 {
+				changes = append(changes, Change{Package: old.Package, Symbol: old.Name, Kind: ChangeMembers, Details: details})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Package != changes[j].Package {
+			return changes[i].Package < changes[j].Package
+		}
+		return changes[i].Symbol < changes[j].Symbol
+	})
+	return changes
+}
+
+// findRename looks for an added symbol in the same package with the same kind, signature and members.
+func findRename(old treesitter.APISymbol, added map[string]treesitter.APISymbol) (treesitter.APISymbol, bool) {
+	var candidates []treesitter.APISymbol
+	for _, sym := range added {
+		if sym.Package == old.Package && sym.Kind == old.Kind && sym.Signature == old.Signature &&
+			strings.Join(sym.Members, ";") == strings.Join(old.Members, ";") {
+			candidates = append(candidates, sym)
+		}
+	}
+	// Only an unambiguous match counts as a rename.
+	if len(candidates) != 1 {
+		return treesitter.APISymbol{}, false
+	}
+	return candidates[0], true
+}
+
+// removedMembers describes members of old missing from cur, pairing a removed field with
+// its replacement when only the type changed.
+func removedMembers(old, cur []string, noun string) []string {
+	curByName := make(map[string]string)
+	for _, m := range cur {
+		curByName[memberName(m)] = m
+	}
+
+	var details []string
+	for _, m := range old {
+		if utils.StringSliceContains(cur, m) {
+			continue
+		}
+		if replacement, ok := curByName[memberName(m)]; ok {
+			details = append(details, fmt.Sprintf("changed %s %s to %s", noun, m, replacement))
+		} else {
+			details = append(details, fmt.Sprintf("removed %s %s", noun, m))
+		}
+	}
+	return details
+}
+
+// memberName returns the identifier of "Name Type" or "Name(params) results".
+func memberName(member string) string {
+	if idx := strings.IndexAny(member, " ("); idx != -1 {
+		return member[:idx]
+	}
+	return member
+}
+
+// HasBreakingChanges reports whether the report contains any change.
+func (r *Report) HasBreakingChanges() bool {
+	return len(r.Changes) > 0
+}
+
+// Markdown renders the report as a "Breaking Changes" section.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Breaking Changes\n\n")
+	for _, c := range r.Changes {
+		b.WriteString("- " + c.Describe() + "\n")
+		for _, d := range c.Details {
+			b.WriteString("  - " + d + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Describe renders a one-line description of the change.
+func (c Change) Describe() string {
+	symbol := fmt.Sprintf("`%s.%s`", path.Base(c.Package), c.Symbol)
+	switch c.Kind {
+	case ChangeRemoved:
+		return symbol + " was removed"
+	case ChangeRenamed:
+		return fmt.Sprintf("%s was renamed to `%s`", symbol, c.New)
+	case ChangeSignature:
+		return fmt.Sprintf("%s changed signature from `%s` to `%s`", symbol, c.Old, c.New)
+	case ChangeKind:
+		return fmt.Sprintf("%s changed from %s to %s", symbol, c.Old, c.New)
+	default:
+		return symbol + " changed its members"
+	}
+}
+
+// InsertSection adds a markdown section to a PR draft. Drafts are usually wrapped in a
+// single fenced code block, so the section goes inside the closing fence.
+func InsertSection(draft, section string) string {
+	trimmed := strings.TrimRight(draft, "\n ")
+	if strings.HasSuffix(trimmed, "```") && strings.Count(trimmed, "```") >= 2 {
+		body := strings.TrimRight(strings.TrimSuffix(trimmed, "```"), "\n ")
+		return body + "\n\n" + strings.TrimRight(section, "\n") + "\n```\n"
+	}
+	return trimmed + "\n\n" + section
+}
@@ -0,0 +1,232 @@
+// internal/audit/audit.go
+
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const (
+	// currentFile is the log being appended to; rotated logs are named audit-<timestamp>.jsonl.
+	currentFile = "audit.jsonl"
+	// MaxFileSize is the size at which the current log is rotated.
+	MaxFileSize = 5 << 20
+	// MaxRotatedFiles is how many rotated logs are kept.
+	MaxRotatedFiles = 10
+	// maxEntrySize bounds a single entry when reading; payloads can exceed MaxFileSize.
+	maxEntrySize = 64 << 20
+	// PayloadsEnv enables recording full prompts and responses when set to "1" or "true".
+	PayloadsEnv = "PRBUDDY_AUDIT_PAYLOADS"
+)
+
+// Entry is one LLM call. Prompts and responses are identified by hash; the full
+// payloads are only recorded when PRBUDDY_AUDIT_PAYLOADS is enabled.
+type Entry struct {
+	ID               string               `json:"id"`
+	Timestamp        time.Time            `json:"timestamp"`
+	Command          string               `json:"command"`
+	Model            string               `json:"model,omitempty"`
+	Endpoint         string               `json:"endpoint,omitempty"`
+	Stream           bool                 `json:"stream"`
+	PromptHash       string               `json:"prompt_hash"`
+	ResponseHash     string               `json:"response_hash,omitempty"`
+	PromptTokens     int                  `json:"prompt_tokens"`
+	CompletionTokens int                  `json:"completion_tokens"`
+	LatencyMS        int64                `json:"latency_ms"`
+	LoadMS           int64                `json:"load_ms,omitempty"`        // Model load time reported by Ollama
+	PromptEvalMS     int64                `json:"prompt_eval_ms,omitempty"` // Prompt evaluation time reported by Ollama
+	EvalMS           int64                `json:"eval_ms,omitempty"`        // Generation time reported by Ollama
+	Redactions       []redact.Redaction   `json:"redactions,omitempty"`
+	Error            string               `json:"error,omitempty"`
+	Messages         []contextpkg.Message `json:"messages,omitempty"`
+	Response         string               `json:"response,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	command = "prbuddy-go"
+)
+
+// SetCommand records which CLI command subsequent LLM calls belong to.
+func SetCommand(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	command = name
+}
+
+// Command returns the command set by SetCommand.
+func Command() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return command
+}
+
+// PayloadsEnabled reports whether full prompts and responses should be recorded.
+func PayloadsEnabled() bool {
+	v := strings.ToLower(os.Getenv(PayloadsEnv))
+	return v == "1" || v == "true"
+}
+
+// Hash returns the hex SHA-256 of s.
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashMessages hashes the messages exactly as they are serialized for the model.
+func HashMessages(messages []contextpkg.Message) string {
+	data, _ := json.Marshal(messages)
+	return Hash(string(data))
+}
+
+// Dir returns .git/pr_buddy_db/audit for the current repository.
+func Dir() (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository path: %w", err)
+	}
+	return filepath.Join(repoPath, ".git", "pr_buddy_db", "audit"), nil
+}
+
+// Append writes an entry to the audit log, assigning its ID and timestamp if unset and
+// rotating the log when it grows past MaxFileSize.
+func Append(entry Entry) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.ID == "" {
+		entry.ID = Hash(entry.Timestamp.Format(time.RFC3339Nano) + entry.PromptHash)[:12]
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	path := filepath.Join(dir, currentFile)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > MaxFileSize {
+		if err := rotate(dir); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate renames the current log and prunes the oldest rotated logs.
+func rotate(dir string) error {
+	rotated := filepath.Join(dir, fmt.Sprintf("audit-%s.jsonl", time.Now().UTC().Format("20060102-150405.000")))
+	if err := os.Rename(filepath.Join(dir, currentFile), rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	old, _ := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+	sort.Strings(old)
+	for len(old) > MaxRotatedFiles {
+		if err := os.Remove(old[0]); err != nil {
+			return fmt.Errorf("failed to prune audit log: %w", err)
+		}
+		old = old[1:]
+	}
+	return nil
+}
+
+// List returns all entries, oldest first, across rotated and current logs.
+func List() ([]Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+	sort.Strings(files)
+	files = append(files, filepath.Join(dir, currentFile))
+
+	var entries []Entry
+	for _, file := range files {
+		fileEntries, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return entries, nil
+}
+
+func readFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // A torn write must not hide the rest of the trail
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Find returns the entry whose ID starts with prefix.
+func Find(prefix string) (*Entry, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var match *Entry
+	for i := range entries {
+		if strings.HasPrefix(entries[i].ID, prefix) {
+			if match != nil {
+				return nil, fmt.Errorf("audit id %q is ambiguous", prefix)
+			}
+			match = &entries[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no audit entry with id %q", prefix)
+	}
+	return match, nil
+}
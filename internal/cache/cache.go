@@ -0,0 +1,201 @@
+// internal/cache/cache.go
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const (
+	// DefaultTTL is how long a cached response stays valid.
+	DefaultTTL = 7 * 24 * time.Hour
+	// DefaultMaxBytes caps the total size of the cache; the oldest entries are evicted first.
+	DefaultMaxBytes = 50 << 20
+
+	// TTLEnv overrides DefaultTTL with a Go duration, e.g. "24h".
+	TTLEnv = "PRBUDDY_CACHE_TTL"
+	// MaxBytesEnv overrides DefaultMaxBytes.
+	MaxBytesEnv = "PRBUDDY_CACHE_MAX_BYTES"
+	// DisableEnv disables the cache when set to "1" or "true", e.g. for git hooks.
+	DisableEnv = "PRBUDDY_NO_CACHE"
+
+	subdir = "responses"
+)
+
+// entry is the on-disk form of a cached response.
+type entry struct {
+	Created  time.Time `json:"created"`
+	Model    string    `json:"model"`
+	Response string    `json:"response"`
+}
+
+var (
+	mu       sync.Mutex
+	disabled bool
+)
+
+// Disable turns the cache off for the rest of the process (the --no-cache flag).
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	disabled = true
+}
+
+// Enabled reports whether responses may be served from or written to the cache.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	v := strings.ToLower(os.Getenv(DisableEnv))
+	return !disabled && v != "1" && v != "true"
+}
+
+// Key hashes everything that determines a deterministic model response.
+func Key(model string, options map[string]interface{}, messages []contextpkg.Message) string {
+	data, _ := json.Marshal(struct {
+		Model    string                 `json:"model"`
+		Options  map[string]interface{} `json:"options"`
+		Messages []contextpkg.Message   `json:"messages"`
+	}{model, options, messages})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Dir returns the response cache directory inside the application cache directory.
+func Dir() (string, error) {
+	appDir, err := utils.AppCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(appDir, subdir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create response cache: %w", err)
+	}
+	return dir, nil
+}
+
+// Get returns the cached response for key if it exists and has not expired.
+func Get(key string) (string, bool) {
+	dir, err := Dir()
+	if err != nil {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return "", false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || time.Since(e.Created) > ttl() {
+		return "", false
+	}
+	return e.Response, true
+}
+
+// Put stores a response and evicts expired entries and, if the cache is over its size
+// limit, the oldest ones.
+func Put(key, model, response string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{Created: time.Now().UTC(), Model: model, Response: response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, key+".json")); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return prune(dir)
+}
+
+// prune removes expired entries, then the oldest until the cache fits in its size limit.
+func prune(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > ttl() {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, file{path, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for len(files) > 0 && total > maxBytes() {
+		os.Remove(files[0].path)
+		total -= files[0].size
+		files = files[1:]
+	}
+	return nil
+}
+
+// Clear deletes every cached response and returns how many were removed.
+func Clear() (int, error) {
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return 0, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return len(paths), nil
+}
+
+func ttl() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(TTLEnv)); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTTL
+}
+
+func maxBytes() int64 {
+	if n, err := strconv.ParseInt(os.Getenv(MaxBytesEnv), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return DefaultMaxBytes
+}
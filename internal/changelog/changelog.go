@@ -0,0 +1,223 @@
+// internal/changelog/changelog.go
+
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Entry categories.
+const (
+	CategoryFeature  = "feature"
+	CategoryFix      = "fix"
+	CategoryBreaking = "breaking"
+	CategoryChange   = "change" // Refactors, performance work and reverts
+	CategoryChore    = "chore"
+)
+
+// Categories lists every valid category.
+var Categories = []string{CategoryFeature, CategoryFix, CategoryBreaking, CategoryChange, CategoryChore}
+
+// Commit is a commit in the changelog range.
+type Commit struct {
+	SHA     string
+	Subject string
+	Body    string
+	Draft   string // Stored PR draft for this commit, if any
+}
+
+// Entry is one classified changelog line.
+type Entry struct {
+	SHA      string
+	Category string
+	Scope    string
+	Summary  string
+}
+
+// Options controls rendering.
+type Options struct {
+	Version       string // Section title; "Unreleased" if empty
+	Date          string // YYYY-MM-DD, omitted if empty
+	IncludeChores bool
+}
+
+// header is written when --prepend creates a new changelog file.
+const header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+
+`
+
+// commitSeparator splits records in CollectCommits' git log output.
+const commitSeparator = "\x1e"
+
+// NormalizeRange turns "v1.2.0" into "v1.2.0..HEAD" and leaves explicit ranges alone.
+func NormalizeRange(rangeSpec string) string {
+	if strings.Contains(rangeSpec, "..") {
+		return rangeSpec
+	}
+	return rangeSpec + "..HEAD"
+}
+
+// RangeEnd returns the end revision of a range, e.g. "v1.3.0" for "v1.2.0..v1.3.0".
+func RangeEnd(rangeSpec string) string {
+	parts := strings.Split(NormalizeRange(rangeSpec), "..")
+	end := strings.TrimPrefix(parts[len(parts)-1], ".")
+	if end == "" {
+		return "HEAD"
+	}
+	return end
+}
+
+// CollectCommits lists the non-merge commits in the range, oldest first.
+func CollectCommits(rangeSpec string) ([]Commit, error) {
+	out, err := utils.ExecGit("log", "--no-merges", "--reverse",
+		"--format=%H%x1f%s%x1f%b"+commitSeparator, NormalizeRange(rangeSpec))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", rangeSpec, err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, commitSeparator) {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		c := Commit{SHA: fields[0], Subject: strings.TrimSpace(fields[1])}
+		if len(fields) == 3 {
+			c.Body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// ClassifyConventional classifies a commit from its Conventional Commits message.
+// It returns false when the message does not follow the convention.
+func ClassifyConventional(c Commit) (Entry, bool) {
+	msg, ok := utils.ParseConventionalCommit(c.Subject + "\n\n" + c.Body)
+	if !ok {
+		return Entry{}, false
+	}
+
+	entry := Entry{SHA: c.SHA, Scope: msg.Scope, Summary: msg.Subject}
+	switch {
+	case msg.Breaking:
+		entry.Category = CategoryBreaking
+	case msg.Type == "feat":
+		entry.Category = CategoryFeature
+	case msg.Type == "fix":
+		entry.Category = CategoryFix
+	case msg.Type == "perf" || msg.Type == "refactor" || msg.Type == "revert":
+		entry.Category = CategoryChange
+	default:
+		entry.Category = CategoryChore
+	}
+	return entry, true
+}
+
+// sections maps categories onto Keep-a-Changelog headings, in output order.
+var sections = []struct {
+	title      string
+	categories []string
+}{
+	{"Added", []string{CategoryFeature}},
+	{"Changed", []string{CategoryBreaking, CategoryChange, CategoryChore}},
+	{"Fixed", []string{CategoryFix}},
+}
+
+// Render produces a Keep-a-Changelog section for the entries.
+func Render(entries []Entry, opts Options) string {
+	version := opts.Version
+	if version == "" {
+		version = "Unreleased"
+	}
+
+	var b strings.Builder
+	b.WriteString("## [" + version + "]")
+	if opts.Date != "" && version != "Unreleased" {
+		b.WriteString(" - " + opts.Date)
+	}
+	b.WriteString("\n")
+
+	for _, section := range sections {
+		var lines []string
+		// Within a section, categories are listed in the order given (breaking first).
+		for _, category := range section.categories {
+			if category == CategoryChore && !opts.IncludeChores {
+				continue
+			}
+			for _, e := range entries {
+				if e.Category == category {
+					lines = append(lines, formatEntry(e))
+				}
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		b.WriteString("\n### " + section.title + "\n\n")
+		b.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+	return b.String()
+}
+
+func formatEntry(e Entry) string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if e.Category == CategoryBreaking {
+		b.WriteString("**BREAKING:** ")
+	}
+	if e.Scope != "" {
+		b.WriteString("**" + e.Scope + ":** ")
+	}
+	b.WriteString(e.Summary)
+	if len(e.SHA) >= 7 {
+		b.WriteString(" (" + e.SHA[:7] + ")")
+	}
+	return b.String()
+}
+
+// sectionHeadingPattern matches a Keep-a-Changelog version heading.
+var sectionHeadingPattern = regexp.MustCompile(`(?m)^## \[([^\]]+)\]`)
+
+// Prepend inserts section into the changelog file above the newest existing version,
+// creating the file with a standard header if it does not exist. It refuses to add a
+// second section for a version that is already present.
+func Prepend(path, section string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	existing := string(data)
+	if existing == "" {
+		existing = header
+	}
+
+	if m := sectionHeadingPattern.FindStringSubmatch(section); m != nil {
+		for _, present := range sectionHeadingPattern.FindAllStringSubmatch(existing, -1) {
+			if present[1] == m[1] {
+				return fmt.Errorf("%s already has a section for %s", path, m[1])
+			}
+		}
+	}
+
+	var updated string
+	if loc := sectionHeadingPattern.FindStringIndex(existing); loc != nil {
+		updated = existing[:loc[0]] + section + "\n" + existing[loc[0]:]
+	} else {
+		updated = strings.TrimRight(existing, "\n") + "\n\n" + section
+	}
+
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
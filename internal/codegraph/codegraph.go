@@ -0,0 +1,396 @@
+// internal/codegraph/codegraph.go
+
+package codegraph
+
+import (
+	"bufio"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+)
+
+// commonMethods are method names most types in the standard library share (fmt.Stringer,
+// error, io.Reader and friends). Calls by these names are left unresolved when they would
+// match a project method, since strings.Builder.String() and Target.String() look alike
+// by name.
+var commonMethods = map[string]bool{
+	"String": true, "Error": true, "Unwrap": true, "Close": true, "Read": true, "Write": true,
+	"Len": true, "Less": true, "Swap": true, "MarshalJSON": true, "UnmarshalJSON": true,
+}
+
+// Package is a Go package of the repository. Test files are left out.
+type Package struct {
+	Dir       string                 `json:"dir"`  // Repo-relative directory, "." for the root
+	Name      string                 `json:"name"` // From the package clause
+	Files     []string               `json:"files"`
+	Imports   []string               `json:"imports"`   // Repository packages it imports, as directories
+	Types     []treesitter.APISymbol `json:"types"`     // Exported type declarations
+	Functions []int                  `json:"functions"` // Indexes into Graph.Functions
+	External  []string               `json:"external"`  // Imports from outside the repository
+}
+
+// Graph is the package and call structure of a repository. Calls are resolved by name,
+// as recorded in the project map, preferring functions of the caller's own package.
+type Graph struct {
+	Module    string                    // Module path from go.mod; empty without one
+	Packages  []*Package                // Sorted by directory
+	Functions []treesitter.FunctionInfo // Repo-relative paths, test files excluded
+
+	byDir       map[string]*Package
+	byName      map[string][]int
+	testImports map[string][]string // Test file -> repository packages it imports; BuildWithTests only
+	callees     [][]int
+	callers     [][]int
+}
+
+// Build analyses the Go files of the repository at repoPath. files and functions are
+// the project map's source files and functions; their paths may be repo-relative or
+// in the project map's "/<repo>/path" form.
+func Build(repoPath string, files []string, functions []treesitter.FunctionInfo) *Graph {
+	return build(repoPath, files, functions, false)
+}
+
+// BuildWithTests is Build with test files included as functions of their directory's
+// package. Their imports are kept per file, so package imports and the dependency
+// order are the same as Build's.
+func BuildWithTests(repoPath string, files []string, functions []treesitter.FunctionInfo) *Graph {
+	return build(repoPath, files, functions, true)
+}
+
+func build(repoPath string, files []string, functions []treesitter.FunctionInfo, tests bool) *Graph {
+	g := &Graph{
+		Module:      modulePath(repoPath),
+		byDir:       make(map[string]*Package),
+		byName:      make(map[string][]int),
+		testImports: make(map[string][]string),
+	}
+
+	imports := make(map[string][]string) // Package dir -> raw import paths
+	for _, file := range files {
+		file = treesitter.RepoRelativePath(filepath.ToSlash(file))
+		if isTestFile(file) && !tests {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(file)
+		name, symbols, err := treesitter.ParseAPI(dir, file, content)
+		if err != nil || name == "" {
+			continue
+		}
+		fileImports, _ := treesitter.ParseImports(file, content)
+		if isTestFile(file) {
+			g.pkg(dir, strings.TrimSuffix(name, "_test"))
+			g.testImports[file] = fileImports
+			continue
+		}
+		pkg := g.pkg(dir, name)
+		pkg.Files = append(pkg.Files, file)
+		for _, sym := range symbols {
+			switch sym.Kind {
+			case treesitter.APIKindStruct, treesitter.APIKindInterface, treesitter.APIKindType:
+				pkg.Types = append(pkg.Types, sym)
+			}
+		}
+		imports[dir] = append(imports[dir], fileImports...)
+	}
+
+	for _, fn := range functions {
+		fn.File = treesitter.RepoRelativePath(fn.File)
+		if isTestFile(fn.File) && !tests {
+			continue
+		}
+		pkg, ok := g.byDir[path.Dir(fn.File)]
+		if !ok {
+			continue
+		}
+		i := len(g.Functions)
+		g.Functions = append(g.Functions, fn)
+		pkg.Functions = append(pkg.Functions, i)
+		g.byName[fn.Name] = append(g.byName[fn.Name], i)
+	}
+
+	for dir, paths := range imports {
+		pkg := g.byDir[dir]
+		for _, p := range paths {
+			if target, ok := g.internalDir(p); ok {
+				if target != dir && !contains(pkg.Imports, target) {
+					pkg.Imports = append(pkg.Imports, target)
+				}
+			} else if !contains(pkg.External, p) {
+				pkg.External = append(pkg.External, p)
+			}
+		}
+		sort.Strings(pkg.Imports)
+		sort.Strings(pkg.External)
+	}
+	for file, paths := range g.testImports {
+		var dirs []string
+		for _, p := range paths {
+			if target, ok := g.internalDir(p); ok && !contains(dirs, target) {
+				dirs = append(dirs, target)
+			}
+		}
+		g.testImports[file] = dirs
+	}
+	sort.Slice(g.Packages, func(i, j int) bool { return g.Packages[i].Dir < g.Packages[j].Dir })
+
+	g.linkCalls()
+	return g
+}
+
+func (g *Graph) pkg(dir, name string) *Package {
+	if pkg, ok := g.byDir[dir]; ok {
+		return pkg
+	}
+	pkg := &Package{Dir: dir, Name: name}
+	g.byDir[dir] = pkg
+	g.Packages = append(g.Packages, pkg)
+	return pkg
+}
+
+// internalDir maps an import path to a repository package directory.
+func (g *Graph) internalDir(importPath string) (string, bool) {
+	if g.Module == "" {
+		return "", false
+	}
+	dir := ""
+	switch {
+	case importPath == g.Module:
+		dir = "."
+	case strings.HasPrefix(importPath, g.Module+"/"):
+		dir = strings.TrimPrefix(importPath, g.Module+"/")
+	default:
+		return "", false
+	}
+	_, ok := g.byDir[dir]
+	return dir, ok
+}
+
+// linkCalls resolves every invocation by name. A function of the caller's own package
+// wins; otherwise the call goes to exported functions of the packages the caller
+// imports, so calls into the standard library do not match project functions.
+// Functions of test files are only reachable from the same directory.
+func (g *Graph) linkCalls() {
+	g.callees = make([][]int, len(g.Functions))
+	g.callers = make([][]int, len(g.Functions))
+	for i, fn := range g.Functions {
+		dir := path.Dir(fn.File)
+		imports := g.byDir[dir].Imports
+		if isTestFile(fn.File) {
+			imports = append(append([]string(nil), imports...), g.testImports[fn.File]...)
+		}
+		seen := make(map[int]bool)
+		for _, name := range Invocations(fn) {
+			var local, imported []int
+			for _, j := range g.byName[name] {
+				target := g.Functions[j]
+				if j == i || (commonMethods[name] && target.Receiver != "") {
+					continue // Recursion adds nothing to the graph
+				}
+				if isTestFile(target.File) && !isTestFile(fn.File) {
+					continue // Test files are not compiled into the package
+				}
+				switch targetDir := path.Dir(target.File); {
+				case targetDir == dir:
+					local = append(local, j)
+				case token.IsExported(name) && !isTestFile(target.File) && contains(imports, targetDir):
+					imported = append(imported, j)
+				}
+			}
+			if len(local) == 0 {
+				local = imported
+			}
+			for _, j := range local {
+				if !seen[j] {
+					seen[j] = true
+					g.callees[i] = append(g.callees[i], j)
+					g.callers[j] = append(g.callers[j], i)
+				}
+			}
+		}
+	}
+}
+
+// Package returns the package in a repo-relative directory.
+func (g *Graph) Package(dir string) (*Package, bool) {
+	pkg, ok := g.byDir[dir]
+	return pkg, ok
+}
+
+// PackageOf returns the directory of the package that declares function i.
+func (g *Graph) PackageOf(i int) string {
+	return path.Dir(g.Functions[i].File)
+}
+
+// Callees returns the indexes of the project functions function i calls.
+func (g *Graph) Callees(i int) []int { return g.callees[i] }
+
+// Callers returns the indexes of the project functions that call function i.
+func (g *Graph) Callers(i int) []int { return g.callers[i] }
+
+// Ranked is a function with its number of distinct callers.
+type Ranked struct {
+	Index   int
+	Callers int
+}
+
+// MostCalled returns up to n functions ranked by how many functions call them, the
+// in-degree centrality of the call graph. Functions nobody calls are left out.
+func (g *Graph) MostCalled(n int) []Ranked {
+	var ranked []Ranked
+	for i := range g.Functions {
+		if c := len(g.callers[i]); c > 0 {
+			ranked = append(ranked, Ranked{Index: i, Callers: c})
+		}
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if ranked[a].Callers != ranked[b].Callers {
+			return ranked[a].Callers > ranked[b].Callers
+		}
+		fa, fb := g.Functions[ranked[a].Index], g.Functions[ranked[b].Index]
+		return DisplayName(fa) < DisplayName(fb)
+	})
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// Flow is the calls from one package into another.
+type Flow struct {
+	From  string
+	To    string
+	Calls int
+	Via   []string // Distinct called functions, as Type.Method or Name
+}
+
+// Flows returns the cross-package calls, busiest first.
+func (g *Graph) Flows() []Flow {
+	index := make(map[[2]string]int)
+	var flows []Flow
+	for i := range g.Functions {
+		from := g.PackageOf(i)
+		for _, j := range g.callees[i] {
+			to := g.PackageOf(j)
+			if to == from {
+				continue
+			}
+			key := [2]string{from, to}
+			k, ok := index[key]
+			if !ok {
+				k = len(flows)
+				index[key] = k
+				flows = append(flows, Flow{From: from, To: to})
+			}
+			flows[k].Calls++
+			if name := DisplayName(g.Functions[j]); !contains(flows[k].Via, name) {
+				flows[k].Via = append(flows[k].Via, name)
+			}
+		}
+	}
+	sort.SliceStable(flows, func(a, b int) bool {
+		if flows[a].Calls != flows[b].Calls {
+			return flows[a].Calls > flows[b].Calls
+		}
+		if flows[a].From != flows[b].From {
+			return flows[a].From < flows[b].From
+		}
+		return flows[a].To < flows[b].To
+	})
+	return flows
+}
+
+// ImportedBy returns the directories of the repository packages that import dir.
+func (g *Graph) ImportedBy(dir string) []string {
+	var out []string
+	for _, pkg := range g.Packages {
+		if contains(pkg.Imports, dir) {
+			out = append(out, pkg.Dir)
+		}
+	}
+	return out
+}
+
+// DependencyOrder returns the package directories with every package after the
+// packages it imports; import cycles are broken by directory order.
+func (g *Graph) DependencyOrder() []string {
+	var order []string
+	state := make(map[string]int) // 1 visiting, 2 done
+	var visit func(dir string)
+	visit = func(dir string) {
+		if state[dir] != 0 {
+			return
+		}
+		state[dir] = 1
+		for _, dep := range g.byDir[dir].Imports {
+			visit(dep)
+		}
+		state[dir] = 2
+		order = append(order, dir)
+	}
+	for _, pkg := range g.Packages {
+		visit(pkg.Dir)
+	}
+	return order
+}
+
+// Invocations lists the distinct names fn calls, in order of first appearance.
+func Invocations(fn treesitter.FunctionInfo) []string {
+	seen := make(map[string]bool)
+	var out []string
+	deps := fn.Dependencies
+	for _, group := range [][]string{deps.Utilities, deps.Handlers, deps.Invocations} {
+		for _, name := range group {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+// DisplayName renders a function as Type.Method or Name.
+func DisplayName(fn treesitter.FunctionInfo) string {
+	if fn.Receiver != "" {
+		return fn.Receiver + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// modulePath reads the module path from go.mod at repoPath.
+func modulePath(repoPath string) string {
+	f, err := os.Open(filepath.Join(repoPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+func isTestFile(file string) bool {
+	return strings.HasSuffix(file, "_test.go")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
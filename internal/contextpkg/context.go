@@ -2,6 +2,7 @@
 package contextpkg
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -14,10 +15,25 @@ import (
 
 // Message represents a chat message.
 type Message struct {
-	Role      string        `json:"role"`                 // e.g., "user", "assistant", "system"
-	Content   string        `json:"content,omitempty"`    // The main text content
-	Images    []string      `json:"images,omitempty"`     // Optional: image paths for multimodal models
-	ToolCalls []interface{} `json:"tool_calls,omitempty"` // Optional: tool calls (if applicable)
+	Role       string     `json:"role"`                   // e.g., "user", "assistant", "system"
+	Content    string     `json:"content,omitempty"`      // The main text content
+	Images     []string   `json:"images,omitempty"`       // Optional: image paths for multimodal models
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Optional: functions the assistant asked to call
+	ToolName   string     `json:"tool_name,omitempty"`    // For role "tool": the function that produced Content
+	ToolCallID string     `json:"tool_call_id,omitempty"` // For role "tool": the call answered, when the model assigns IDs
+}
+
+// ToolCall is a function call requested by the model.
+type ToolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction names the function to call. Arguments is always a JSON object,
+// whatever encoding the model used on the wire.
+type ToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // Task represents a unit of work.
@@ -27,8 +43,17 @@ type Task struct {
 	Functions    []string `json:"functions"`
 	Dependencies []string `json:"dependencies"`
 	Notes        []string `json:"notes"`
+	Status       string   `json:"status,omitempty"`  // One of the TaskStatus* values; empty means open
+	Commits      []string `json:"commits,omitempty"` // Commit SHAs that advanced or closed the task
 }
 
+// Task status values.
+const (
+	TaskStatusOpen       = "open"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+)
+
 // Conversation represents a single conversation thread.
 type Conversation struct {
 	ID             string
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/metrics"
 )
 
 // outputWriter is used for all command output to enable testability
@@ -72,6 +76,14 @@ func HandleDCECommandMenu(input string, littleguy *LittleGuy) bool {
 		displayDCEStatus(littleguy)
 		return true
 
+	case lowerInput == "/suggestions", lowerInput == "/suggest":
+		displaySuggestions(littleguy)
+		return true
+
+	case lowerInput == "/snooze", strings.HasPrefix(lowerInput, "/snooze "):
+		handleSnoozeCommand(trimmedInput, littleguy)
+		return true
+
 	default:
 		return false
 	}
@@ -143,6 +155,9 @@ func displayTaskList(littleguy *LittleGuy, verbose bool) {
 		fmt.Fprintf(outputWriter, "  %d) %s\n", i+1, task.Description)
 
 		if verbose {
+			if task.Status == contextpkg.TaskStatusInProgress {
+				fmt.Fprintf(outputWriter, "     Status: in progress (commits: %s)\n", shortSHAs(task.Commits))
+			}
 			if len(task.Files) > 0 {
 				fmt.Fprintf(outputWriter, "     Files: %s\n", strings.Join(task.Files, ", "))
 			}
@@ -206,12 +221,28 @@ func displayDCEStatus(littleguy *LittleGuy) {
 		status = "INACTIVE"
 	}
 	taskCount := len(littleguy.tasks)
+	suggestionCount := len(littleguy.suggestions)
 	littleguy.mutex.RUnlock()
 
 	fmt.Fprintf(outputWriter, "  Status: %s\n", status)
 	fmt.Fprintf(outputWriter, "  Active Tasks: %d\n", taskCount)
+	fmt.Fprintf(outputWriter, "  Pending Suggestions: %d\n", suggestionCount)
 	fmt.Fprintf(outputWriter, "  Monitoring Interval: %v\n", littleguy.pollInterval)
 	fmt.Fprintf(outputWriter, "  Features: Dynamic task tracking, Git change monitoring\n")
+
+	snapshot := metrics.Default.Snapshot()
+	fmt.Fprintf(outputWriter, "  LLM Calls: %d (%d failed)\n", snapshot.Total.Calls, snapshot.Total.Errors)
+	models := make([]string, 0, len(snapshot.ByModel))
+	for model := range snapshot.ByModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		agg := snapshot.ByModel[model]
+		fmt.Fprintf(outputWriter, "    %s: %d call(s), %d prompt / %d output tokens, %.1f tok/s, avg latency %v\n",
+			model, agg.Calls, agg.PromptTokens, agg.CompletionTokens, agg.TokensPerSecond(),
+			agg.AverageLatency().Round(time.Millisecond))
+	}
 }
 
 // handlePriorityCommand allows users to set task priorities
@@ -262,6 +293,7 @@ func handlePriorityCommand(input string, littleguy *LittleGuy) {
 	}
 
 	// Update task priority
+	defer littleguy.flushTasks()
 	littleguy.mutex.Lock()
 	defer littleguy.mutex.Unlock()
 
@@ -297,6 +329,7 @@ func handlePriorityCommand(input string, littleguy *LittleGuy) {
 	}
 
 	task.Notes = newNotes
+	littleguy.persistLocked()
 }
 
 // handleCompleteCommand marks tasks as completed and shows remaining tasks
@@ -325,13 +358,16 @@ func handleCompleteCommand(input string, littleguy *LittleGuy) {
 	}
 
 	task := littleguy.tasks[taskNum-1]
+	task.Status = contextpkg.TaskStatusDone
 
 	// Remove the task from tasks and add to completed
 	littleguy.tasks = append(littleguy.tasks[:taskNum-1], littleguy.tasks[taskNum:]...)
 	littleguy.completed = append(littleguy.completed, task)
+	littleguy.persistLocked()
 
 	taskCount := len(littleguy.tasks)
 	littleguy.mutex.Unlock()
+	littleguy.flushTasks()
 
 	color.New(color.FgGreen).Fprintf(outputWriter, "[Complete] Task %d marked as completed: %s\n", taskNum, task.Description)
 
@@ -348,6 +384,72 @@ func handleCompleteCommand(input string, littleguy *LittleGuy) {
 	}
 }
 
+// shortSHAs abbreviates commit SHAs to seven characters for display.
+func shortSHAs(shas []string) string {
+	short := make([]string, 0, len(shas))
+	for _, sha := range shas {
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		short = append(short, sha)
+	}
+	return strings.Join(short, ", ")
+}
+
+// displaySuggestions runs the suggestion engine and lists the active suggestions.
+func displaySuggestions(littleguy *LittleGuy) {
+	color.New(color.FgCyan).Fprintf(outputWriter, "\n[Suggestions] Checking changed functions...\n")
+
+	littleguy.CheckForQueries()
+	suggestions := littleguy.Suggestions()
+	if len(suggestions) == 0 {
+		color.New(color.FgGreen).Fprintf(outputWriter, "  No suggestions. Nice work!\n")
+		return
+	}
+
+	for i, s := range suggestions {
+		fmt.Fprintf(outputWriter, "  %d) %s (%s:%d)\n", i+1, s.Message, s.File, s.Line)
+	}
+	fmt.Fprintf(outputWriter, "\nUse '/snooze <num> [duration]' to hide a suggestion.\n")
+}
+
+// handleSnoozeCommand snoozes one or all active suggestions.
+func handleSnoozeCommand(input string, littleguy *LittleGuy) {
+	parts := strings.Fields(input)
+	if len(parts) < 2 || len(parts) > 3 {
+		color.New(color.FgRed).Fprintf(outputWriter, "[X] Usage: /snooze <suggestion-number|all> [duration]\n")
+		return
+	}
+
+	duration := time.Hour
+	if len(parts) == 3 {
+		d, err := time.ParseDuration(parts[2])
+		if err != nil || d <= 0 {
+			color.New(color.FgRed).Fprintf(outputWriter, "[X] Invalid duration (examples: 30m, 2h)\n")
+			return
+		}
+		duration = d
+	}
+
+	suggestions := littleguy.Suggestions()
+	var targets []Suggestion
+	if strings.EqualFold(parts[1], "all") {
+		targets = suggestions
+	} else {
+		num, err := strconv.Atoi(parts[1])
+		if err != nil || num < 1 || num > len(suggestions) {
+			color.New(color.FgRed).Fprintf(outputWriter, "[X] Invalid suggestion number\n")
+			return
+		}
+		targets = []Suggestion{suggestions[num-1]}
+	}
+
+	for _, s := range targets {
+		littleguy.SnoozeSuggestion(s.ID, duration)
+	}
+	color.New(color.FgGreen).Fprintf(outputWriter, "[Snooze] Snoozed %d suggestion(s) for %v\n", len(targets), duration)
+}
+
 // refreshTaskList manually triggers a task list refresh
 func refreshTaskList(littleguy *LittleGuy) {
 	color.New(color.FgCyan).Fprintf(outputWriter, "\n[Refresh] Refreshing task list from git changes...\n")
@@ -376,5 +478,7 @@ func displayCommandMenu() {
 	fmt.Fprint(outputWriter, "  /complete <num>        - Mark a task as completed\n")
 	fmt.Fprint(outputWriter, "  /refresh               - Manually refresh task list from git\n")
 	fmt.Fprint(outputWriter, "  /status                - Show detailed DCE status\n")
+	fmt.Fprint(outputWriter, "  /suggestions           - Check changed functions for tests, docs, and errors\n")
+	fmt.Fprint(outputWriter, "  /snooze <num|all> [dur]- Snooze suggestions (default 1h)\n")
 	fmt.Fprint(outputWriter, "  /commands, /cmds, /help- Show this command menu\n")
 }
@@ -0,0 +1,255 @@
+// internal/dce/commit_tracker.go
+
+package dce
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxJudgeDiffLines caps how much of the commit diff is shown to the LLM judge.
+const maxJudgeDiffLines = 400
+
+// CommitChanges describes the files and Go functions touched by a single commit.
+type CommitChanges struct {
+	SHA       string
+	Message   string
+	Files     []string
+	Functions []string
+	Diff      string
+}
+
+// CommitReconciliation summarizes how a commit affected the open task list.
+type CommitReconciliation struct {
+	SHA        string
+	Closed     []contextpkg.Task
+	Progressed []contextpkg.Task
+}
+
+// Summary renders a one-line summary such as "commit abc1234 closed 2 tasks".
+func (r *CommitReconciliation) Summary() string {
+	short := r.SHA
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	summary := fmt.Sprintf("commit %s closed %d %s", short, len(r.Closed), pluralize("task", len(r.Closed)))
+	if len(r.Progressed) > 0 {
+		summary += fmt.Sprintf(", advanced %d", len(r.Progressed))
+	}
+	return summary
+}
+
+// CollectCommitChanges lists the files a commit touched and, for Go files, the
+// functions whose bodies overlap the commit's hunks.
+func CollectCommitChanges(sha string) (*CommitChanges, error) {
+	diff, err := utils.ExecGit("show", "--format=", "--unified=0", "--no-color", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit diff: %w", err)
+	}
+	message, err := utils.ExecGit("log", "-1", "--pretty=%B", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit message: %w", err)
+	}
+
+	changes := &CommitChanges{SHA: sha, Message: message, Diff: diff}
+	for _, fd := range utils.ParseUnifiedDiff(diff) {
+		path := fd.Path()
+		changes.Files = append(changes.Files, path)
+
+		if !strings.HasSuffix(path, ".go") || fd.IsDeleted() {
+			continue
+		}
+		content, err := utils.ExecGit("show", sha+":"+path)
+		if err != nil {
+			continue
+		}
+		funcs, err := treesitter.ParseGoSource(path, []byte(content))
+		if err != nil {
+			continue
+		}
+		for _, fn := range funcs {
+			if hunksTouchFunction(fd.Hunks, fn) && !utils.StringSliceContains(changes.Functions, fn.Name) {
+				changes.Functions = append(changes.Functions, fn.Name)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// hunksTouchFunction reports whether any hunk's new-side range overlaps the function.
+func hunksTouchFunction(hunks []utils.Hunk, fn treesitter.FunctionInfo) bool {
+	for _, h := range hunks {
+		start := h.NewStart
+		end := h.NewStart + h.NewLines - 1
+		if h.NewLines == 0 {
+			// Pure deletion: the hunk sits just after line NewStart.
+			end = start
+		}
+		if start <= fn.EndLine && end >= fn.StartLine {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileTasks matches open tasks against a commit. Tasks whose referenced files and
+// functions were all touched are closed; partially touched tasks become in-progress.
+// When judge is non-nil the LLM decides for every candidate task, overriding the heuristic.
+func ReconcileTasks(tasks []contextpkg.Task, changes *CommitChanges, judge ChatClient) (open []contextpkg.Task, result *CommitReconciliation) {
+	result = &CommitReconciliation{SHA: changes.SHA}
+
+	for _, task := range tasks {
+		if utils.StringSliceContains(task.Commits, changes.SHA) {
+			open = append(open, task)
+			continue
+		}
+
+		status, candidate := matchTaskToCommit(task, changes)
+		if judge != nil && candidate {
+			if verdict, err := judgeTask(judge, task, changes); err == nil {
+				status = verdict
+			}
+		}
+
+		switch status {
+		case contextpkg.TaskStatusDone:
+			task.Status = contextpkg.TaskStatusDone
+			task.Commits = append(task.Commits, changes.SHA)
+			result.Closed = append(result.Closed, task)
+		case contextpkg.TaskStatusInProgress:
+			task.Status = contextpkg.TaskStatusInProgress
+			task.Commits = append(task.Commits, changes.SHA)
+			result.Progressed = append(result.Progressed, task)
+			open = append(open, task)
+		default:
+			open = append(open, task)
+		}
+	}
+	return open, result
+}
+
+// matchTaskToCommit applies the file/function overlap heuristic. The second return value
+// reports whether the task is worth asking the LLM judge about.
+func matchTaskToCommit(task contextpkg.Task, changes *CommitChanges) (string, bool) {
+	refs := len(task.Files) + len(task.Functions)
+	if refs == 0 {
+		// Nothing to match on; only the judge can tell.
+		return "", true
+	}
+
+	hits := 0
+	for _, f := range task.Files {
+		if utils.StringSliceContains(changes.Files, f) {
+			hits++
+		}
+	}
+	for _, fn := range task.Functions {
+		if utils.StringSliceContains(changes.Functions, fn) {
+			hits++
+		}
+	}
+
+	switch {
+	case hits == 0:
+		return "", false
+	case hits == refs:
+		return contextpkg.TaskStatusDone, true
+	default:
+		return contextpkg.TaskStatusInProgress, true
+	}
+}
+
+// judgeTask asks the LLM whether the commit satisfies the task.
+func judgeTask(judge ChatClient, task contextpkg.Task, changes *CommitChanges) (string, error) {
+	prompt := fmt.Sprintf(`
+Decide whether the following commit completes a development task.
+
+**Task:**
+%s
+Files: %v
+Functions: %v
+
+**Commit Message:**
+%s
+
+**Code Changes:**
+%s
+
+!TASK: Respond with JSON only: {"verdict": "done" | "in_progress" | "unrelated"}
+`, task.Description, task.Files, task.Functions, changes.Message,
+		contextpkg.TruncateDiff(changes.Diff, maxJudgeDiffLines))
+
+	response, err := judge.GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a meticulous reviewer tracking task completion."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return "", err
+	}
+	var verdict struct {
+		Verdict string `json:"verdict"`
+	}
+	if err := json.Unmarshal([]byte(raw), &verdict); err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(verdict.Verdict)) {
+	case "done":
+		return contextpkg.TaskStatusDone, nil
+	case "in_progress", "in-progress":
+		return contextpkg.TaskStatusInProgress, nil
+	case "unrelated":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown verdict %q", verdict.Verdict)
+	}
+}
+
+// ReconcileStoredTasks applies a commit to the persisted DCE task list.
+// It returns (nil, nil) when PRBuddy-Go is not initialized or no DCE session has saved tasks.
+func ReconcileStoredTasks(sha string, useJudge bool) (*CommitReconciliation, error) {
+	state, err := LoadTaskState()
+	if err == ErrNotInitialized || (err == nil && state == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := CollectCommitChanges(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	var judge ChatClient
+	if useJudge {
+		judge = getPlannerClient()
+	}
+
+	open, result := ReconcileTasks(state.Tasks, changes, judge)
+	state.Tasks = open
+	state.Completed = append(state.Completed, result.Closed...)
+
+	if err := SaveTaskState(state); err != nil {
+		return nil, fmt.Errorf("failed to save task state: %w", err)
+	}
+	return result, nil
+}
+
+// pluralize appends "s" to word unless n is exactly one.
+func pluralize(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
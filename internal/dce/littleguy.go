@@ -10,6 +10,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
@@ -22,8 +23,14 @@ type LittleGuy struct {
 	codeSnapshots  map[string]string // filePath -> file content
 	pollInterval   time.Duration     // How often to check for diffs
 	monitorStarted bool              // Tracks background monitoring status
-	pendingQueries []string
+	pendingQueries []string          // IDs of suggestions already surfaced this session
 	queryCallback  func(string)
+	lastHead       string               // Last HEAD commit seen by the monitor
+	suggestions    []Suggestion         // Active (non-snoozed) suggestions from the last check
+	snoozedUntil   map[string]time.Time // Suggestion ID -> time it may resurface
+
+	unsaved *TaskState // Task list recorded by persistLocked, waiting for flushTasks
+	saveMu  sync.Mutex // Serializes task store writes so the newest state lands last
 }
 
 // NewLittleGuy initializes a new LittleGuy instance.
@@ -34,6 +41,14 @@ func NewLittleGuy(conversationID string, initialTasks []contextpkg.Task) *Little
 		completed:      []contextpkg.Task{},
 		codeSnapshots:  make(map[string]string),
 		pollInterval:   10 * time.Second,
+		snoozedUntil:   make(map[string]time.Time),
+	}
+	if head, err := utils.GetLatestCommit(); err == nil {
+		lg.lastHead = head
+	}
+	if len(initialTasks) > 0 {
+		lg.persistLocked()
+		lg.flushTasks()
 	}
 
 	// Add to context manager
@@ -88,6 +103,8 @@ func (lg *LittleGuy) StartMonitoring() {
 			}
 
 			time.Sleep(lg.pollInterval)
+			lg.checkForNewCommits()
+
 			diffOutput, err := utils.ExecGit("diff", "--unified=0")
 			if err != nil {
 				color.Red("[LittleGuy] Failed to run git diff: %v\n", err)
@@ -95,6 +112,7 @@ func (lg *LittleGuy) StartMonitoring() {
 			}
 			if diffOutput != "" {
 				lg.UpdateFromDiff(diffOutput)
+				lg.CheckForQueries()
 			}
 		}
 	}()
@@ -102,6 +120,7 @@ func (lg *LittleGuy) StartMonitoring() {
 
 // MonitorInput analyzes user input for function names or file references and updates tasks.
 func (lg *LittleGuy) MonitorInput(input string) {
+	defer lg.flushTasks()
 	lg.mutex.Lock()
 	defer lg.mutex.Unlock()
 
@@ -135,12 +154,14 @@ func (lg *LittleGuy) MonitorInput(input string) {
 			}
 		}
 	}
-	messages := lg.BuildEphemeralContext("")
+	lg.persistLocked()
+	messages := lg.buildEphemeralContextLocked("")
 	lg.logLLMContext(messages)
 }
 
 // UpdateFromDiff parses Git diff output and updates tasks accordingly.
 func (lg *LittleGuy) UpdateFromDiff(diff string) {
+	defer lg.flushTasks()
 	lg.mutex.Lock()
 	defer lg.mutex.Unlock()
 
@@ -159,11 +180,69 @@ func (lg *LittleGuy) UpdateFromDiff(diff string) {
 		}
 	}
 
+	lg.persistLocked()
+
 	// Log the updated context for debugging
-	messages := lg.BuildEphemeralContext("")
+	messages := lg.buildEphemeralContextLocked("")
 	lg.logLLMContext(messages)
 }
 
+// checkForNewCommits reconciles the task list against any commits made since the last poll.
+func (lg *LittleGuy) checkForNewCommits() {
+	head, err := utils.GetLatestCommit()
+	if err != nil {
+		return
+	}
+
+	lg.mutex.RLock()
+	lastHead := lg.lastHead
+	lg.mutex.RUnlock()
+
+	if head == lastHead {
+		return
+	}
+
+	var shas []string
+	if lastHead == "" {
+		shas = []string{head}
+	} else if out, err := utils.ExecGit("rev-list", "--reverse", lastHead+".."+head); err == nil && out != "" {
+		shas = utils.SplitLines(out)
+	}
+
+	for _, sha := range shas {
+		result, err := lg.ReconcileCommit(sha)
+		if err != nil {
+			color.Red("[LittleGuy] Failed to reconcile commit %s: %v\n", sha, err)
+			continue
+		}
+		if len(result.Closed) > 0 || len(result.Progressed) > 0 {
+			color.Green("[LittleGuy] %s\n", result.Summary())
+		}
+	}
+
+	lg.mutex.Lock()
+	lg.lastHead = head
+	lg.mutex.Unlock()
+}
+
+// ReconcileCommit marks open tasks done or in-progress based on what the commit touched.
+func (lg *LittleGuy) ReconcileCommit(sha string) (*CommitReconciliation, error) {
+	changes, err := CollectCommitChanges(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	defer lg.flushTasks()
+	lg.mutex.Lock()
+	defer lg.mutex.Unlock()
+
+	open, result := ReconcileTasks(lg.tasks, changes, nil)
+	lg.tasks = open
+	lg.completed = append(lg.completed, result.Closed...)
+	lg.persistLocked()
+	return result, nil
+}
+
 // ParseGitDiff extracts meaningful changes from git diff output
 func ParseGitDiff(diff string) []GitChange {
 	var changes []GitChange
@@ -240,14 +319,13 @@ func (lg *LittleGuy) handleModifiedFile(change GitChange) {
 				Notes:       []string{"Write unit tests", "Add documentation"},
 			})
 		} else if change.Type == "removed" {
-			// Function was removed - mark related tasks as completed
-			for i, task := range lg.tasks {
-				for _, fn := range task.Functions {
-					if fn == change.FuncName {
-						lg.completed = append(lg.completed, task)
-						lg.tasks = append(lg.tasks[:i], lg.tasks[i+1:]...)
-						break
-					}
+			// A removed declaration line usually means the function is being edited or
+			// renamed, not that the work is finished. Completion is driven by commits.
+			note := fmt.Sprintf("Function %s modified in working tree", change.FuncName)
+			for i := range lg.tasks {
+				if utils.StringSliceContains(lg.tasks[i].Functions, change.FuncName) &&
+					!utils.StringSliceContains(lg.tasks[i].Notes, note) {
+					lg.tasks[i].Notes = append(lg.tasks[i].Notes, note)
 				}
 			}
 		}
@@ -287,7 +365,11 @@ func (lg *LittleGuy) markTaskAsCompleted(funcName string) {
 func (lg *LittleGuy) BuildEphemeralContext(userQuery string) []contextpkg.Message {
 	lg.mutex.RLock()
 	defer lg.mutex.RUnlock()
+	return lg.buildEphemeralContextLocked(userQuery)
+}
 
+// buildEphemeralContextLocked is BuildEphemeralContext for callers already holding the mutex.
+func (lg *LittleGuy) buildEphemeralContextLocked(userQuery string) []contextpkg.Message {
 	var messages []contextpkg.Message
 	messages = append(messages, contextpkg.Message{
 		Role:    "system",
@@ -342,6 +424,7 @@ func (lg *LittleGuy) AddCodeSnippet(filePath, content string) {
 
 // UpdateTaskList appends new tasks if they're not already represented.
 func (lg *LittleGuy) UpdateTaskList(newTasks []contextpkg.Task) {
+	defer lg.flushTasks()
 	lg.mutex.Lock()
 	defer lg.mutex.Unlock()
 	for _, t := range newTasks {
@@ -356,10 +439,56 @@ func (lg *LittleGuy) UpdateTaskList(newTasks []contextpkg.Task) {
 			lg.tasks = append(lg.tasks, t)
 		}
 	}
+	lg.persistLocked()
 }
 
-// logLLMContext writes the raw LLM input to a log file using utils.LogLittleGuyContext.
+// persistLocked records a copy of the task list for flushTasks to save, so out-of-process
+// hooks can reconcile it. Callers must hold the mutex and call flushTasks after
+// releasing it.
+func (lg *LittleGuy) persistLocked() {
+	lg.unsaved = &TaskState{
+		ConversationID: lg.conversationID,
+		Tasks:          cloneTasks(lg.tasks),
+		Completed:      cloneTasks(lg.completed),
+	}
+}
+
+// flushTasks writes the task list recorded by persistLocked, if any. Callers must not
+// hold the mutex. Uninitialized repositories are silently skipped.
+func (lg *LittleGuy) flushTasks() {
+	lg.saveMu.Lock()
+	defer lg.saveMu.Unlock()
+
+	lg.mutex.Lock()
+	state := lg.unsaved
+	lg.unsaved = nil
+	lg.mutex.Unlock()
+	if state == nil {
+		return
+	}
+	if err := SaveTaskState(state); err != nil && err != ErrNotInitialized {
+		color.Red("[LittleGuy] Failed to persist tasks: %v\n", err)
+	}
+}
+
+// cloneTasks copies tasks deeply enough that later edits cannot race with a save.
+func cloneTasks(tasks []contextpkg.Task) []contextpkg.Task {
+	out := make([]contextpkg.Task, len(tasks))
+	for i, t := range tasks {
+		t.Files = append([]string(nil), t.Files...)
+		t.Functions = append([]string(nil), t.Functions...)
+		t.Dependencies = append([]string(nil), t.Dependencies...)
+		t.Notes = append([]string(nil), t.Notes...)
+		t.Commits = append([]string(nil), t.Commits...)
+		out[i] = t
+	}
+	return out
+}
+
+// logLLMContext writes the LLM input, redacted as it would be sent, to a log file using
+// utils.LogLittleGuyContext.
 func (lg *LittleGuy) logLLMContext(messages []contextpkg.Message) {
+	messages, _ = redact.Default().RedactMessages(messages)
 	var rawContext strings.Builder
 	for _, msg := range messages {
 		rawContext.WriteString(fmt.Sprintf("%s: %s\n\n", msg.Role, msg.Content))
@@ -393,39 +522,88 @@ func (lg *LittleGuy) hasTaskForFunction(fn string) bool {
 	return false
 }
 
-// Method to set query callback
+// SetQueryCallback registers a function that receives each newly surfaced suggestion message.
 func (lg *LittleGuy) SetQueryCallback(callback func(string)) {
+	lg.mutex.Lock()
+	defer lg.mutex.Unlock()
 	lg.queryCallback = callback
 }
 
-// Method to generate and send queries based on task changes
-func (lg *LittleGuy) CheckForQueries() {
-	lg.mutex.Lock()
-	defer lg.mutex.Unlock()
+// CheckForQueries analyzes new or changed functions in the working tree and surfaces
+// suggestions (missing tests, missing doc comments, ignored errors). Each suggestion is
+// surfaced at most once per session unless it was snoozed and the snooze has expired.
+// Newly surfaced suggestions are passed to the query callback and returned.
+func (lg *LittleGuy) CheckForQueries() []Suggestion {
+	suggestions, err := AnalyzeWorkingTree()
+	if err != nil {
+		color.Red("[LittleGuy] Suggestion analysis failed: %v\n", err)
+		return nil
+	}
 
-	// 1. Check for new functions without tests
-	for _, task := range lg.tasks {
-		for _, fn := range task.Functions {
-			if !lg.hasTestForFunction(fn) && !lg.isQueryPending(fn) {
-				query := fmt.Sprintf("You added the function '%s'. Would you like me to generate test cases?", fn)
-				lg.pendingQueries = append(lg.pendingQueries, fn)
-				if lg.queryCallback != nil {
-					lg.queryCallback(query)
-				}
+	lg.mutex.Lock()
+	now := time.Now()
+	var active, fresh []Suggestion
+	for _, s := range suggestions {
+		if until, ok := lg.snoozedUntil[s.ID]; ok {
+			if now.Before(until) {
+				continue
 			}
+			delete(lg.snoozedUntil, s.ID)
+		}
+		active = append(active, s)
+		if !lg.isQueryPending(s.ID) {
+			lg.pendingQueries = append(lg.pendingQueries, s.ID)
+			fresh = append(fresh, s)
 		}
 	}
+	lg.suggestions = active
+	callback := lg.queryCallback
+	lg.mutex.Unlock()
+
+	if callback != nil {
+		for _, s := range fresh {
+			callback(s.Message)
+		}
+	}
+	return fresh
 }
 
-// Helper to check if a function has tests (simplified)
-func (lg *LittleGuy) hasTestForFunction(funcName string) bool {
-	for _, task := range lg.tasks {
-		if strings.Contains(task.Description, "test") &&
-			utils.StringSliceContains(task.Functions, funcName) {
-			return true
+// Suggestions returns the active suggestions from the most recent check.
+func (lg *LittleGuy) Suggestions() []Suggestion {
+	lg.mutex.RLock()
+	defer lg.mutex.RUnlock()
+	return append([]Suggestion(nil), lg.suggestions...)
+}
+
+// SnoozeSuggestion hides a suggestion for the given duration. Once the snooze expires
+// the suggestion is surfaced again on the next check.
+func (lg *LittleGuy) SnoozeSuggestion(id string, d time.Duration) bool {
+	lg.mutex.Lock()
+	defer lg.mutex.Unlock()
+
+	found := false
+	remaining := lg.suggestions[:0]
+	for _, s := range lg.suggestions {
+		if s.ID == id {
+			found = true
+			continue
 		}
+		remaining = append(remaining, s)
 	}
-	return false
+	if !found {
+		return false
+	}
+	lg.suggestions = remaining
+	lg.snoozedUntil[id] = time.Now().Add(d)
+
+	pending := lg.pendingQueries[:0]
+	for _, p := range lg.pendingQueries {
+		if p != id {
+			pending = append(pending, p)
+		}
+	}
+	lg.pendingQueries = pending
+	return true
 }
 
 // Helper to check if query is already pending
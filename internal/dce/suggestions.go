@@ -0,0 +1,324 @@
+// internal/dce/suggestions.go
+
+package dce
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Suggestion kinds surfaced by the proactive suggestion engine.
+const (
+	SuggestionMissingTest  = "missing_test"
+	SuggestionMissingDoc   = "missing_doc"
+	SuggestionIgnoredError = "ignored_error"
+)
+
+// maxTestCallDepth bounds how far from a TestXxx function the call graph is followed
+// when deciding whether a function is exercised by tests.
+const maxTestCallDepth = 2
+
+// Suggestion is a proactive hint about a new or changed function.
+type Suggestion struct {
+	ID       string `json:"id"` // Stable key used for per-session dedupe and snoozing
+	Kind     string `json:"kind"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Message  string `json:"message"`
+}
+
+// knownErrorReturners are standard library calls that return an error as their last value.
+// They complement the project map, which only knows about functions defined in the repo.
+var knownErrorReturners = map[string]bool{
+	"WriteFile": true, "MkdirAll": true, "Remove": true, "RemoveAll": true,
+	"Rename": true, "Chmod": true, "Chdir": true, "Setenv": true,
+	"Unmarshal": true, "Flock": true, "Sync": true,
+}
+
+var (
+	// blankAssignPattern matches `_ = f(...)` and `x, _ := f(...)`, capturing the callee name.
+	blankAssignPattern = regexp.MustCompile(`(?:^|,)\s*_\s*:?=\s*(?:[\w]+\.)*(\w+)\(`)
+	// bareCallPattern matches a call used as a statement, capturing the callee name.
+	bareCallPattern = regexp.MustCompile(`^\s*(?:[\w]+\.)*(\w+)\(.*\)\s*$`)
+)
+
+// analysisCache holds the last AnalyzeWorkingTree result, keyed by the working tree
+// state it was computed from, so an unchanged tree is not parsed again on every poll.
+var analysisCache struct {
+	sync.Mutex
+	key         string
+	suggestions []Suggestion
+}
+
+// AnalyzeWorkingTree inspects Go functions added or changed since HEAD (including
+// untracked files) and returns suggestions about missing tests, missing doc
+// comments on exported functions, and ignored error returns.
+func AnalyzeWorkingTree() ([]Suggestion, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository path: %w", err)
+	}
+
+	diff, err := utils.ExecGit("diff", "HEAD", "--unified=0", "--no-color")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working tree diff: %w", err)
+	}
+	trackedOut, err := utils.ExecGit("ls-files", "--full-name", ":/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+	untrackedOut, err := utils.ExecGit("ls-files", "--others", "--exclude-standard", "--full-name", ":/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	// Changed hunks per file; untracked files have no hunks and count as entirely new.
+	changed := make(map[string][]utils.Hunk)
+	for _, fd := range utils.ParseUnifiedDiff(diff) {
+		if !fd.IsDeleted() && strings.HasSuffix(fd.Path(), ".go") {
+			changed[fd.Path()] = fd.Hunks
+		}
+	}
+	untracked := utils.SplitLines(untrackedOut)
+	for _, f := range untracked {
+		if strings.HasSuffix(f, ".go") {
+			changed[f] = nil
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	key := workingTreeKey(repoPath, diff, untracked)
+	analysisCache.Lock()
+	defer analysisCache.Unlock()
+	if key == analysisCache.key {
+		return append([]Suggestion(nil), analysisCache.suggestions...), nil
+	}
+
+	allFiles := append(utils.SplitLines(trackedOut), untracked...)
+	functions, err := treesitter.ParseGoFiles(repoPath, allFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := analyzeFunctions(repoPath, functions, changed)
+	analysisCache.key, analysisCache.suggestions = key, suggestions
+	return append([]Suggestion(nil), suggestions...), nil
+}
+
+// workingTreeKey identifies the Go sources AnalyzeWorkingTree sees: HEAD, the diff
+// against it and the size and modification time of every untracked Go file.
+func workingTreeKey(repoPath, diff string, untracked []string) string {
+	h := sha256.New()
+	head, _ := utils.GetLatestCommit()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", repoPath, head, diff)
+	for _, f := range untracked {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(repoPath, f)); err == nil {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\x00", f, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// analyzeFunctions produces suggestions for the functions that overlap the changed hunks.
+// A nil hunk slice marks a brand-new file whose functions are all considered changed.
+func analyzeFunctions(repoPath string, functions []treesitter.FunctionInfo, changed map[string][]utils.Hunk) []Suggestion {
+	tested := testedFunctions(functions)
+	errorReturners := errorReturningFunctions(functions)
+
+	var suggestions []Suggestion
+	fileLines := make(map[string][]string)
+
+	for _, fn := range functions {
+		hunks, ok := changed[fn.File]
+		if !ok || strings.HasSuffix(fn.File, "_test.go") {
+			continue
+		}
+		if hunks != nil && !hunksTouchFunction(hunks, fn) {
+			continue
+		}
+
+		lines, ok := fileLines[fn.File]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(repoPath, fn.File))
+			if err != nil {
+				continue
+			}
+			lines = strings.Split(string(data), "\n")
+			fileLines[fn.File] = lines
+		}
+
+		display := functionDisplayName(fn)
+
+		if fn.Name != "main" && fn.Name != "init" && !tested[fn.Name] {
+			suggestions = append(suggestions, Suggestion{
+				ID:       fmt.Sprintf("%s:%s:%s", SuggestionMissingTest, fn.File, display),
+				Kind:     SuggestionMissingTest,
+				File:     fn.File,
+				Line:     fn.StartLine,
+				Function: display,
+				Message:  fmt.Sprintf("%s has no tests, generate some?", display),
+			})
+		}
+
+		if token.IsExported(fn.Name) && !hasDocComment(lines, fn.StartLine) {
+			suggestions = append(suggestions, Suggestion{
+				ID:       fmt.Sprintf("%s:%s:%s", SuggestionMissingDoc, fn.File, display),
+				Kind:     SuggestionMissingDoc,
+				File:     fn.File,
+				Line:     fn.StartLine,
+				Function: display,
+				Message:  fmt.Sprintf("exported func %s lacks a doc comment", display),
+			})
+		}
+
+		for _, lineNo := range changedLinesInFunction(hunks, fn) {
+			if lineNo < 1 || lineNo > len(lines) {
+				continue
+			}
+			code := strings.TrimSpace(lines[lineNo-1])
+			if callee := ignoredErrorCallee(code, errorReturners); callee != "" {
+				suggestions = append(suggestions, Suggestion{
+					ID:       fmt.Sprintf("%s:%s:%s:%s", SuggestionIgnoredError, fn.File, display, code),
+					Kind:     SuggestionIgnoredError,
+					File:     fn.File,
+					Line:     lineNo,
+					Function: display,
+					Message:  fmt.Sprintf("error return of %s ignored in %s (%s:%d)", callee, display, fn.File, lineNo),
+				})
+			}
+		}
+	}
+	return suggestions
+}
+
+// testedFunctions returns the names reachable from TestXxx functions in _test.go files
+// within maxTestCallDepth calls.
+func testedFunctions(functions []treesitter.FunctionInfo) map[string]bool {
+	callees := make(map[string][]string)
+	var frontier []string
+	for _, fn := range functions {
+		callees[fn.Name] = append(callees[fn.Name], allInvocations(fn)...)
+		if strings.HasSuffix(fn.File, "_test.go") && strings.HasPrefix(fn.Name, "Test") {
+			frontier = append(frontier, fn.Name)
+		}
+	}
+
+	tested := make(map[string]bool)
+	visited := make(map[string]bool)
+	for depth := 0; depth < maxTestCallDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, name := range frontier {
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			for _, callee := range callees[name] {
+				tested[callee] = true
+				next = append(next, callee)
+			}
+		}
+		frontier = next
+	}
+	return tested
+}
+
+// errorReturningFunctions returns the names of project functions whose last result is an error.
+func errorReturningFunctions(functions []treesitter.FunctionInfo) map[string]bool {
+	names := make(map[string]bool, len(knownErrorReturners))
+	for name := range knownErrorReturners {
+		names[name] = true
+	}
+	for _, fn := range functions {
+		if n := len(fn.Returns); n > 0 && fn.Returns[n-1] == "error" {
+			names[fn.Name] = true
+		}
+	}
+	return names
+}
+
+// ignoredErrorCallee returns the callee whose error result is discarded on this line, if any.
+func ignoredErrorCallee(code string, errorReturners map[string]bool) string {
+	if strings.HasPrefix(code, "//") {
+		return ""
+	}
+	if m := blankAssignPattern.FindStringSubmatch(code); m != nil && errorReturners[m[1]] {
+		// Only the last assigned value carries the error, so `_, err := f()` is fine.
+		lhs := code[:strings.Index(code, "=")]
+		lhs = strings.TrimSuffix(strings.TrimSpace(lhs), ":")
+		parts := strings.Split(lhs, ",")
+		if strings.TrimSpace(parts[len(parts)-1]) == "_" {
+			return m[1]
+		}
+		return ""
+	}
+	for _, keyword := range []string{"return ", "go ", "defer ", "if ", "for ", "switch ", "case "} {
+		if strings.HasPrefix(code, keyword) {
+			return ""
+		}
+	}
+	if m := bareCallPattern.FindStringSubmatch(code); m != nil && errorReturners[m[1]] {
+		return m[1]
+	}
+	return ""
+}
+
+// changedLinesInFunction lists changed line numbers inside the function. For brand-new
+// files (nil hunks) every line of the function counts as changed.
+func changedLinesInFunction(hunks []utils.Hunk, fn treesitter.FunctionInfo) []int {
+	var lines []int
+	if hunks == nil {
+		for l := fn.StartLine; l <= fn.EndLine; l++ {
+			lines = append(lines, l)
+		}
+		return lines
+	}
+	for _, h := range hunks {
+		for l := h.NewStart; l < h.NewStart+h.NewLines; l++ {
+			if l >= fn.StartLine && l <= fn.EndLine {
+				lines = append(lines, l)
+			}
+		}
+	}
+	return lines
+}
+
+// allInvocations flattens every call recorded for a function.
+func allInvocations(fn treesitter.FunctionInfo) []string {
+	deps := fn.Dependencies
+	calls := make([]string, 0, len(deps.Handlers)+len(deps.Utilities)+len(deps.Invocations))
+	calls = append(calls, deps.Handlers...)
+	calls = append(calls, deps.Utilities...)
+	return append(calls, deps.Invocations...)
+}
+
+// hasDocComment reports whether the line above a declaration is a comment.
+func hasDocComment(lines []string, startLine int) bool {
+	if startLine < 2 || startLine-2 >= len(lines) {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(lines[startLine-2]), "//")
+}
+
+// functionDisplayName renders methods as Receiver.Name and functions as Name.
+func functionDisplayName(fn treesitter.FunctionInfo) string {
+	if fn.Receiver != "" {
+		return fn.Receiver + "." + fn.Name
+	}
+	return fn.Name
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
-// BuildTaskList creates tasks based on user input, file matching, and function extraction.
+// BuildTaskList creates tasks based on user input. When PlannerEnv is set and a planner
+// client is configured, the LLM decomposes the input into subtasks grounded in the project
+// map; otherwise, or if planning fails, tasks are built from keyword file matching and
+// function extraction.
 func BuildTaskList(input string) ([]contextpkg.Task, []string, error) {
 	var logs []string
 	logs = append(logs, fmt.Sprintf("Building task list from input: %q", input))
@@ -25,21 +28,40 @@ func BuildTaskList(input string) ([]contextpkg.Task, []string, error) {
 	trackedFiles := utils.SplitLines(out)
 	logs = append(logs, fmt.Sprintf("Found %d tracked files", len(trackedFiles)))
 
-	// 2. Match files based on keywords.
+	// 2. Prefer LLM planning when it is turned on.
+	if client := getPlannerClient(); client != nil && planningEnabled() {
+		tasks, planLogs, err := planTasksWithLLM(client, input, trackedFiles)
+		logs = append(logs, planLogs...)
+		if err == nil {
+			return tasks, logs, nil
+		}
+		logs = append(logs, fmt.Sprintf("LLM planning failed (%v) - falling back to keyword heuristic", err))
+	}
+
+	tasks, heuristicLogs := buildHeuristicTaskList(input, trackedFiles)
+	return tasks, append(logs, heuristicLogs...), nil
+}
+
+// buildHeuristicTaskList builds a single task from keyword file matches and extracted functions.
+// It is the offline fallback for BuildTaskList.
+func buildHeuristicTaskList(input string, trackedFiles []string) ([]contextpkg.Task, []string) {
+	var logs []string
+
+	// 1. Match files based on keywords.
 	matchedFiles := matchFilesByKeywords(trackedFiles, input)
 	logs = append(logs, fmt.Sprintf("Matched %d files: %v", len(matchedFiles), matchedFiles))
 
-	// 3. If no files matched, create a catch-all task.
+	// 2. If no files matched, create a catch-all task.
 	if len(matchedFiles) == 0 {
 		task := contextpkg.Task{
 			Description: input,
 			Notes:       []string{"No direct file matches found. Add manually."},
 		}
 		logs = append(logs, "No file matches found - created catch-all task")
-		return []contextpkg.Task{task}, logs, nil
+		return []contextpkg.Task{task}, logs
 	}
 
-	// 4. Extract functions from each matched file.
+	// 3. Extract functions from each matched file.
 	var allFunctions []string
 	fileFuncPattern := `(?m)^\s*(def|func|function|public|private|static|void)\s+(\w+)\s*\(`
 	for _, f := range matchedFiles {
@@ -52,7 +74,7 @@ func BuildTaskList(input string) ([]contextpkg.Task, []string, error) {
 		}
 	}
 
-	// 5. Create a consolidated task.
+	// 4. Create a consolidated task.
 	task := contextpkg.Task{
 		Description:  input,
 		Files:        matchedFiles,
@@ -62,7 +84,7 @@ func BuildTaskList(input string) ([]contextpkg.Task, []string, error) {
 	}
 	logs = append(logs, fmt.Sprintf("Created task with %d files and %d functions", len(matchedFiles), len(allFunctions)))
 
-	return []contextpkg.Task{task}, logs, nil
+	return []contextpkg.Task{task}, logs
 }
 
 // matchFilesByKeywords returns files from allFiles that contain any keyword from userInput.
@@ -136,6 +158,7 @@ func RefreshTaskListFromGitChanges(conversationID string) error {
 	}
 
 	// For each changed file, if it is not already represented in a task, add a new task.
+	defer littleguy.flushTasks()
 	littleguy.mutex.Lock()
 	defer littleguy.mutex.Unlock()
 
@@ -163,6 +186,7 @@ func RefreshTaskListFromGitChanges(conversationID string) error {
 			fmt.Printf("[TaskHelper] Added new task for file: %s\n", changedFile)
 		}
 	}
+	littleguy.persistLocked()
 	return nil
 }
 
@@ -0,0 +1,213 @@
+// internal/dce/task_planner.go
+
+package dce
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// ChatClient is the subset of llm.LLMClient used by the DCE for task planning.
+// It is declared here because the llm package imports dce, not the other way around.
+type ChatClient interface {
+	GetChatResponse(messages []contextpkg.Message) (string, error)
+}
+
+var (
+	plannerMutex  sync.RWMutex
+	plannerClient ChatClient
+)
+
+// PlannerEnv enables LLM task planning in BuildTaskList when set to a true value. It is
+// off by default because each plan costs a model call and a full project map build.
+const PlannerEnv = "PRBUDDY_DCE_PLANNER"
+
+// maxPlannerMapEntries caps how many files from the project map are listed in the planning prompt.
+const maxPlannerMapEntries = 300
+
+// SetPlannerClient sets the client used for LLM-driven task decomposition when
+// PlannerEnv is set. Passing nil disables planning, so BuildTaskList uses the keyword
+// heuristic only.
+func SetPlannerClient(client ChatClient) {
+	plannerMutex.Lock()
+	defer plannerMutex.Unlock()
+	plannerClient = client
+}
+
+// planningEnabled reports whether PlannerEnv turns LLM task planning on.
+func planningEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(PlannerEnv))
+	return enabled
+}
+
+func getPlannerClient() ChatClient {
+	plannerMutex.RLock()
+	defer plannerMutex.RUnlock()
+	return plannerClient
+}
+
+// plannedTask is the JSON shape the LLM is asked to produce for each subtask.
+type plannedTask struct {
+	Description string   `json:"description"`
+	Files       []string `json:"files"`
+	Functions   []string `json:"functions"`
+	Notes       []string `json:"notes"`
+}
+
+// planTasksWithLLM asks the planner client to decompose the input into concrete subtasks.
+// Every file must be tracked by git and every function must exist in the project map;
+// anything else is dropped and reported in the returned logs.
+func planTasksWithLLM(client ChatClient, input string, trackedFiles []string) ([]contextpkg.Task, []string, error) {
+	var logs []string
+
+	fileFuncs := buildFileFunctionIndex()
+	logs = append(logs, fmt.Sprintf("Project map lists functions for %d files", len(fileFuncs)))
+
+	messages := []contextpkg.Message{
+		{Role: "system", Content: "You are a senior engineer who breaks feature requests into concrete, file-level subtasks. Respond with JSON only."},
+		{Role: "user", Content: buildPlanningPrompt(input, trackedFiles, fileFuncs)},
+	}
+
+	response, err := client.GetChatResponse(messages)
+	if err != nil {
+		return nil, logs, fmt.Errorf("planner request failed: %w", err)
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return nil, logs, fmt.Errorf("planner returned no usable JSON: %w", err)
+	}
+
+	var plan struct {
+		Tasks []plannedTask `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return nil, logs, fmt.Errorf("failed to parse planner response: %w", err)
+	}
+
+	tracked := make(map[string]bool, len(trackedFiles))
+	for _, f := range trackedFiles {
+		tracked[f] = true
+	}
+	knownFuncs := make(map[string]bool)
+	for _, funcs := range fileFuncs {
+		for _, fn := range funcs {
+			knownFuncs[fn] = true
+		}
+	}
+
+	var tasks []contextpkg.Task
+	for _, pt := range plan.Tasks {
+		description := strings.TrimSpace(pt.Description)
+		if description == "" {
+			continue
+		}
+
+		var files []string
+		for _, f := range pt.Files {
+			f = strings.TrimPrefix(strings.TrimSpace(f), "./")
+			if !tracked[f] {
+				logs = append(logs, fmt.Sprintf("Dropped unknown file %q from subtask %q", f, description))
+				continue
+			}
+			if !utils.StringSliceContains(files, f) {
+				files = append(files, f)
+			}
+		}
+
+		var functions []string
+		for _, fn := range pt.Functions {
+			fn = strings.TrimSpace(fn)
+			if !knownFuncs[fn] {
+				logs = append(logs, fmt.Sprintf("Dropped unknown function %q from subtask %q", fn, description))
+				continue
+			}
+			if !utils.StringSliceContains(functions, fn) {
+				functions = append(functions, fn)
+			}
+		}
+
+		notes := append([]string{}, pt.Notes...)
+		notes = append(notes, "Planned by LLM from: "+input)
+
+		tasks = append(tasks, contextpkg.Task{
+			Description: description,
+			Files:       files,
+			Functions:   functions,
+			Notes:       notes,
+		})
+	}
+
+	if len(tasks) == 0 {
+		return nil, logs, fmt.Errorf("planner produced no valid subtasks")
+	}
+
+	logs = append(logs, fmt.Sprintf("LLM planner produced %d subtasks", len(tasks)))
+	return tasks, logs, nil
+}
+
+// buildFileFunctionIndex maps repo-relative file paths to the functions the project map found in them.
+// Failures are non-fatal: the planner simply receives less context.
+func buildFileFunctionIndex() map[string][]string {
+	index := make(map[string][]string)
+
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return index
+	}
+
+	projectMap, err := treesitter.NewGoParser().BuildProjectMap(repoPath)
+	if err != nil || projectMap == nil {
+		return index
+	}
+
+	for _, fn := range projectMap.Functions {
+		rel := treesitter.RepoRelativePath(fn.File)
+		index[rel] = append(index[rel], fn.Name)
+	}
+	return index
+}
+
+// buildPlanningPrompt renders the user request together with the tracked files and known functions.
+func buildPlanningPrompt(input string, trackedFiles []string, fileFuncs map[string][]string) string {
+	var sb strings.Builder
+
+	sb.WriteString("Break the following request into 1-6 concrete subtasks.\n\n")
+	sb.WriteString(fmt.Sprintf("**Request:**\n%s\n\n", input))
+	sb.WriteString("**Project Files (path: functions):**\n")
+
+	files := append([]string{}, trackedFiles...)
+	sort.Strings(files)
+	listed := 0
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		if listed >= maxPlannerMapEntries {
+			sb.WriteString(fmt.Sprintf("... %d more files omitted\n", len(files)-listed))
+			break
+		}
+		if funcs := fileFuncs[f]; len(funcs) > 0 {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", f, strings.Join(funcs, ", ")))
+		} else {
+			sb.WriteString(f + "\n")
+		}
+		listed++
+	}
+
+	sb.WriteString(`
+!TASK: Only reference files and functions that appear in the list above. Do not invent paths.
+Respond with a single JSON object of the form:
+{"tasks": [{"description": "...", "files": ["path/to/file.go"], "functions": ["FuncName"], "notes": ["..."]}]}
+`)
+	return sb.String()
+}
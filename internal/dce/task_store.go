@@ -0,0 +1,104 @@
+// internal/dce/task_store.go
+
+package dce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// ErrNotInitialized is returned when PRBuddy-Go has not been initialized in the repository,
+// so there is no .git/pr_buddy_db directory to persist DCE state into.
+var ErrNotInitialized = errors.New("PRBuddy-Go is not initialized in this repository")
+
+// TaskState is the persisted task list of the most recent DCE session.
+// It lets out-of-process hooks (e.g. post-commit) see and update the session's tasks.
+type TaskState struct {
+	ConversationID string            `json:"conversation_id"`
+	Tasks          []contextpkg.Task `json:"tasks"`
+	Completed      []contextpkg.Task `json:"completed"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+var (
+	storePathMu       sync.RWMutex
+	taskStoreOverride string
+)
+
+// SetTaskStorePath makes LoadTaskState and SaveTaskState use path instead of the
+// repository's .git/pr_buddy_db/dce/tasks.json. An empty path restores the default.
+func SetTaskStorePath(path string) {
+	storePathMu.Lock()
+	defer storePathMu.Unlock()
+	taskStoreOverride = path
+}
+
+// taskStorePath returns .git/pr_buddy_db/dce/tasks.json, or ErrNotInitialized.
+func taskStorePath() (string, error) {
+	storePathMu.RLock()
+	override := taskStoreOverride
+	storePathMu.RUnlock()
+	if override != "" {
+		return override, nil
+	}
+
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository path: %w", err)
+	}
+
+	dbPath := filepath.Join(repoPath, ".git", "pr_buddy_db")
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotInitialized
+		}
+		return "", fmt.Errorf("error checking pr_buddy_db: %w", err)
+	}
+
+	return filepath.Join(dbPath, "dce", "tasks.json"), nil
+}
+
+// LoadTaskState reads the persisted task state. It returns (nil, nil) if no state has been saved yet.
+func LoadTaskState() (*TaskState, error) {
+	path, err := taskStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := utils.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read task state: %w", err)
+	}
+
+	var state TaskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveTaskState atomically writes the task state to disk.
+func SaveTaskState(state *TaskState) error {
+	path, err := taskStorePath()
+	if err != nil {
+		return err
+	}
+
+	state.UpdatedAt = time.Now()
+	jsonStr, err := utils.MarshalJSON(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task state: %w", err)
+	}
+	return utils.WriteFile(path, []byte(jsonStr))
+}
@@ -0,0 +1,298 @@
+// internal/diagram/diagram.go
+
+package diagram
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+)
+
+// DefaultDepth is how many calls away from the scope a call graph reaches.
+const DefaultDepth = 2
+
+// Output formats.
+const (
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+	FormatHTML    = "html"
+)
+
+var (
+	quoteRe = regexp.MustCompile(`["\\]`)
+	idRe    = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// Node is a package or function in a diagram.
+type Node struct {
+	ID    string
+	Label string
+	Group string // Package directory the node is drawn in; empty for package graphs
+	Root  bool   // Inside the requested scope
+}
+
+// Edge is an import or a call.
+type Edge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Diagram is a graph ready to render.
+type Diagram struct {
+	Title string
+	Nodes []Node
+	Edges []Edge
+}
+
+// Scope limits a call graph to the functions of a package, or to functions matching a
+// name ("Name" or "Type.Method"), and what lies within Depth calls of them.
+type Scope struct {
+	Package  string
+	Function string
+	Depth    int
+}
+
+// Packages returns the package import graph. Edges carry the number of calls made
+// along the import, when there are any.
+func Packages(g *codegraph.Graph) *Diagram {
+	calls := make(map[[2]string]int)
+	for _, f := range g.Flows() {
+		calls[[2]string{f.From, f.To}] = f.Calls
+	}
+	d := &Diagram{Title: "Package dependencies"}
+	for _, pkg := range g.Packages {
+		d.Nodes = append(d.Nodes, Node{ID: packageID(pkg.Dir), Label: pkg.Dir})
+		for _, dep := range pkg.Imports {
+			e := Edge{From: packageID(pkg.Dir), To: packageID(dep)}
+			if n := calls[[2]string{pkg.Dir, dep}]; n > 0 {
+				e.Label = fmt.Sprintf("%d calls", n)
+			}
+			d.Edges = append(d.Edges, e)
+		}
+	}
+	return d
+}
+
+// Calls returns the call graph around a scope. For a package, its functions and their
+// callees up to Depth calls out; for a function, its callees and callers up to Depth
+// calls in either direction.
+func Calls(g *codegraph.Graph, scope Scope) (*Diagram, error) {
+	var roots []int
+	switch {
+	case scope.Function != "":
+		for i, fn := range g.Functions {
+			if fn.Name == scope.Function || codegraph.DisplayName(fn) == scope.Function {
+				roots = append(roots, i)
+			}
+		}
+		if len(roots) == 0 {
+			return nil, fmt.Errorf("function %q not found in the project map", scope.Function)
+		}
+	case scope.Package != "":
+		dir := filepath.ToSlash(filepath.Clean(scope.Package))
+		pkg, ok := g.Package(dir)
+		if !ok {
+			return nil, fmt.Errorf("package %q not found in the project map", scope.Package)
+		}
+		roots = pkg.Functions
+	default:
+		return nil, fmt.Errorf("a call graph needs a package or function scope")
+	}
+
+	depth := map[int]int{}
+	for _, i := range roots {
+		depth[i] = 0
+	}
+	walk(roots, depth, scope.Depth, g.Callees)
+	if scope.Function != "" {
+		up := map[int]int{}
+		for _, i := range roots {
+			up[i] = 0
+		}
+		walk(roots, up, scope.Depth, g.Callers)
+		for i, n := range up {
+			if _, ok := depth[i]; !ok {
+				depth[i] = n
+			}
+		}
+	}
+
+	included := make([]int, 0, len(depth))
+	for i := range depth {
+		included = append(included, i)
+	}
+	sort.Slice(included, func(a, b int) bool {
+		fa, fb := g.Functions[included[a]], g.Functions[included[b]]
+		if fa.File != fb.File {
+			return fa.File < fb.File
+		}
+		return fa.StartLine < fb.StartLine
+	})
+
+	title := "Calls in " + scope.Package
+	if scope.Function != "" {
+		title = "Calls around " + scope.Function
+	}
+	d := &Diagram{Title: title}
+	for _, i := range included {
+		fn := g.Functions[i]
+		d.Nodes = append(d.Nodes, Node{ID: functionID(i), Label: codegraph.DisplayName(fn), Group: g.PackageOf(i), Root: depth[i] == 0})
+	}
+	for _, i := range included {
+		for _, j := range g.Callees(i) {
+			if _, ok := depth[j]; ok {
+				d.Edges = append(d.Edges, Edge{From: functionID(i), To: functionID(j)})
+			}
+		}
+	}
+	return d, nil
+}
+
+// walk does a breadth-first search from roots along next, recording each function's
+// distance up to maxDepth.
+func walk(roots []int, depth map[int]int, maxDepth int, next func(int) []int) {
+	queue := append([]int(nil), roots...)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if depth[i] >= maxDepth {
+			continue
+		}
+		for _, j := range next(i) {
+			if _, seen := depth[j]; !seen {
+				depth[j] = depth[i] + 1
+				queue = append(queue, j)
+			}
+		}
+	}
+}
+
+// Mermaid renders the diagram as a Mermaid flowchart, with functions grouped into one
+// subgraph per package.
+func (d *Diagram) Mermaid() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: %s\n---\ngraph LR\n", d.Title)
+	for _, group := range d.groups() {
+		indent := "    "
+		if group.name != "" {
+			fmt.Fprintf(&b, "    subgraph %s[\"%s\"]\n", packageID(group.name), group.name)
+			indent = "        "
+		}
+		for _, n := range group.nodes {
+			fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, n.ID, mermaidText(n.Label))
+		}
+		if group.name != "" {
+			b.WriteString("    end\n")
+		}
+	}
+	for _, e := range d.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", e.From, mermaidText(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", e.From, e.To)
+		}
+	}
+	if roots := d.highlighted(); len(roots) > 0 {
+		b.WriteString("    classDef scope stroke-width:3px\n")
+		fmt.Fprintf(&b, "    class %s scope\n", strings.Join(roots, ","))
+	}
+	return b.String()
+}
+
+// DOT renders the diagram in Graphviz DOT, with functions grouped into one cluster per
+// package.
+func (d *Diagram) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(d.Title))
+	b.WriteString("    rankdir=LR;\n    node [shape=box, fontname=\"Helvetica\"];\n")
+	highlighted := make(map[string]bool)
+	for _, id := range d.highlighted() {
+		highlighted[id] = true
+	}
+	for _, group := range d.groups() {
+		indent := "    "
+		if group.name != "" {
+			fmt.Fprintf(&b, "    subgraph %s {\n        label=%s;\n", dotQuote("cluster_"+group.name), dotQuote(group.name))
+			indent = "        "
+		}
+		for _, n := range group.nodes {
+			style := ""
+			if highlighted[n.ID] {
+				style = ", penwidth=2"
+			}
+			fmt.Fprintf(&b, "%s%s [label=%s%s];\n", indent, n.ID, dotQuote(n.Label), style)
+		}
+		if group.name != "" {
+			b.WriteString("    }\n")
+		}
+	}
+	for _, e := range d.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "    %s -> %s [label=%s];\n", e.From, e.To, dotQuote(e.Label))
+		} else {
+			fmt.Fprintf(&b, "    %s -> %s;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// highlighted returns the IDs of the scope's own nodes, or nothing when every node is
+// in scope.
+func (d *Diagram) highlighted() []string {
+	var roots []string
+	for _, n := range d.Nodes {
+		if n.Root {
+			roots = append(roots, n.ID)
+		}
+	}
+	if len(roots) == len(d.Nodes) {
+		return nil
+	}
+	return roots
+}
+
+type group struct {
+	name  string
+	nodes []Node
+}
+
+// groups splits the nodes by package, keeping the order of first appearance.
+func (d *Diagram) groups() []group {
+	var groups []group
+	index := make(map[string]int)
+	for _, n := range d.Nodes {
+		k, ok := index[n.Group]
+		if !ok {
+			k = len(groups)
+			index[n.Group] = k
+			groups = append(groups, group{name: n.Group})
+		}
+		groups[k].nodes = append(groups[k].nodes, n)
+	}
+	return groups
+}
+
+func packageID(dir string) string {
+	if dir == "." {
+		return "pkg_root"
+	}
+	return "pkg_" + idRe.ReplaceAllString(dir, "_")
+}
+
+func functionID(i int) string {
+	return fmt.Sprintf("fn%d", i)
+}
+
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
+
+func dotQuote(text string) string {
+	return `"` + quoteRe.ReplaceAllString(text, `\$0`) + `"`
+}
@@ -0,0 +1,87 @@
+// internal/diagram/viewer.go
+
+package diagram
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+)
+
+//go:embed viewer.html
+var viewerTemplate string
+
+type viewerPackage struct {
+	Dir       string   `json:"dir"`
+	Name      string   `json:"name"`
+	Imports   []string `json:"imports"`
+	Functions []int    `json:"functions"`
+}
+
+type viewerFunction struct {
+	Name      string `json:"name"`
+	Display   string `json:"display"`
+	Package   string `json:"package"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature"`
+	Callers   []int  `json:"callers"`
+	Callees   []int  `json:"callees"`
+}
+
+type viewerData struct {
+	Title     string           `json:"title"`
+	Root      string           `json:"root"` // Absolute repository path, for editor links
+	Packages  []viewerPackage  `json:"packages"`
+	Functions []viewerFunction `json:"functions"`
+}
+
+// HTML renders a self-contained page for browsing the graph offline: functions and
+// packages can be searched, each function shows its callers and callees, and its
+// location opens in an editor. repoPath is used for the editor links.
+func HTML(g *codegraph.Graph, title, repoPath string) ([]byte, error) {
+	data := viewerData{Title: title, Root: filepath.ToSlash(repoPath)}
+	for _, pkg := range g.Packages {
+		data.Packages = append(data.Packages, viewerPackage{
+			Dir:       pkg.Dir,
+			Name:      pkg.Name,
+			Imports:   nonNil(pkg.Imports),
+			Functions: nonNil(pkg.Functions),
+		})
+	}
+	for i, fn := range g.Functions {
+		data.Functions = append(data.Functions, viewerFunction{
+			Name:      fn.Name,
+			Display:   codegraph.DisplayName(fn),
+			Package:   g.PackageOf(i),
+			File:      fn.File,
+			Line:      fn.StartLine,
+			EndLine:   fn.EndLine,
+			Signature: fn.Signature,
+			Callers:   nonNil(g.Callers(i)),
+			Callees:   nonNil(g.Callees(i)),
+		})
+	}
+
+	// encoding/json escapes <, > and &, so the data cannot close the script element.
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode viewer data: %w", err)
+	}
+	page := strings.Replace(viewerTemplate, "{{TITLE}}", html.EscapeString(title), 1)
+	page = strings.Replace(page, "/*DATA*/null", string(payload), 1)
+	return []byte(page), nil
+}
+
+func nonNil[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
@@ -0,0 +1,292 @@
+// internal/guide/guide.go
+
+package guide
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+)
+
+// Entry point kinds.
+const (
+	EntryMain  = "main"
+	EntryCobra = "command"
+	EntryHTTP  = "http"
+)
+
+// DefaultTop is how many functions the "Most-Called Functions" section lists.
+const DefaultTop = 10
+
+// keyTypesPerPackage and topFunctionsPerPackage bound the per-package sections.
+const (
+	keyTypesPerPackage     = 5
+	topFunctionsPerPackage = 5
+)
+
+var (
+	cobraCommandRe = regexp.MustCompile(`(\w+)\s*:?=\s*&cobra\.Command\{`)
+	cobraFieldRe   = regexp.MustCompile(`^\s*(Use|Short):\s*"((?:[^"\\]|\\.)*)"`)
+	httpRouteRe    = regexp.MustCompile(`\.Handle(?:Func)?\(\s*"([^"]+)"\s*,\s*([\w.]+)`)
+	httpHandlerRe  = regexp.MustCompile(`\(\s*\w+\s+http\.ResponseWriter\s*,\s*\w+\s+\*http\.Request\s*\)$`)
+)
+
+// EntryPoint is a way into the program: a main function, a cobra command or an HTTP
+// handler.
+type EntryPoint struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`   // "main", the command's Use line or the route
+	Target  string `json:"target"` // The function or variable behind it
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Detail  string `json:"detail,omitempty"` // Cobra's Short description
+}
+
+// KeyType is an exported type with the number of methods declared on it.
+type KeyType struct {
+	treesitter.APISymbol
+	Methods int
+}
+
+// Guide is the material for a repository guide. Summaries and Overview are filled in
+// by the LLM; the rest comes from the project map.
+type Guide struct {
+	Repo        string
+	Graph       *codegraph.Graph
+	EntryPoints []EntryPoint
+	Summaries   map[string]string // Package directory -> summary
+	Overview    string
+	GeneratedAt time.Time
+}
+
+// Analyze builds the project metadata and map of the repository at repoPath and
+// derives its packages, call graph and entry points.
+func Analyze(repoPath string) (*Guide, error) {
+	parser := treesitter.NewGoParser()
+	metadata, err := parser.BuildProjectMetadata(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build project metadata: %w", err)
+	}
+	projectMap, err := parser.BuildProjectMap(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build project map: %w", err)
+	}
+
+	graph := codegraph.Build(repoPath, metadata.SourceFiles, projectMap.Functions)
+	if len(graph.Packages) == 0 {
+		return nil, fmt.Errorf("no Go packages found in %s", repoPath)
+	}
+	return &Guide{
+		Repo:        filepath.Base(repoPath),
+		Graph:       graph,
+		EntryPoints: findEntryPoints(repoPath, graph),
+		Summaries:   make(map[string]string),
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// findEntryPoints collects main functions, cobra commands and HTTP handlers, in that
+// order.
+func findEntryPoints(repoPath string, g *codegraph.Graph) []EntryPoint {
+	var mains, commands, handlers []EntryPoint
+	routed := make(map[string]bool) // "dir:Name" of handlers registered on a route
+
+	for _, pkg := range g.Packages {
+		for _, file := range pkg.Files {
+			lines, err := readLines(filepath.Join(repoPath, file))
+			if err != nil {
+				continue
+			}
+			commands = append(commands, cobraCommands(pkg.Dir, file, lines)...)
+			for i, line := range lines {
+				for _, m := range httpRouteRe.FindAllStringSubmatch(line, -1) {
+					target := m[2]
+					if target == "func" {
+						target = "(inline)"
+					} else if dot := strings.LastIndex(target, "."); dot >= 0 {
+						target = target[dot+1:]
+					}
+					ep := EntryPoint{Kind: EntryHTTP, Name: m[1], Target: target, Package: pkg.Dir, File: file, Line: i + 1}
+					if fn, ok := lookup(g, pkg.Dir, target); ok {
+						ep.File, ep.Line = fn.File, fn.StartLine
+						routed[pkg.Dir+":"+target] = true
+					}
+					handlers = append(handlers, ep)
+				}
+			}
+		}
+	}
+
+	for i, fn := range g.Functions {
+		dir := g.PackageOf(i)
+		pkg, _ := g.Package(dir)
+		switch {
+		case fn.Name == "main" && fn.Receiver == "" && pkg.Name == "main":
+			mains = append(mains, EntryPoint{Kind: EntryMain, Name: "main", Target: "main", Package: dir, File: fn.File, Line: fn.StartLine})
+		case isHTTPHandler(fn) && !routed[dir+":"+fn.Name]:
+			name := codegraph.DisplayName(fn)
+			handlers = append(handlers, EntryPoint{Kind: EntryHTTP, Name: name, Target: name, Package: dir, File: fn.File, Line: fn.StartLine})
+		}
+	}
+
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return append(append(mains, commands...), handlers...)
+}
+
+// cobraCommands finds `x := &cobra.Command{...}` literals and their Use and Short fields.
+func cobraCommands(dir, file string, lines []string) []EntryPoint {
+	var commands []EntryPoint
+	for i, line := range lines {
+		m := cobraCommandRe.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		ep := EntryPoint{Kind: EntryCobra, Target: m[1], Package: dir, File: file, Line: i + 1}
+		// Read the literal's own fields, up to its closing brace.
+		depth := 1
+		for _, field := range lines[i+1:] {
+			if f := cobraFieldRe.FindStringSubmatch(field); f != nil && depth == 1 {
+				if f[1] == "Use" {
+					ep.Name = f[2]
+				} else {
+					ep.Detail = f[2]
+				}
+			}
+			depth += strings.Count(field, "{") - strings.Count(field, "}")
+			if depth <= 0 {
+				break
+			}
+		}
+		if ep.Name == "" {
+			ep.Name = m[1]
+		}
+		commands = append(commands, ep)
+	}
+	return commands
+}
+
+// isHTTPHandler reports whether fn is an http.HandlerFunc, or takes no arguments and
+// returns a handler. Middleware and helpers that take a ResponseWriter are left out.
+func isHTTPHandler(fn treesitter.FunctionInfo) bool {
+	if httpHandlerRe.MatchString(fn.Signature) {
+		return true
+	}
+	if !strings.HasSuffix(fn.Signature, fn.Name+"()") && !strings.Contains(fn.Signature, fn.Name+"() ") {
+		return false
+	}
+	for _, r := range fn.Returns {
+		if r == "http.HandlerFunc" || r == "http.Handler" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup finds a plain function by name in the package at dir.
+func lookup(g *codegraph.Graph, dir, name string) (treesitter.FunctionInfo, bool) {
+	pkg, ok := g.Package(dir)
+	if !ok {
+		return treesitter.FunctionInfo{}, false
+	}
+	for _, i := range pkg.Functions {
+		if fn := g.Functions[i]; fn.Name == name && fn.Receiver == "" {
+			return fn, true
+		}
+	}
+	return treesitter.FunctionInfo{}, false
+}
+
+// EntryPointsIn returns the entry points declared in the package at dir.
+func (gd *Guide) EntryPointsIn(dir string) []EntryPoint {
+	var out []EntryPoint
+	for _, ep := range gd.EntryPoints {
+		if ep.Package == dir {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+// KeyTypes returns up to n exported types of the package at dir, those with the most
+// methods first.
+func (gd *Guide) KeyTypes(dir string, n int) []KeyType {
+	pkg, ok := gd.Graph.Package(dir)
+	if !ok {
+		return nil
+	}
+	methods := make(map[string]int)
+	for _, i := range pkg.Functions {
+		if r := gd.Graph.Functions[i].Receiver; r != "" {
+			methods[r]++
+		}
+	}
+	types := make([]KeyType, 0, len(pkg.Types))
+	for _, sym := range pkg.Types {
+		types = append(types, KeyType{APISymbol: sym, Methods: methods[sym.Name]})
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		if types[i].Methods != types[j].Methods {
+			return types[i].Methods > types[j].Methods
+		}
+		return types[i].Name < types[j].Name
+	})
+	if n > 0 && len(types) > n {
+		types = types[:n]
+	}
+	return types
+}
+
+// TopFunctions returns up to n functions of the package at dir ranked by their number
+// of callers.
+func (gd *Guide) TopFunctions(dir string, n int) []codegraph.Ranked {
+	var out []codegraph.Ranked
+	for _, r := range gd.Graph.MostCalled(0) {
+		if gd.Graph.PackageOf(r.Index) == dir {
+			out = append(out, r)
+			if len(out) == n {
+				break
+			}
+		}
+	}
+	return out
+}
+
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// packageLabel names a package for headings and diagrams: its directory, or the module
+// path's last element for the root package.
+func packageLabel(g *codegraph.Graph, dir string) string {
+	if dir != "." {
+		return dir
+	}
+	if g.Module != "" {
+		return path.Base(g.Module)
+	}
+	if pkg, ok := g.Package(dir); ok {
+		return pkg.Name
+	}
+	return dir
+}
@@ -0,0 +1,141 @@
+// internal/guide/render.go
+
+package guide
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+)
+
+// maxFlowVia caps the called functions listed per data flow edge.
+const maxFlowVia = 5
+
+var mermaidIDRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Markdown renders the guide with Mermaid diagrams for the package architecture and
+// the data flow between packages. top bounds the most-called functions table.
+func (gd *Guide) Markdown(top int) string {
+	g := gd.Graph
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s Guide\n\n", gd.Repo)
+	fmt.Fprintf(&b, "_Generated by PRBuddy-Go on %s from the project map._\n\n", gd.GeneratedAt.Format("2006-01-02"))
+
+	b.WriteString("## Overview\n\n")
+	if gd.Overview != "" {
+		b.WriteString(strings.TrimSpace(gd.Overview) + "\n\n")
+	}
+	fmt.Fprintf(&b, "%d package(s), %d function(s) and %d entry point(s).\n\n", len(g.Packages), len(g.Functions), len(gd.EntryPoints))
+
+	b.WriteString("## Architecture\n\nRepository packages and the packages they import.\n\n")
+	b.WriteString("```mermaid\ngraph TD\n")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", mermaidID(pkg.Dir), packageLabel(g, pkg.Dir))
+	}
+	for _, pkg := range g.Packages {
+		for _, dep := range pkg.Imports {
+			fmt.Fprintf(&b, "    %s --> %s\n", mermaidID(pkg.Dir), mermaidID(dep))
+		}
+	}
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Entry Points\n\n")
+	if len(gd.EntryPoints) == 0 {
+		b.WriteString("No main functions, cobra commands or HTTP handlers were found.\n\n")
+	} else {
+		b.WriteString("| Kind | Name | Target | Location | Description |\n|---|---|---|---|---|\n")
+		for _, ep := range gd.EntryPoints {
+			fmt.Fprintf(&b, "| %s | `%s` | `%s` | %s:%d | %s |\n", ep.Kind, cell(ep.Name), ep.Target, ep.File, ep.Line, cell(ep.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Packages\n\n")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(&b, "### `%s` (package %s)\n\n", packageLabel(g, pkg.Dir), pkg.Name)
+		if summary := strings.TrimSpace(gd.Summaries[pkg.Dir]); summary != "" {
+			b.WriteString(summary + "\n\n")
+		}
+		fmt.Fprintf(&b, "- **Files:** %d, **Functions:** %d\n", len(pkg.Files), len(pkg.Functions))
+		fmt.Fprintf(&b, "- **Imports:** %s\n", codeList(g, pkg.Imports))
+		fmt.Fprintf(&b, "- **Imported by:** %s\n\n", codeList(g, g.ImportedBy(pkg.Dir)))
+
+		if types := gd.KeyTypes(pkg.Dir, keyTypesPerPackage); len(types) > 0 {
+			b.WriteString("**Key types**\n\n| Type | Kind | Methods | Location |\n|---|---|---|---|\n")
+			for _, t := range types {
+				fmt.Fprintf(&b, "| `%s` | %s | %d | %s:%d |\n", t.Name, t.Kind, t.Methods, t.File, t.Line)
+			}
+			b.WriteString("\n")
+		}
+		if ranked := gd.TopFunctions(pkg.Dir, topFunctionsPerPackage); len(ranked) > 0 {
+			b.WriteString("**Most-called functions**\n\n")
+			for _, r := range ranked {
+				fn := g.Functions[r.Index]
+				fmt.Fprintf(&b, "- `%s` (%s:%d), %d caller(s)\n", codegraph.DisplayName(fn), fn.File, fn.StartLine, r.Callers)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## Most-Called Functions\n\nRanked by how many distinct functions call them.\n\n")
+	if ranked := g.MostCalled(top); len(ranked) == 0 {
+		b.WriteString("No calls between project functions were found.\n\n")
+	} else {
+		b.WriteString("| # | Function | Package | Callers | Location |\n|---|---|---|---|---|\n")
+		for i, r := range ranked {
+			fn := g.Functions[r.Index]
+			fmt.Fprintf(&b, "| %d | `%s` | %s | %d | %s:%d |\n", i+1, codegraph.DisplayName(fn), packageLabel(g, g.PackageOf(r.Index)), r.Callers, fn.File, fn.StartLine)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Data Flow\n\nCalls from one package's functions into another's.\n\n")
+	flows := g.Flows()
+	if len(flows) == 0 {
+		b.WriteString("No calls cross package boundaries.\n")
+		return b.String()
+	}
+	b.WriteString("```mermaid\ngraph LR\n")
+	for _, f := range flows {
+		fmt.Fprintf(&b, "    %s[\"%s\"] -->|%d| %s[\"%s\"]\n", mermaidID(f.From), packageLabel(g, f.From), f.Calls, mermaidID(f.To), packageLabel(g, f.To))
+	}
+	b.WriteString("```\n\n| From | To | Calls | Via |\n|---|---|---|---|\n")
+	for _, f := range flows {
+		via := f.Via
+		more := ""
+		if len(via) > maxFlowVia {
+			more = fmt.Sprintf(" and %d more", len(via)-maxFlowVia)
+			via = via[:maxFlowVia]
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | `%s`%s |\n", packageLabel(g, f.From), packageLabel(g, f.To), f.Calls, strings.Join(via, "`, `"), more)
+	}
+	return b.String()
+}
+
+// mermaidID turns a package directory into a Mermaid node identifier.
+func mermaidID(dir string) string {
+	if dir == "." {
+		return "pkg_root"
+	}
+	return "pkg_" + mermaidIDRe.ReplaceAllString(dir, "_")
+}
+
+// codeList renders package directories as a comma-separated list of code spans.
+func codeList(g *codegraph.Graph, dirs []string) string {
+	if len(dirs) == 0 {
+		return "none"
+	}
+	items := make([]string, len(dirs))
+	for i, dir := range dirs {
+		items[i] = "`" + packageLabel(g, dir) + "`"
+	}
+	return strings.Join(items, ", ")
+}
+
+// cell escapes text for a markdown table cell.
+func cell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
@@ -0,0 +1,342 @@
+// internal/health/health.go
+
+package health
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/mapquery"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// PostCommitEnv enables the Code Health section of post-commit PR drafts, which parses
+// every Go file at the merge-base and at HEAD on each commit.
+const PostCommitEnv = "PRBUDDY_CODE_HEALTH"
+
+// Finding kinds.
+const (
+	KindUnused     = "unused"
+	KindLong       = "long"
+	KindComplex    = "complex"
+	KindNested     = "nested"
+	KindReturnPath = "returns"
+)
+
+// Thresholds are the largest values a function may have before it is reported.
+type Thresholds struct {
+	Lines       int `json:"lines"`
+	Complexity  int `json:"complexity"`
+	Nesting     int `json:"nesting"`
+	ReturnPaths int `json:"return_paths"`
+}
+
+// DefaultThresholds are the limits used when none are given.
+var DefaultThresholds = Thresholds{Lines: 80, Complexity: 15, Nesting: 4, ReturnPaths: 6}
+
+// Finding is one function over a threshold, or an unreferenced unexported function.
+type Finding struct {
+	Kind     string `json:"kind"`
+	Function string `json:"function"` // Type.Method or Name
+	Package  string `json:"package"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Value    int    `json:"value,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+// Report lists the findings for a set of functions.
+type Report struct {
+	Thresholds Thresholds `json:"thresholds"`
+	Functions  int        `json:"functions"` // Non-test functions checked
+	Findings   []Finding  `json:"findings"`
+}
+
+// Delta is the change in findings from the merge-base of a base branch to HEAD.
+type Delta struct {
+	Base       string     `json:"base"`
+	Head       string     `json:"head"`
+	Thresholds Thresholds `json:"thresholds"`
+	Added      []Finding  `json:"added"`
+	Resolved   []Finding  `json:"resolved"`
+}
+
+// Source is the Go code of a revision or working tree.
+type Source struct {
+	Functions  []treesitter.FunctionInfo
+	References map[string]int // Identifier occurrences by name, declarations included
+}
+
+// add parses one file into the source. Unparsable files are skipped.
+func (s *Source) add(file string, content []byte) {
+	functions, err := treesitter.ParseGoSource(file, content)
+	if err != nil {
+		return
+	}
+	identifiers, err := treesitter.ParseIdentifiers(file, content)
+	if err != nil {
+		return
+	}
+	s.Functions = append(s.Functions, functions...)
+	for name, n := range identifiers {
+		s.References[name] += n
+	}
+}
+
+// Check reports the functions over the thresholds and the unexported functions whose
+// name appears nowhere but in their declarations. Unlike mapquery's callers, any
+// reference counts, so functions passed as values are not reported. Test files count
+// as references but are not checked themselves.
+func Check(src *Source, t Thresholds) *Report {
+	report := &Report{Thresholds: t, Findings: []Finding{}}
+	declared := make(map[string]int)
+	for _, fn := range src.Functions {
+		declared[fn.Name]++
+	}
+
+	for _, fn := range src.Functions {
+		if strings.HasSuffix(fn.File, "_test.go") {
+			continue
+		}
+		report.Functions++
+		finding := func(kind string, value, limit int) Finding {
+			return Finding{Kind: kind, Function: codegraph.DisplayName(fn), Package: path.Dir(fn.File),
+				File: fn.File, Line: fn.StartLine, Value: value, Limit: limit}
+		}
+
+		if !token.IsExported(fn.Name) && !mapquery.IsEntryPoint(fn) && src.References[fn.Name] <= declared[fn.Name] {
+			report.Findings = append(report.Findings, finding(KindUnused, 0, 0))
+		}
+		if lines := fn.EndLine - fn.StartLine + 1; lines > t.Lines {
+			report.Findings = append(report.Findings, finding(KindLong, lines, t.Lines))
+		}
+		m := fn.Metrics
+		if m.Complexity > t.Complexity {
+			report.Findings = append(report.Findings, finding(KindComplex, m.Complexity, t.Complexity))
+		}
+		if m.MaxNesting > t.Nesting {
+			report.Findings = append(report.Findings, finding(KindNested, m.MaxNesting, t.Nesting))
+		}
+		if m.ReturnPaths > t.ReturnPaths {
+			report.Findings = append(report.Findings, finding(KindReturnPath, m.ReturnPaths, t.ReturnPaths))
+		}
+	}
+	sortFindings(report.Findings)
+	return report
+}
+
+// Snapshot parses the Go files of a revision. Vendored and testdata files are skipped.
+func Snapshot(rev string) (*Source, error) {
+	out, err := utils.ExecGit("ls-tree", "-r", "--name-only", rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", rev, err)
+	}
+
+	var files []string
+	for _, file := range utils.SplitLines(out) {
+		if isSourceFile(file) {
+			files = append(files, file)
+		}
+	}
+	blobs, err := utils.ReadBlobs(rev, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files at %s: %w", rev, err)
+	}
+
+	src := &Source{References: make(map[string]int)}
+	for _, file := range files {
+		if content, ok := blobs[file]; ok {
+			src.add(file, content)
+		}
+	}
+	return src, nil
+}
+
+// WorkingTree parses the tracked and untracked, non-ignored Go files of the working tree.
+func WorkingTree(repoPath string) (*Source, error) {
+	out, err := utils.ExecGit("ls-files", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+
+	src := &Source{References: make(map[string]int)}
+	for _, file := range utils.SplitLines(out) {
+		if !isSourceFile(file) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			continue
+		}
+		src.add(file, content)
+	}
+	return src, nil
+}
+
+// Diff compares the findings at the merge-base of base and HEAD against HEAD.
+func Diff(base string, t Thresholds) (*Delta, error) {
+	mergeBase, err := utils.ExecGit("merge-base", base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge-base with %s: %w", base, err)
+	}
+	head, err := utils.GetLatestCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	delta := &Delta{Base: mergeBase, Head: head, Thresholds: t, Added: []Finding{}, Resolved: []Finding{}}
+	if mergeBase == head {
+		return delta, nil
+	}
+
+	oldSrc, err := Snapshot(mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	newSrc, err := Snapshot(head)
+	if err != nil {
+		return nil, err
+	}
+	delta.Added, delta.Resolved = Compare(Check(oldSrc, t), Check(newSrc, t))
+	return delta, nil
+}
+
+// Compare returns the findings of cur that old does not have, and those of old that are
+// gone from cur. Findings are matched by kind, package and function, so moving a
+// function within its package or growing it further is not a new finding.
+func Compare(old, cur *Report) (added, resolved []Finding) {
+	key := func(f Finding) string { return f.Kind + " " + f.Package + " " + f.Function }
+	oldKeys, curKeys := make(map[string]bool), make(map[string]bool)
+	for _, f := range old.Findings {
+		oldKeys[key(f)] = true
+	}
+	for _, f := range cur.Findings {
+		curKeys[key(f)] = true
+	}
+
+	added, resolved = []Finding{}, []Finding{}
+	for _, f := range cur.Findings {
+		if !oldKeys[key(f)] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range old.Findings {
+		if !curKeys[key(f)] {
+			resolved = append(resolved, f)
+		}
+	}
+	return added, resolved
+}
+
+// HasChanges reports whether the branch adds or resolves any finding.
+func (d *Delta) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Resolved) > 0
+}
+
+// Summary describes the added and resolved findings by kind, e.g. "adds 2 functions
+// over complexity 15".
+func (d *Delta) Summary() []string {
+	var lines []string
+	for _, kind := range []string{KindComplex, KindLong, KindNested, KindReturnPath, KindUnused} {
+		if n := count(d.Added, kind); n > 0 {
+			lines = append(lines, fmt.Sprintf("adds %s", describeKind(kind, n, d.Thresholds)))
+		}
+		if n := count(d.Resolved, kind); n > 0 {
+			lines = append(lines, fmt.Sprintf("removes %s", describeKind(kind, n, d.Thresholds)))
+		}
+	}
+	return lines
+}
+
+// Markdown renders the delta as a "Code Health" section for a PR draft.
+func (d *Delta) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Code Health\n\n")
+	for _, line := range d.Summary() {
+		b.WriteString("- This PR " + line + "\n")
+	}
+	if len(d.Added) > 0 {
+		b.WriteString("\nNew findings:\n\n")
+		for _, f := range d.Added {
+			b.WriteString("- " + f.Describe() + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Describe renders a one-line description of the finding.
+func (f Finding) Describe() string {
+	where := fmt.Sprintf("`%s` (%s:%d)", f.Function, f.File, f.Line)
+	switch f.Kind {
+	case KindUnused:
+		return where + " is not called anywhere"
+	case KindLong:
+		return fmt.Sprintf("%s is %d lines long (limit %d)", where, f.Value, f.Limit)
+	case KindComplex:
+		return fmt.Sprintf("%s has cyclomatic complexity %d (limit %d)", where, f.Value, f.Limit)
+	case KindNested:
+		return fmt.Sprintf("%s nests %d levels deep (limit %d)", where, f.Value, f.Limit)
+	default:
+		return fmt.Sprintf("%s has %d return statements (limit %d)", where, f.Value, f.Limit)
+	}
+}
+
+// describeKind renders n findings of a kind, e.g. "2 functions over complexity 15".
+func describeKind(kind string, n int, t Thresholds) string {
+	noun := "functions"
+	if n == 1 {
+		noun = "function"
+	}
+	switch kind {
+	case KindUnused:
+		return fmt.Sprintf("%d unreferenced unexported %s", n, noun)
+	case KindLong:
+		return fmt.Sprintf("%d %s over %d lines", n, noun, t.Lines)
+	case KindComplex:
+		return fmt.Sprintf("%d %s over complexity %d", n, noun, t.Complexity)
+	case KindNested:
+		return fmt.Sprintf("%d %s nested deeper than %d levels", n, noun, t.Nesting)
+	default:
+		return fmt.Sprintf("%d %s with more than %d return statements", n, noun, t.ReturnPaths)
+	}
+}
+
+func count(findings []Finding, kind string) int {
+	n := 0
+	for _, f := range findings {
+		if f.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// sortFindings orders findings by file and line.
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// isSourceFile reports whether a file is Go source outside vendor and testdata.
+func isSourceFile(file string) bool {
+	if !strings.HasSuffix(file, ".go") {
+		return false
+	}
+	for _, segment := range strings.Split(path.Dir(file), "/") {
+		if segment == "vendor" || segment == "testdata" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,301 @@
+// internal/history/history.go
+
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// DefaultMaxCommits bounds how many commits of git log -L are collected.
+const DefaultMaxCommits = 10
+
+// lineContext is how many lines around a line outside any function are explained.
+const lineContext = 5
+
+// commitSeparator splits records in Collect's git log output.
+const commitSeparator = "\x1e"
+
+var blameHeaderPattern = regexp.MustCompile(`^([0-9a-f]{40}) \d+ \d+`)
+
+// Target is the range of lines whose history is explained.
+type Target struct {
+	File      string // Repo-relative path
+	StartLine int
+	EndLine   int
+	Function  string // Display name (Type.Method for methods); empty for a bare line range
+}
+
+// String returns "Name (file:start-end)", or "file:start-end" for a bare line range.
+func (t Target) String() string {
+	lines := fmt.Sprintf("%s:%d-%d", t.File, t.StartLine, t.EndLine)
+	if t.Function == "" {
+		return lines
+	}
+	return fmt.Sprintf("%s (%s)", t.Function, lines)
+}
+
+// Commit is a commit that shaped the target's lines.
+type Commit struct {
+	SHA         string
+	Author      string
+	Date        string // YYYY-MM-DD
+	Subject     string
+	Body        string
+	Diff        string // The commit's change to the target's lines, from git log -L
+	BlamedLines int    // Lines of the current range last changed by this commit
+	Draft       string // Stored PR draft for this commit, if any
+	time        int64
+}
+
+// Locate resolves spec in the repository at repoPath. spec is "file:line", which
+// selects the function containing that line (or a few lines around it outside any
+// function), or a function as accepted by treesitter.FindFunction.
+func Locate(repoPath, spec string) (Target, error) {
+	if idx := strings.LastIndex(spec, ":"); idx != -1 {
+		if line, err := strconv.Atoi(spec[idx+1:]); err == nil {
+			return locateLine(repoPath, filepath.ToSlash(filepath.Clean(spec[:idx])), line)
+		}
+	}
+
+	fn, _, err := treesitter.FindFunction(repoPath, spec)
+	if err != nil {
+		return Target{}, err
+	}
+	return functionTarget(fn), nil
+}
+
+func functionTarget(fn treesitter.FunctionInfo) Target {
+	name := fn.Name
+	if fn.Receiver != "" {
+		name = fn.Receiver + "." + fn.Name
+	}
+	return Target{File: treesitter.RepoRelativePath(fn.File), StartLine: fn.StartLine, EndLine: fn.EndLine, Function: name}
+}
+
+func locateLine(repoPath, file string, line int) (Target, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, file))
+	if err != nil {
+		return Target{}, fmt.Errorf("cannot read %s: %w", file, err)
+	}
+	total := len(strings.Split(strings.TrimSuffix(string(content), "\n"), "\n"))
+	if line < 1 || line > total {
+		return Target{}, fmt.Errorf("%s has %d lines; line %d is out of range", file, total, line)
+	}
+
+	if fn, ok := treesitter.FunctionAt(repoPath, file, line); ok {
+		return functionTarget(fn), nil
+	}
+	return Target{File: file, StartLine: max(1, line-lineContext), EndLine: min(total, line+lineContext)}, nil
+}
+
+// Source returns the target's lines as they are in the working tree.
+func Source(repoPath string, t Target) (string, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, t.File))
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", t.File, err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if t.StartLine < 1 || t.EndLine > len(lines) || t.StartLine > t.EndLine {
+		return "", fmt.Errorf("line range %d-%d out of bounds for %s", t.StartLine, t.EndLine, t.File)
+	}
+	return strings.Join(lines[t.StartLine-1:t.EndLine], "\n"), nil
+}
+
+// Collect lists the commits that shaped the target's lines, oldest first: the latest
+// maxCommits commits of git log -L over the range as it is in HEAD, plus any older
+// commit git blame still attributes current lines to. Uncommitted lines are not
+// attributed, and a file that is not in HEAD has no history.
+func Collect(t Target, maxCommits int) ([]Commit, error) {
+	if maxCommits <= 0 {
+		maxCommits = DefaultMaxCommits
+	}
+	head, err := utils.ExecGitRaw(nil, "show", "HEAD:"+t.File)
+	if err != nil {
+		return nil, nil // Not committed yet
+	}
+
+	var commits []Commit
+	if start, end, ok := headRange(t, head); ok {
+		out, err := utils.ExecGit("log", fmt.Sprintf("-L%d,%d:%s", start, end, t.File),
+			"-n", strconv.Itoa(maxCommits), "--format="+commitSeparator+"%H%x1f%an%x1f%as%x1f%at%x1f%s%x1f%b%x1f")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the history of %s: %w", t.File, err)
+		}
+		commits = parseLog(out)
+	}
+
+	index := make(map[string]int)
+	for i, c := range commits {
+		index[c.SHA] = i
+	}
+
+	blamed, err := blameCommits(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blamed {
+		if i, ok := index[b.SHA]; ok {
+			commits[i].BlamedLines = b.BlamedLines
+			continue
+		}
+		if body, err := utils.ExecGit("log", "-1", "--format=%b", b.SHA); err == nil {
+			b.Body = strings.TrimSpace(body)
+		}
+		index[b.SHA] = len(commits)
+		commits = append(commits, b)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		if commits[i].time != commits[j].time {
+			return commits[i].time < commits[j].time
+		}
+		return isAncestor(commits[i].SHA, commits[j].SHA)
+	})
+	return commits, nil
+}
+
+// parseLog splits Collect's git log -L output into commits.
+func parseLog(out string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(out, commitSeparator) {
+		fields := strings.SplitN(record, "\x1f", 7)
+		if len(fields) < 7 {
+			continue
+		}
+		c := Commit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Date:    fields[2],
+			Subject: strings.TrimSpace(fields[4]),
+			Body:    strings.TrimSpace(fields[5]),
+			Diff:    strings.TrimSpace(fields[6]),
+		}
+		c.time, _ = strconv.ParseInt(fields[3], 10, 64)
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// headRange returns the target's lines as numbered in HEAD, whose content is head, for
+// git log -L. A function is located in HEAD by name; a bare range is shifted past the
+// uncommitted hunks above it. ok is false when none of the lines exist in HEAD.
+func headRange(t Target, head string) (start, end int, ok bool) {
+	if t.Function != "" {
+		functions, err := treesitter.ParseGoSource(t.File, []byte(head))
+		if err != nil {
+			return 0, 0, false
+		}
+		for _, fn := range functions {
+			if functionTarget(fn).Function == t.Function {
+				return fn.StartLine, fn.EndLine, true
+			}
+		}
+		return 0, 0, false // Function not committed yet
+	}
+
+	diff, err := utils.ExecGit("diff", "-U0", "HEAD", "--", t.File)
+	if err != nil {
+		return 0, 0, false
+	}
+	var hunks []utils.Hunk
+	for _, f := range utils.ParseUnifiedDiff(diff) {
+		hunks = append(hunks, f.Hunks...)
+	}
+	total := len(strings.Split(strings.TrimSuffix(head, "\n"), "\n"))
+	start = max(1, headLine(hunks, t.StartLine, false))
+	end = min(total, headLine(hunks, t.EndLine, true))
+	return start, end, start <= end
+}
+
+// headLine maps a working-tree line to HEAD through the hunks of git diff -U0 HEAD.
+// A line inside a hunk maps to the first (or, for the end of a range, the last) HEAD
+// line the hunk replaced.
+func headLine(hunks []utils.Hunk, line int, rangeEnd bool) int {
+	shift := 0
+	for _, h := range hunks {
+		if h.NewLines == 0 { // Pure deletion after line NewStart
+			if line > h.NewStart {
+				shift += h.OldLines
+			}
+			continue
+		}
+		if line >= h.NewStart+h.NewLines {
+			shift += h.OldLines - h.NewLines
+			continue
+		}
+		if line >= h.NewStart {
+			if h.OldLines == 0 { // Pure addition after line OldStart
+				if rangeEnd {
+					return h.OldStart
+				}
+				return h.OldStart + 1
+			}
+			if rangeEnd {
+				return h.OldStart + h.OldLines - 1
+			}
+			return h.OldStart
+		}
+		break
+	}
+	return line + shift
+}
+
+// isAncestor orders commits made in the same second.
+func isAncestor(ancestor, descendant string) bool {
+	_, err := utils.ExecGit("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+// blameCommits returns the commits git blame attributes the target's current lines to,
+// with BlamedLines set, in order of first appearance.
+func blameCommits(t Target) ([]Commit, error) {
+	out, err := utils.ExecGit("blame", "--line-porcelain", "-L", fmt.Sprintf("%d,%d", t.StartLine, t.EndLine), "--", t.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", t.File, err)
+	}
+
+	var commits []Commit
+	index := make(map[string]int)
+	current := -1
+	for _, line := range strings.Split(out, "\n") {
+		if m := blameHeaderPattern.FindStringSubmatch(line); m != nil {
+			if strings.Trim(m[1], "0") == "" {
+				current = -1 // Not committed yet
+				continue
+			}
+			i, ok := index[m[1]]
+			if !ok {
+				i = len(commits)
+				index[m[1]] = i
+				commits = append(commits, Commit{SHA: m[1]})
+			}
+			commits[i].BlamedLines++
+			current = i
+			continue
+		}
+		if current < 0 || commits[current].BlamedLines > 1 {
+			continue // Commit details are repeated for every line
+		}
+		c := &commits[current]
+		switch {
+		case strings.HasPrefix(line, "author "):
+			c.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-time "):
+			c.time, _ = strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64)
+			c.Date = time.Unix(c.time, 0).UTC().Format("2006-01-02")
+		case strings.HasPrefix(line, "summary "):
+			c.Subject = strings.TrimPrefix(line, "summary ")
+		}
+	}
+	return commits, nil
+}
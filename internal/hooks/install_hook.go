@@ -10,6 +10,34 @@ import (
 )
 
 func InstallPostCommitHook() error {
+	// Define the hook content
+	prBuddyHookContent := `echo "` + utils.Cyan("[PRBuddy-Go] Commit detected. Generating pull request...") + `"
+
+# Run the PR generation command
+prbuddy-go post-commit --non-interactive
+
+if [ $? -eq 0 ]; then
+  echo "` + utils.Green("[PRBuddy-Go] Pull request generated successfully.") + `"
+else
+  echo "` + utils.Red("[PRBuddy-Go] Failed to generate pull request.") + `"
+fi`
+
+	return installHook("post-commit", "prbuddy-go post-commit", prBuddyHookContent)
+}
+
+// InstallPrepareCommitMsgHook installs a prepare-commit-msg hook that drafts a
+// Conventional Commits message from the staged diff. The command itself skips
+// merges, amends and user-supplied messages, and never blocks the commit.
+func InstallPrepareCommitMsgHook() error {
+	prBuddyHookContent := `# Draft a commit message from the staged changes
+prbuddy-go commit-msg "$1" "$2" "$3" || true`
+
+	return installHook("prepare-commit-msg", "prbuddy-go commit-msg", prBuddyHookContent)
+}
+
+// installHook writes a hook script, or appends PRBuddy's snippet to an existing hook
+// that does not already contain marker.
+func installHook(name, marker, prBuddyHookContent string) error {
 	repoPath, err := utils.GetRepoPath()
 	if err != nil {
 		return err
@@ -25,53 +53,38 @@ func InstallPostCommitHook() error {
 		}
 	}
 
-	// Define the hook content
-	prBuddyHookContent := `echo "` + utils.Cyan("[PRBuddy-Go] Commit detected. Generating pull request...") + `"
-
-# Run the PR generation command
-prbuddy-go post-commit --non-interactive
-
-if [ $? -eq 0 ]; then
-  echo "` + utils.Green("[PRBuddy-Go] Pull request generated successfully.") + `"
-else
-  echo "` + utils.Red("[PRBuddy-Go] Failed to generate pull request.") + `"
-fi`
-
-	postCommitPath := filepath.Join(hooksDir, "post-commit")
+	hookPath := filepath.Join(hooksDir, name)
 
-	// Check if the post-commit hook already exists
-	if _, err := os.Stat(postCommitPath); err == nil {
+	// Check if the hook already exists
+	if _, err := os.Stat(hookPath); err == nil {
 		// Read the existing hook content
-		existingContent, err := os.ReadFile(postCommitPath)
+		existingContent, err := os.ReadFile(hookPath)
 		if err != nil {
-			return fmt.Errorf("failed to read existing post-commit hook: %w", err)
+			return fmt.Errorf("failed to read existing %s hook: %w", name, err)
 		}
 
 		// Check if the PRBuddy hook content is already present
-		if strings.Contains(string(existingContent), "prbuddy-go post-commit") {
-			fmt.Println(utils.Green("[PRBuddy-Go] post-commit hook already contains PRBuddy logic. Skipping reinstallation."))
+		if strings.Contains(string(existingContent), marker) {
+			fmt.Println(utils.Green(fmt.Sprintf("[PRBuddy-Go] %s hook already contains PRBuddy logic. Skipping reinstallation.", name)))
 			return nil
 		}
 
 		// Append PRBuddy hook content to the existing hook
-		updatedContent := string(existingContent) + "\n\n# Added by PRBuddy-Go\n" + prBuddyHookContent
-		err = os.WriteFile(postCommitPath, []byte(updatedContent), 0755)
+		updatedContent := string(existingContent) + "\n\n# Added by PRBuddy-Go\n" + prBuddyHookContent + "\n"
+		err = os.WriteFile(hookPath, []byte(updatedContent), 0755)
 		if err != nil {
-			return fmt.Errorf("failed to append PRBuddy logic to existing post-commit hook: %w", err)
+			return fmt.Errorf("failed to append PRBuddy logic to existing %s hook: %w", name, err)
 		}
-		fmt.Println(utils.Green("[PRBuddy-Go] post-commit hook updated with PRBuddy logic."))
+		fmt.Println(utils.Green(fmt.Sprintf("[PRBuddy-Go] %s hook updated with PRBuddy logic.", name)))
 	} else {
 		// If the hook doesn't exist, create a new one
-		newHookContent := `
-						#!/bin/bash
-						# Added by PRBuddy-Go
-						` + prBuddyHookContent
+		newHookContent := "#!/bin/bash\n# Added by PRBuddy-Go\n" + prBuddyHookContent + "\n"
 
-		err = os.WriteFile(postCommitPath, []byte(newHookContent), 0755)
+		err = os.WriteFile(hookPath, []byte(newHookContent), 0755)
 		if err != nil {
-			return fmt.Errorf("failed to write post-commit hook: %w", err)
+			return fmt.Errorf("failed to write %s hook: %w", name, err)
 		}
-		fmt.Printf(utils.Cyan("[PRBuddy-Go] post-commit hook installed at %s\n"), postCommitPath)
+		fmt.Println(utils.Cyan(fmt.Sprintf("[PRBuddy-Go] %s hook installed at %s", name, hookPath)))
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
@@ -32,3 +33,48 @@ func RemovePostCommitHook() error {
 	fmt.Printf("[PRBuddy-Go] post-commit hook removed from %s\n", postCommitPath)
 	return nil
 }
+
+// RemovePrepareCommitMsgHook removes PRBuddy's snippet from the prepare-commit-msg hook,
+// deleting the hook entirely if nothing else remains in it.
+func RemovePrepareCommitMsgHook() error {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return err
+	}
+
+	hookPath := filepath.Join(repoPath, ".git", "hooks", "prepare-commit-msg")
+
+	content, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read prepare-commit-msg hook: %w", err)
+	}
+
+	var kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.Contains(trimmed, "prbuddy-go commit-msg") ||
+			trimmed == "# Added by PRBuddy-Go" ||
+			trimmed == "# Draft a commit message from the staged changes" {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	remaining := strings.TrimSpace(strings.Join(kept, "\n"))
+	if remaining == "" || remaining == "#!/bin/bash" || remaining == "#!/bin/sh" {
+		if err := os.Remove(hookPath); err != nil {
+			return fmt.Errorf("failed to remove prepare-commit-msg hook: %w", err)
+		}
+		fmt.Printf("[PRBuddy-Go] prepare-commit-msg hook removed from %s\n", hookPath)
+		return nil
+	}
+
+	if err := os.WriteFile(hookPath, []byte(remaining+"\n"), 0755); err != nil {
+		return fmt.Errorf("failed to update prepare-commit-msg hook: %w", err)
+	}
+	fmt.Printf("[PRBuddy-Go] PRBuddy logic removed from %s\n", hookPath)
+	return nil
+}
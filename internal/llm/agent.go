@@ -0,0 +1,115 @@
+// internal/llm/agent.go
+
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/agent"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// NoToolsEnv disables the repository tools in quickassist and DCE when set to a true value.
+const NoToolsEnv = "PRBUDDY_NO_TOOLS"
+
+// AgentMaxStepsEnv overrides how many tool-calling rounds a single answer may take.
+const AgentMaxStepsEnv = "PRBUDDY_AGENT_MAX_STEPS"
+
+// DefaultAgentMaxSteps bounds the tool-calling rounds of one answer.
+const DefaultAgentMaxSteps = 8
+
+const toolSystemPrompt = `You can inspect the user's git repository with read-only tools (read files, search, list functions, history, blame and diffs). ` +
+	`Use them when the answer depends on code you have not seen, rather than guessing. ` +
+	`Paths are relative to the repository root. When you have enough information, reply to the user in plain text without calling a tool.`
+
+const toolBudgetPrompt = "You have used all available tool calls. Answer now with what you have learned so far."
+
+// AgentOptions bounds RunAgent.
+type AgentOptions struct {
+	MaxSteps int // Tool-calling rounds before the model must answer; DefaultAgentMaxSteps if zero
+}
+
+// RunAgent answers messages with a tool-calling loop: the model's tool calls are run
+// in toolbox and their results fed back until it replies without calling a tool. When
+// the step budget is spent, the model is asked for a final answer without tools.
+// It returns ErrToolsUnsupported if the client or model cannot call tools.
+func RunAgent(client LLMClient, messages []contextpkg.Message, toolbox *agent.Toolbox, opts AgentOptions) (string, error) {
+	caller, ok := client.(ToolCaller)
+	if !ok {
+		return "", ErrToolsUnsupported
+	}
+	maxSteps := opts.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultAgentMaxSteps
+	}
+
+	history := append([]contextpkg.Message(nil), messages...)
+	tools := toolbox.Definitions()
+	for step := 0; step <= maxSteps; step++ {
+		if step == maxSteps {
+			// Out of budget: withdraw the tools so the model has to answer.
+			history = append(history, contextpkg.Message{Role: "user", Content: toolBudgetPrompt})
+			tools = nil
+		}
+
+		reply, err := caller.ChatWithTools(history, tools)
+		if err != nil {
+			return "", err
+		}
+		if len(reply.ToolCalls) == 0 || tools == nil {
+			if strings.TrimSpace(reply.Content) == "" {
+				return "", fmt.Errorf("empty response from LLM")
+			}
+			return reply.Content, nil
+		}
+
+		history = append(history, reply)
+		for _, call := range reply.ToolCalls {
+			logrus.Infof("Tool call: %s %s", call.Function.Name, call.Function.Arguments)
+			history = append(history, contextpkg.Message{
+				Role:       "tool",
+				Content:    toolbox.Execute(call),
+				ToolName:   call.Function.Name,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+	return "", fmt.Errorf("agent stopped without an answer") // Unreachable: the last round has no tools
+}
+
+// answerWithTools answers a conversation through RunAgent with the repository toolbox.
+// ok is false when tools are disabled, there is no repository, or the model cannot
+// call tools; the caller then answers without them.
+func answerWithTools(task models.Task, messages []contextpkg.Message) (response string, ok bool, err error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv(NoToolsEnv)); disabled {
+		return "", false, nil
+	}
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", false, nil
+	}
+	toolbox, err := agent.NewToolbox(repoPath)
+	if err != nil {
+		return "", false, nil
+	}
+
+	opts := AgentOptions{}
+	if n, err := strconv.Atoi(os.Getenv(AgentMaxStepsEnv)); err == nil && n > 0 {
+		opts.MaxSteps = n
+	}
+	withPrompt := append([]contextpkg.Message{{Role: "system", Content: toolSystemPrompt}}, messages...)
+
+	response, err = RunAgent(clientFor(task), withPrompt, toolbox, opts)
+	if errors.Is(err, ErrToolsUnsupported) {
+		logrus.Infof("Answering without repository tools: %v", err)
+		return "", false, nil
+	}
+	return response, true, err
+}
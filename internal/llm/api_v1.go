@@ -0,0 +1,398 @@
+// internal/llm/api_v1.go
+
+package llm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// APIVersion is the version of the /v1 API reported in its OpenAPI document.
+const APIVersion = "1.0.0"
+
+// Error codes returned in APIError.Code.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeNotAGitRepo      = "not_a_git_repo"
+	CodeModelUnavailable = "model_unavailable"
+	CodeLLMTimeout       = "llm_timeout"
+	CodeInternal         = "internal"
+)
+
+// APIError is the error body of every /v1 response that fails.
+type APIError struct {
+	Status    int    `json:"-"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (e *APIError) Error() string { return e.Code + ": " + e.Message }
+
+func newAPIError(status int, code, format string, args ...any) *APIError {
+	return &APIError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// classifyError maps an error from the handlers below to its status and code.
+func classifyError(err error) *APIError {
+	var apiErr *APIError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.As(err, &tooLarge):
+		return newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "request body exceeds %d bytes", MaxRequestBodyBytes)
+	case errors.Is(err, ErrLLMTimeout):
+		return newAPIError(http.StatusGatewayTimeout, CodeLLMTimeout, "%v", err)
+	case errors.Is(err, ErrModelUnavailable):
+		return newAPIError(http.StatusServiceUnavailable, CodeModelUnavailable, "%v", err)
+	case errors.Is(err, utils.ErrNotGitRepo):
+		return newAPIError(http.StatusConflict, CodeNotAGitRepo, "the server is not running inside a git repository")
+	case errors.Is(err, os.ErrNotExist):
+		return newAPIError(http.StatusNotFound, CodeNotFound, "%v", err)
+	default:
+		return newAPIError(http.StatusInternalServerError, CodeInternal, "%v", err)
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Request IDs
+// ------------------------------------------------------------------------------
+
+type requestIDKey struct{}
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// withRequestID echoes a well-formed client X-Request-ID or assigns a new one.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+func writeAPIError(w http.ResponseWriter, r *http.Request, apiErr *APIError) {
+	body := *apiErr
+	body.RequestID = requestID(r)
+	log.Printf("HTTP %d %s [%s]: %s", body.Status, body.Code, body.RequestID, body.Message)
+	writeJSON(w, body.Status, V1ErrorResponse{Error: body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if data, err := utils.MarshalJSON(v); err == nil {
+		w.Write([]byte(data))
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Routing
+// ------------------------------------------------------------------------------
+
+// route is one /v1 operation. The OpenAPI document is generated from the same table.
+type route struct {
+	method      string
+	path        string // ServeMux pattern, e.g. /v1/conversations/{id}
+	operationID string
+	summary     string
+	status      int
+	request     reflect.Type // nil when the operation takes no body
+	response    reflect.Type // nil for 204 responses
+	handler     http.HandlerFunc
+}
+
+// noBody marks operations without a request or response body.
+type noBody struct{}
+
+// endpoint builds a route whose handler decodes Req, calls fn and encodes Resp.
+func endpoint[Req, Resp any](method, path, operationID, summary string, fn func(*http.Request, Req) (Resp, error)) route {
+	rt := route{method: method, path: path, operationID: operationID, summary: summary, status: http.StatusOK}
+	if t := reflect.TypeOf((*Req)(nil)).Elem(); t != reflect.TypeOf(noBody{}) {
+		rt.request = t
+	}
+	if t := reflect.TypeOf((*Resp)(nil)).Elem(); t != reflect.TypeOf(noBody{}) {
+		rt.response = t
+	} else {
+		rt.status = http.StatusNoContent
+	}
+
+	rt.handler = func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if rt.request != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				if errors.Is(err, io.EOF) {
+					err = newAPIError(http.StatusBadRequest, CodeInvalidRequest, "request body is required")
+				} else if apiErr := classifyError(err); apiErr.Code != CodePayloadTooLarge {
+					err = newAPIError(http.StatusBadRequest, CodeInvalidRequest, "invalid JSON body: %v", err)
+				}
+				writeAPIError(w, r, classifyError(err))
+				return
+			}
+		}
+
+		resp, err := fn(r, req)
+		if err != nil {
+			writeAPIError(w, r, classifyError(err))
+			return
+		}
+		if rt.response == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, rt.status, resp)
+	}
+	return rt
+}
+
+// registerV1 mounts the /v1 API. Operations sharing a path are dispatched by method so
+// unknown paths and wrong methods get structured errors too.
+func registerV1(router *http.ServeMux) {
+	byPath := make(map[string][]route)
+	var paths []string
+	for _, rt := range v1Routes() {
+		if _, ok := byPath[rt.path]; !ok {
+			paths = append(paths, rt.path)
+		}
+		byPath[rt.path] = append(byPath[rt.path], rt)
+	}
+
+	for _, path := range paths {
+		routes := byPath[path]
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			var allowed []string
+			for _, rt := range routes {
+				if rt.method == r.Method {
+					rt.handler(w, r)
+					return
+				}
+				allowed = append(allowed, rt.method)
+			}
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeAPIError(w, r, newAPIError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method %s not allowed; use %s", r.Method, strings.Join(allowed, ", ")))
+		})
+	}
+	router.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no such endpoint %s", r.URL.Path))
+	})
+}
+
+// ------------------------------------------------------------------------------
+// Resources
+// ------------------------------------------------------------------------------
+
+// V1 request and response bodies.
+type (
+	V1ErrorResponse struct {
+		Error APIError `json:"error"`
+	}
+
+	V1MessageRequest struct {
+		Input string `json:"input"`
+		DCE   bool   `json:"dce,omitempty"` // Route through the Dynamic Context Engine
+	}
+
+	V1MessageResponse struct {
+		ConversationID string `json:"conversation_id"`
+		Reply          string `json:"reply"`
+	}
+
+	V1SuggestionsResponse struct {
+		New         []dce.Suggestion `json:"new"`
+		Suggestions []dce.Suggestion `json:"suggestions"`
+	}
+
+	V1SnoozeRequest struct {
+		Duration string `json:"duration,omitempty"` // Go duration, defaults to 1h
+	}
+
+	V1SummaryResponse struct {
+		Summary string `json:"summary"`
+	}
+
+	V1DraftRequest struct {
+		Messages []contextpkg.Message `json:"messages"`
+	}
+
+	V1Draft struct {
+		Branch   string               `json:"branch"`
+		Commit   string               `json:"commit"`
+		Messages []contextpkg.Message `json:"messages"`
+	}
+
+	V1ModelList struct {
+		Models []models.Model `json:"models"`
+		Active string         `json:"active"`
+	}
+
+	V1ActiveModel struct {
+		Model string `json:"model"`
+	}
+)
+
+func v1Routes() []route {
+	return []route{
+		endpoint("POST", "/v1/conversations/{id}/messages", "sendMessage",
+			"Send a message to a conversation and return the assistant reply",
+			func(r *http.Request, req V1MessageRequest) (V1MessageResponse, error) {
+				id := r.PathValue("id")
+				if strings.TrimSpace(req.Input) == "" {
+					return V1MessageResponse{}, newAPIError(http.StatusBadRequest, CodeInvalidRequest, "input is required")
+				}
+				handle := HandleQuickAssist
+				if req.DCE {
+					handle = HandleDCERequest
+				}
+				reply, err := handle(id, req.Input)
+				return V1MessageResponse{ConversationID: id, Reply: reply}, err
+			}),
+		endpoint("DELETE", "/v1/conversations/{id}", "deleteConversation",
+			"Forget a conversation",
+			func(r *http.Request, _ noBody) (noBody, error) {
+				contextpkg.ConversationManagerInstance.RemoveConversation(r.PathValue("id"))
+				return noBody{}, nil
+			}),
+		endpoint("GET", "/v1/conversations/{id}/suggestions", "listSuggestions",
+			"Analyze the working tree and list the DCE suggestions for a conversation",
+			func(r *http.Request, _ noBody) (V1SuggestionsResponse, error) {
+				littleguy, err := dceSessionFor(r.PathValue("id"))
+				if err != nil {
+					return V1SuggestionsResponse{}, err
+				}
+				fresh := littleguy.CheckForQueries()
+				return V1SuggestionsResponse{New: fresh, Suggestions: littleguy.Suggestions()}, nil
+			}),
+		endpoint("POST", "/v1/conversations/{id}/suggestions/{suggestion}/snooze", "snoozeSuggestion",
+			"Hide a suggestion for a while",
+			func(r *http.Request, req V1SnoozeRequest) (noBody, error) {
+				littleguy, err := dceSessionFor(r.PathValue("id"))
+				if err != nil {
+					return noBody{}, err
+				}
+				duration := time.Hour
+				if req.Duration != "" {
+					if duration, err = time.ParseDuration(req.Duration); err != nil || duration <= 0 {
+						return noBody{}, newAPIError(http.StatusBadRequest, CodeInvalidRequest, "invalid duration %q", req.Duration)
+					}
+				}
+				if !littleguy.SnoozeSuggestion(r.PathValue("suggestion"), duration) {
+					return noBody{}, newAPIError(http.StatusNotFound, CodeNotFound, "unknown suggestion %q", r.PathValue("suggestion"))
+				}
+				return noBody{}, nil
+			}),
+		endpoint("GET", "/v1/changes/summary", "summarizeChanges",
+			"Summarize the uncommitted changes",
+			func(r *http.Request, _ noBody) (V1SummaryResponse, error) {
+				summary, err := GenerateWhatSummary()
+				return V1SummaryResponse{Summary: summary}, err
+			}),
+		endpoint("GET", "/v1/drafts/{branch}/{commit}", "getDraft",
+			"Load the saved draft context for a commit (URL-encode slashes in the branch)",
+			func(r *http.Request, _ noBody) (V1Draft, error) {
+				branch, commit, err := draftKey(r)
+				if err != nil {
+					return V1Draft{}, err
+				}
+				messages, err := LoadDraftContext(branch, commit)
+				if err != nil {
+					return V1Draft{}, err
+				}
+				return V1Draft{Branch: branch, Commit: commit, Messages: messages}, nil
+			}),
+		endpoint("PUT", "/v1/drafts/{branch}/{commit}", "putDraft",
+			"Save the draft context for a commit",
+			func(r *http.Request, req V1DraftRequest) (V1Draft, error) {
+				branch, commit, err := draftKey(r)
+				if err != nil {
+					return V1Draft{}, err
+				}
+				if len(req.Messages) == 0 {
+					return V1Draft{}, newAPIError(http.StatusBadRequest, CodeInvalidRequest, "messages are required")
+				}
+				if err := SaveDraftContext(branch, commit, req.Messages); err != nil {
+					return V1Draft{}, err
+				}
+				return V1Draft{Branch: branch, Commit: commit, Messages: req.Messages}, nil
+			}),
+		endpoint("GET", "/v1/models", "listModels",
+			"List installed models and the default model",
+			func(r *http.Request, _ noBody) (V1ModelList, error) {
+				installed, err := models.List(models.Endpoint())
+				if err != nil {
+					return V1ModelList{}, fmt.Errorf("%w: %v", ErrModelUnavailable, err)
+				}
+				active, _ := GetLLMConfig()
+				return V1ModelList{Models: installed, Active: active}, nil
+			}),
+		endpoint("GET", "/v1/models/active", "getActiveModel",
+			"Return the model used by default",
+			func(r *http.Request, _ noBody) (V1ActiveModel, error) {
+				active, _ := GetLLMConfig()
+				return V1ActiveModel{Model: active}, nil
+			}),
+		endpoint("PUT", "/v1/models/active", "setActiveModel",
+			"Use a model for every task until the server stops",
+			func(r *http.Request, req V1ActiveModel) (V1ActiveModel, error) {
+				if req.Model == "" {
+					return V1ActiveModel{}, newAPIError(http.StatusBadRequest, CodeInvalidRequest, "model is required")
+				}
+				contextpkg.SetActiveModel(req.Model)
+				return req, nil
+			}),
+		endpoint("GET", "/v1/openapi.json", "getOpenAPI",
+			"This document",
+			func(r *http.Request, _ noBody) (map[string]any, error) {
+				return OpenAPIDocument(), nil
+			}),
+	}
+}
+
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+func draftKey(r *http.Request) (string, string, error) {
+	branch, commit := r.PathValue("branch"), r.PathValue("commit")
+	if branch == "" || !commitPattern.MatchString(commit) {
+		return "", "", newAPIError(http.StatusBadRequest, CodeInvalidRequest, "a branch and a commit SHA of at least 7 hex digits are required")
+	}
+	return branch, commit, nil
+}
@@ -0,0 +1,127 @@
+// internal/llm/auth.go
+
+package llm
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AllowedOriginsEnv adds exact origins to the CORS allowlist, comma separated.
+const AllowedOriginsEnv = "PRBUDDY_ALLOWED_ORIGINS"
+
+// extensionOrigins are the origins a VS Code webview or the workbench itself sends.
+// Webview origins carry a random ID, so only the scheme is matched for them.
+var extensionOrigins = []string{"vscode-webview://", "vscode-file://vscode-app"}
+
+// loopbackHosts are the only Host header values accepted, to defeat DNS rebinding.
+var loopbackHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// NewSessionToken returns a random bearer token for one serve session.
+func NewSessionToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewAPIHandler returns the serve API, the /v1 API and the legacy routes, for a server
+// on the given loopback port. Every request must carry the session token as a bearer
+// token and a loopback Host header; browser requests are further limited to the
+// extension's origins.
+func NewAPIHandler(token string, port int) http.Handler {
+	router := http.NewServeMux()
+	registerHandlers(router)
+	registerV1(router)
+	return withRequestID(secureHandler(router, token, port))
+}
+
+func secureHandler(next http.Handler, token string, port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !validHost(r.Host, port) {
+			deny(w, r, http.StatusForbidden, CodeForbidden, "Invalid Host header")
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !allowedOrigin(origin) {
+				deny(w, r, http.StatusForbidden, CodeForbidden, "Origin not allowed")
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Vary", "Origin")
+			w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
+			if r.Method == http.MethodOptions {
+				// Preflights cannot carry credentials; the real request is still authenticated.
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+RequestIDHeader)
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		if !validToken(r.Header.Get("Authorization"), token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prbuddy-go"`)
+			deny(w, r, http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deny rejects a request in the error format of the API it addressed.
+func deny(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if strings.HasPrefix(r.URL.Path, "/v1/") {
+		writeAPIError(w, r, newAPIError(status, code, "%s", message))
+		return
+	}
+	writeError(w, message, status)
+}
+
+func validHost(hostHeader string, port int) bool {
+	host, portStr, err := net.SplitHostPort(hostHeader)
+	if err != nil || portStr != strconv.Itoa(port) {
+		return false
+	}
+	for _, allowed := range loopbackHosts {
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedOrigin(origin string) bool {
+	if u, err := url.Parse(origin); err != nil || u.Scheme == "" {
+		return false
+	}
+	for _, allowed := range extensionOrigins {
+		if strings.HasSuffix(allowed, "://") && strings.HasPrefix(origin, allowed) || origin == allowed {
+			return true
+		}
+	}
+	for _, allowed := range strings.Split(os.Getenv(AllowedOriginsEnv), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func validToken(header, token string) bool {
+	presented, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
@@ -0,0 +1,112 @@
+// internal/llm/changelog.go
+
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/changelog"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxChangelogDraftLines caps how much of a stored PR draft is shown per commit.
+const maxChangelogDraftLines = 40
+
+// ClassifyCommits classifies each commit, using its Conventional Commits message when
+// possible and asking the LLM about the rest. Stored PR drafts are attached to the
+// commits and used as context for the LLM. If the LLM fails, unclassified commits
+// are listed as general changes under their original subject.
+func ClassifyCommits(commits []changelog.Commit) []changelog.Entry {
+	entries := make([]changelog.Entry, len(commits))
+	var pending []int
+
+	for i := range commits {
+		if draft, ok := FindDraftForCommit(commits[i].SHA); ok {
+			commits[i].Draft = draft
+		}
+		if entry, ok := changelog.ClassifyConventional(commits[i]); ok {
+			entries[i] = entry
+			continue
+		}
+		entries[i] = changelog.Entry{SHA: commits[i].SHA, Category: changelog.CategoryChange, Summary: commits[i].Subject}
+		pending = append(pending, i)
+	}
+
+	if len(pending) > 0 {
+		if err := classifyWithLLM(commits, entries, pending); err != nil {
+			logrus.Warnf("LLM changelog classification failed: %v", err)
+		}
+	}
+	return entries
+}
+
+// classifyWithLLM fills in entries[i] for every pending index from one LLM request.
+func classifyWithLLM(commits []changelog.Commit, entries []changelog.Entry, pending []int) error {
+	var list strings.Builder
+	for n, i := range pending {
+		c := commits[i]
+		list.WriteString(fmt.Sprintf("### c%d\nSubject: %s\n", n+1, c.Subject))
+		if c.Body != "" {
+			list.WriteString("Body:\n" + c.Body + "\n")
+		}
+		if c.Draft != "" {
+			list.WriteString("PR draft:\n" + contextpkg.TruncateDiff(c.Draft, maxChangelogDraftLines) + "\n")
+		}
+		list.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`
+Classify the following commits for a release changelog.
+
+%s
+---
+!TASK:
+1. category is one of: feature (new user-facing capability), fix (bug fix), breaking (incompatible change), change (refactor, performance, behavior tweak), chore (docs, tests, build, tooling).
+2. summary is one short, user-facing sentence fragment in imperative mood, without a trailing period.
+3. Respond with JSON only: {"entries": [{"id": "c1", "category": "feature", "summary": "add review command"}]}
+`, list.String())
+
+	response, err := clientFor(models.TaskChangelog).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a release manager writing concise, accurate release notes."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return err
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return err
+	}
+	var parsed struct {
+		Entries []struct {
+			ID       string `json:"id"`
+			Category string `json:"category"`
+			Summary  string `json:"summary"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return err
+	}
+
+	for _, e := range parsed.Entries {
+		var n int
+		if _, err := fmt.Sscanf(e.ID, "c%d", &n); err != nil || n < 1 || n > len(pending) {
+			continue
+		}
+		entry := &entries[pending[n-1]]
+		category := strings.ToLower(strings.TrimSpace(e.Category))
+		if utils.StringSliceContains(changelog.Categories, category) {
+			entry.Category = category
+		}
+		if summary := strings.TrimSpace(e.Summary); summary != "" {
+			entry.Summary = strings.TrimRight(summary, ".")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// internal/llm/commit_msg.go
+
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// ErrNothingStaged is returned when there is no staged diff to describe.
+var ErrNothingStaged = errors.New("no staged changes")
+
+// GenerateCommitMessage drafts a Conventional Commits message for the staged changes.
+// The scope is inferred from the touched package directories rather than left to the model.
+func GenerateCommitMessage() (*utils.ConventionalCommit, error) {
+	diff, err := utils.ExecGit("diff", "--cached", "--no-color")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, ErrNothingStaged
+	}
+	names, err := utils.ExecGit("diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	files := utils.SplitLines(names)
+	scope := utils.InferCommitScope(files)
+
+	scopeHint := "Leave the scope out; the changes span several areas."
+	if scope != "" {
+		scopeHint = fmt.Sprintf("The scope is %q.", scope)
+	}
+
+	prompt := fmt.Sprintf(`
+Write a commit message for the following staged changes.
+
+**Files:**
+%s
+
+**Code Changes:**
+%s
+
+!TASK:
+1. Follow the Conventional Commits specification. %s
+2. type is one of: %s.
+3. subject is imperative mood, lowercase, no trailing period, and short enough that the header "type(scope): subject" fits in %d characters.
+4. body explains what changed and why in a few short wrapped lines; leave it empty for trivial changes.
+5. Set breaking to true only if the change breaks a public API or behavior.
+6. Respond with JSON only: {"type": "...", "subject": "...", "body": "...", "breaking": false}
+`, strings.Join(files, "\n"), compactDiff(diff), scopeHint, strings.Join(utils.ConventionalCommitTypes, ", "), utils.MaxCommitHeaderLength)
+
+	response, err := clientFor(models.TaskCommitMsg).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a developer who writes precise, conventional commit messages."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit message from LLM: %w", err)
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commit message response: %w", err)
+	}
+	var msg utils.ConventionalCommit
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal commit message: %w", err)
+	}
+	if strings.TrimSpace(msg.Subject) == "" {
+		return nil, fmt.Errorf("LLM returned an empty commit subject")
+	}
+
+	msg.Scope = scope
+	msg.Normalize()
+	return &msg, nil
+}
+
+// ShouldPrepareCommitMessage decides whether the prepare-commit-msg hook should draft a
+// message. source is the hook's second argument; existing is the current message file.
+// Merges, squashes, amends/reuses (-c/-C/--amend) and messages the user already
+// supplied (-m, -F or a template with content) are left alone. Comment lines use
+// core.commentChar, and everything below the scissors line of `git commit -v` is ignored.
+func ShouldPrepareCommitMessage(source, existing string) bool {
+	switch source {
+	case "message", "merge", "squash", "commit":
+		return false
+	}
+	prefixes := commentPrefixes()
+	for _, line := range strings.Split(existing, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		comment, ok := cutCommentPrefix(trimmed, prefixes)
+		if !ok {
+			return false
+		}
+		if strings.TrimSpace(comment) == scissors {
+			break
+		}
+	}
+
+	for _, marker := range []string{"MERGE_HEAD", "CHERRY_PICK_HEAD", "REVERT_HEAD"} {
+		path, err := utils.GetGitPath(marker)
+		if err != nil {
+			return false
+		}
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// scissors is the line, after the comment character, below which git discards the
+// message file; `git commit -v` puts the diff there.
+const scissors = "------------------------ >8 ------------------------"
+
+// autoCommentChars are the characters git chooses from when core.commentChar is "auto".
+const autoCommentChars = "#;@!$%^&|:"
+
+// commentPrefixes returns the strings that may start a comment line in the commit
+// message file, following core.commentChar and defaulting to "#".
+func commentPrefixes() []string {
+	value, err := utils.ExecGit("config", "core.commentChar")
+	switch {
+	case err != nil || value == "":
+		return []string{"#"}
+	case value == "auto":
+		return strings.Split(autoCommentChars, "")
+	default:
+		return []string{value}
+	}
+}
+
+// cutCommentPrefix returns line without its comment prefix, and whether it had one.
+func cutCommentPrefix(line string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+// PrepareCommitMessageFile writes a generated message into the commit message file,
+// keeping git's comment lines below it. It reports whether the file was changed.
+func PrepareCommitMessageFile(path, source string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read commit message file: %w", err)
+	}
+	if !ShouldPrepareCommitMessage(source, string(existing)) {
+		return false, nil
+	}
+
+	msg, err := GenerateCommitMessage()
+	if err != nil {
+		if errors.Is(err, ErrNothingStaged) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	content := msg.String()
+	if comments := strings.TrimLeft(string(existing), "\n"); comments != "" {
+		content += "\n" + comments
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	return true, nil
+}
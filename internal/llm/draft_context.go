@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
@@ -63,6 +66,38 @@ func LoadDraftContext(branchName, commitHash string) ([]contextpkg.Message, erro
 	return context, nil
 }
 
+// SavedDraft identifies a draft context stored under .git/pr_buddy_db.
+type SavedDraft struct {
+	Branch  string    `json:"branch"` // Sanitized branch name, as used in the directory name
+	Commit  string    `json:"commit"` // Abbreviated (7 character) commit hash
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// ListDrafts returns the saved draft contexts, most recent first.
+func ListDrafts() ([]SavedDraft, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository path: %w", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(repoPath, ".git", "pr_buddy_db", "*-*", "draft_context.json"))
+	if err != nil {
+		return nil, err
+	}
+	var drafts []SavedDraft
+	for _, match := range matches {
+		dir := filepath.Base(filepath.Dir(match))
+		sep := strings.LastIndex(dir, "-")
+		info, err := os.Stat(match)
+		if err != nil || len(dir)-sep-1 != 7 {
+			continue
+		}
+		drafts = append(drafts, SavedDraft{Branch: dir[:sep], Commit: dir[sep+1:], SavedAt: info.ModTime()})
+	}
+	sort.SliceStable(drafts, func(i, j int) bool { return drafts[i].SavedAt.After(drafts[j].SavedAt) })
+	return drafts, nil
+}
+
 // saveFile writes content to a specified file within a directory
 func saveFile(dir, filename, content string) error {
 	path := filepath.Join(dir, filename)
@@ -72,3 +107,43 @@ func saveFile(dir, filename, content string) error {
 
 	return nil
 }
+
+// FindDraftForCommit returns the most recent assistant message stored for a commit under
+// .git/pr_buddy_db/<branch>-<sha7>, regardless of branch. The second result reports
+// whether a draft was found.
+func FindDraftForCommit(commitHash string) (string, bool) {
+	if len(commitHash) < 7 {
+		return "", false
+	}
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", false
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(repoPath, ".git", "pr_buddy_db",
+		"*-"+commitHash[:7], "draft_context.json"))
+	// The same commit may have drafts on several branches; prefer the latest saved.
+	modTimes := make(map[string]time.Time, len(matches))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil {
+			modTimes[match] = info.ModTime()
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return modTimes[matches[i]].After(modTimes[matches[j]]) })
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			continue
+		}
+		var messages []contextpkg.Message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			continue
+		}
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "assistant" && strings.TrimSpace(messages[i].Content) != "" {
+				return messages[i].Content, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,20 @@
+// internal/llm/edit_format.go
+
+package llm
+
+import "github.com/soyuz43/prbuddy-go/internal/contextpkg"
+
+// editFormatPrompt asks for code changes in a form internal/patch can apply.
+const editFormatPrompt = "When you suggest changes to files in the repository, give them as search/replace blocks so they can be applied automatically. " +
+	"Put the repo-relative file path on its own line, followed by:\n" +
+	"<<<<<<< SEARCH\n" +
+	"the exact lines to replace, copied from the file, with enough context to be unique\n" +
+	"=======\n" +
+	"the new lines\n" +
+	">>>>>>> REPLACE\n" +
+	"Use an empty SEARCH section to create a new file. A unified diff in a ```diff block is also accepted."
+
+// withEditFormat prepends editFormatPrompt to a quickassist or DCE conversation.
+func withEditFormat(messages []contextpkg.Message) []contextpkg.Message {
+	return append([]contextpkg.Message{{Role: "system", Content: editFormatPrompt}}, messages...)
+}
@@ -0,0 +1,36 @@
+// internal/llm/explain.go
+
+package llm
+
+import (
+	"fmt"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+)
+
+// ExplainFunction asks the LLM to explain a function for editor integrations.
+// name is the display name (Type.Method for methods) and file is repo-relative.
+func ExplainFunction(name, file, source string) (string, error) {
+	prompt := fmt.Sprintf(`
+Explain the Go function %s from %s.
+
+**Source:**
+%s
+
+!TASK:
+1. Start with one sentence saying what the function is for.
+2. Walk through its logic step by step, including error handling and edge cases.
+3. Point out side effects (I/O, shared state, goroutines) and anything surprising.
+4. Be concise and use markdown; do not repeat the source.
+`, name, file, "```go\n"+source+"\n```")
+
+	response, err := clientFor(models.TaskChat).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a senior Go engineer explaining code to a colleague."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get explanation from LLM: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,163 @@
+// internal/llm/guarded_client.go
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/agent"
+	"github.com/soyuz43/prbuddy-go/internal/audit"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/metrics"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+)
+
+// Usage is what the model reported about a single call.
+type Usage struct {
+	Model              string
+	Endpoint           string
+	PromptTokens       int
+	CompletionTokens   int
+	TotalDuration      time.Duration
+	LoadDuration       time.Duration
+	PromptEvalDuration time.Duration
+	EvalDuration       time.Duration
+}
+
+// usageReporter is implemented by clients that can report usage, such as DefaultLLMClient.
+// Other clients (e.g. test fakes) are audited without model or token details.
+type usageReporter interface {
+	chatWithUsage(messages []contextpkg.Message) (string, Usage, error)
+	streamWithUsage(messages []contextpkg.Message, onDone func(Usage)) (<-chan string, error)
+}
+
+// guardedClient wraps every LLMClient: it redacts outgoing messages and appends an
+// audit entry for each call.
+type guardedClient struct {
+	inner LLMClient
+}
+
+func (c *guardedClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	messages = redactMessages(messages)
+	start := time.Now()
+
+	var response string
+	var usage Usage
+	var err error
+	if reporter, ok := c.inner.(usageReporter); ok {
+		response, usage, err = reporter.chatWithUsage(messages)
+	} else {
+		response, err = c.inner.GetChatResponse(messages)
+	}
+
+	recordCall(messages, response, usage, false, time.Since(start), err)
+	return response, err
+}
+
+func (c *guardedClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	messages = redactMessages(messages)
+	start := time.Now()
+
+	var usage Usage
+	var stream <-chan string
+	var err error
+	if reporter, ok := c.inner.(usageReporter); ok {
+		stream, err = reporter.streamWithUsage(messages, func(u Usage) { usage = u })
+	} else {
+		stream, err = c.inner.StreamChatResponse(messages)
+	}
+	if err != nil {
+		recordCall(messages, "", usage, true, time.Since(start), err)
+		return nil, err
+	}
+
+	// Relay chunks so the full response can be audited once the stream ends.
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		var response strings.Builder
+		for chunk := range stream {
+			response.WriteString(chunk)
+			out <- chunk
+		}
+		recordCall(messages, response.String(), usage, true, time.Since(start), nil)
+	}()
+	return out, nil
+}
+
+// recordCall updates the process metrics and appends an audit entry. Audit failures are
+// logged, never returned, so an unwritable log does not break the feature that made the call.
+func recordCall(messages []contextpkg.Message, response string, usage Usage, stream bool, latency time.Duration, callErr error) {
+	command := audit.Command()
+	metrics.Record(metrics.Call{
+		Command:            command,
+		Model:              usage.Model,
+		PromptTokens:       usage.PromptTokens,
+		CompletionTokens:   usage.CompletionTokens,
+		LoadDuration:       usage.LoadDuration,
+		PromptEvalDuration: usage.PromptEvalDuration,
+		EvalDuration:       usage.EvalDuration,
+		Latency:            latency,
+		Failed:             callErr != nil,
+	})
+
+	entry := audit.Entry{
+		Command:          command,
+		Model:            usage.Model,
+		Endpoint:         usage.Endpoint,
+		Stream:           stream,
+		PromptHash:       audit.HashMessages(messages),
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		LatencyMS:        latency.Milliseconds(),
+		LoadMS:           usage.LoadDuration.Milliseconds(),
+		PromptEvalMS:     usage.PromptEvalDuration.Milliseconds(),
+		EvalMS:           usage.EvalDuration.Milliseconds(),
+		Redactions:       redact.Default().Applied(messages),
+	}
+	if response != "" {
+		entry.ResponseHash = audit.Hash(response)
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+	if audit.PayloadsEnabled() {
+		entry.Messages, entry.Response = messages, response
+	}
+
+	if err := audit.Append(entry); err != nil {
+		logrus.Warnf("Audit entry not written: %v", err)
+	}
+}
+
+func (c *guardedClient) ChatWithTools(messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, error) {
+	messages = redactMessages(messages)
+	start := time.Now()
+
+	var reply contextpkg.Message
+	var usage Usage
+	var err error
+	if reporter, ok := c.inner.(toolUsageReporter); ok {
+		reply, usage, err = reporter.toolChatWithUsage(messages, tools)
+	} else if caller, ok := c.inner.(ToolCaller); ok {
+		reply, err = caller.ChatWithTools(messages, tools)
+	} else {
+		return contextpkg.Message{}, ErrToolsUnsupported // Nothing was sent, so nothing to audit
+	}
+
+	recordCall(messages, toolReplyText(reply), usage, false, time.Since(start), err)
+	return reply, err
+}
+
+// toolReplyText renders a tool-calling reply for the audit log.
+func toolReplyText(reply contextpkg.Message) string {
+	var b strings.Builder
+	b.WriteString(reply.Content)
+	for _, call := range reply.ToolCalls {
+		fmt.Fprintf(&b, "\n[tool call] %s %s", call.Function.Name, call.Function.Arguments)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,171 @@
+// internal/llm/guide.go
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/guide"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+)
+
+const (
+	// maxGuideFunctions caps the function signatures shown per package, most-called first.
+	maxGuideFunctions = 40
+	// maxGuideTypes caps the exported types shown per package.
+	maxGuideTypes = 15
+	// maxGuideFlows caps the cross-package call edges shown for the overview.
+	maxGuideFlows = 20
+)
+
+// SummarizeGuide fills in the guide's package summaries and overview hierarchically:
+// packages are summarized after the packages they import, with those summaries as
+// context, and the overview is written from all of them. progress, if not nil, is
+// called before each package.
+func SummarizeGuide(gd *guide.Guide, progress func(dir string)) error {
+	g := gd.Graph
+	for _, dir := range g.DependencyOrder() {
+		if progress != nil {
+			progress(dir)
+		}
+		summary, err := summarizePackage(gd, dir)
+		if err != nil {
+			return err
+		}
+		gd.Summaries[dir] = summary
+	}
+
+	var packages strings.Builder
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(&packages, "### %s (package %s)\n%s\n\n", pkg.Dir, pkg.Name, gd.Summaries[pkg.Dir])
+	}
+	var flows strings.Builder
+	for i, f := range g.Flows() {
+		if i == maxGuideFlows {
+			break
+		}
+		fmt.Fprintf(&flows, "- %s -> %s: %d call(s) via %s\n", f.From, f.To, f.Calls, strings.Join(f.Via, ", "))
+	}
+
+	prompt := fmt.Sprintf(`
+Write the architecture overview for a guide to the %s repository.
+
+**Packages:**
+%s
+**Entry points:**
+%s
+**Calls between packages:**
+%s
+!TASK:
+1. Explain in one paragraph what the project does and who uses it.
+2. Describe the main layers and which packages belong to each.
+3. Trace how control and data flow from the entry points through the packages.
+4. Point a newcomer to where to start reading.
+5. Be concise and use markdown paragraphs and lists, without headings.
+`, gd.Repo, packages.String(), entryPointList(gd.EntryPoints), orNone(flows.String()))
+
+	response, err := clientFor(models.TaskSummary).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a senior engineer writing an onboarding guide to a codebase."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get guide overview from LLM: %w", err)
+	}
+	gd.Overview = strings.TrimSpace(response)
+	return nil
+}
+
+// summarizePackage asks the LLM to describe one package from its declarations, entry
+// points and the summaries of the repository packages it imports.
+func summarizePackage(gd *guide.Guide, dir string) (string, error) {
+	g := gd.Graph
+	pkg, _ := g.Package(dir)
+
+	var deps strings.Builder
+	for _, dep := range pkg.Imports {
+		fmt.Fprintf(&deps, "- %s: %s\n", dep, strings.ReplaceAll(gd.Summaries[dep], "\n", " "))
+	}
+
+	var types strings.Builder
+	for _, t := range gd.KeyTypes(dir, maxGuideTypes) {
+		fmt.Fprintf(&types, "- %s %s", t.Kind, t.Name)
+		if len(t.Members) > 0 {
+			fmt.Fprintf(&types, " {%s}", strings.Join(t.Members, "; "))
+		}
+		fmt.Fprintf(&types, ", %d method(s)\n", t.Methods)
+	}
+
+	// Most-called functions first, then the rest in file order.
+	var functions strings.Builder
+	listed := make(map[int]bool)
+	order := make([]int, 0, len(pkg.Functions))
+	for _, r := range gd.TopFunctions(dir, maxGuideFunctions) {
+		order = append(order, r.Index)
+		listed[r.Index] = true
+	}
+	for _, i := range pkg.Functions {
+		if !listed[i] {
+			order = append(order, i)
+		}
+	}
+	for n, i := range order {
+		if n == maxGuideFunctions {
+			fmt.Fprintf(&functions, "... and %d more\n", len(order)-n)
+			break
+		}
+		fn := g.Functions[i]
+		fmt.Fprintf(&functions, "- %s (%d caller(s))\n", fn.Signature, len(g.Callers(i)))
+	}
+
+	prompt := fmt.Sprintf(`
+Summarize the Go package %s (package %s) for a guide to the %s repository.
+
+**Files:** %s
+**External imports:** %s
+**Repository packages it imports:**
+%s
+**Exported types:**
+%s
+**Functions:**
+%s
+**Entry points:**
+%s
+!TASK:
+1. In two to four sentences, state the package's responsibility.
+2. Name its key types and functions and how they work together.
+3. Say what it relies on from the packages it imports.
+4. Respond with plain markdown paragraphs, without headings.
+`, dir, pkg.Name, gd.Repo, strings.Join(pkg.Files, ", "), orNone(strings.Join(pkg.External, ", ")),
+		orNone(deps.String()), orNone(types.String()), orNone(functions.String()), entryPointList(gd.EntryPointsIn(dir)))
+
+	response, err := clientFor(models.TaskSummary).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a senior engineer writing an onboarding guide to a codebase."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get summary of %s from LLM: %w", dir, err)
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// entryPointList renders entry points one per line for a prompt.
+func entryPointList(entryPoints []guide.EntryPoint) string {
+	var b strings.Builder
+	for _, ep := range entryPoints {
+		fmt.Fprintf(&b, "- %s %s -> %s (%s:%d)", ep.Kind, ep.Name, ep.Target, ep.File, ep.Line)
+		if ep.Detail != "" {
+			b.WriteString(": " + ep.Detail)
+		}
+		b.WriteString("\n")
+	}
+	return orNone(b.String())
+}
+
+func orNone(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "none\n"
+	}
+	return text
+}
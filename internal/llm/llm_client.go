@@ -9,14 +9,15 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
 	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/models"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
@@ -33,51 +34,69 @@ type LLMClient interface {
 }
 
 // DefaultLLMClient implements the LLMClient interface using Ollama’s /api/chat.
-type DefaultLLMClient struct{}
+// Task selects the model through the per-task routing of ModelFor.
+type DefaultLLMClient struct {
+	Task models.Task
+}
+
+// chatOptions are the model options sent with every chat request. They are part of
+// the response cache key.
+var chatOptions = map[string]interface{}{
+	"num_ctx": 8192,
+}
 
 //------------------------------------------------------------------------------
 // NON-STREAMING METHOD: GetChatResponse
 //------------------------------------------------------------------------------
 
 func (c *DefaultLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
-	model, endpoint := GetLLMConfig()
+	response, _, err := c.chatWithUsage(messages)
+	return response, err
+}
+
+// chatWithUsage performs a non-streaming chat and returns what Ollama reported about it.
+func (c *DefaultLLMClient) chatWithUsage(messages []contextpkg.Message) (string, Usage, error) {
+	model, endpoint := ModelFor(c.Task)
+	usage := Usage{Model: model, Endpoint: endpoint}
 
 	// Request body: force "stream": false
 	requestBody := map[string]interface{}{
 		"model":    model,
 		"messages": messages,
-		"options": map[string]interface{}{
-			"num_ctx": 8192,
-		},
-		"stream": false,
+		"options":  chatOptions,
+		"stream":   false,
 	}
 
 	jsonBody, err := utils.MarshalJSON(requestBody)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to marshal request body")
+		return "", usage, errors.Wrap(err, "failed to marshal request body")
 	}
 
-	resp, err := http.Post(endpoint+"/api/chat", "application/json", strings.NewReader(jsonBody))
+	resp, err := chatHTTPClient().Post(endpoint+"/api/chat", "application/json", strings.NewReader(jsonBody))
 	if err != nil {
-		return "", errors.Wrap(err, "failed to send POST request to LLM")
+		return "", usage, transportError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("LLM responded with status code %d", resp.StatusCode)
+		return "", usage, statusError(resp.StatusCode, model)
 	}
 
 	var llmResp LLMResponse
 	if err := json.NewDecoder(resp.Body).Decode(&llmResp); err != nil {
-		return "", errors.Wrap(err, "failed to decode LLM response")
+		if transportErr := transportError(err); errors.Is(transportErr, ErrLLMTimeout) {
+			return "", usage, transportErr
+		}
+		return "", usage, errors.Wrap(err, "failed to decode LLM response")
 	}
+	usage = llmResp.usage(model, endpoint)
 
 	if llmResp.Message.Content == "" {
-		return "", fmt.Errorf("empty response from LLM")
+		return "", usage, fmt.Errorf("empty response from LLM")
 	}
 
 	logrus.Info("Received response from LLM successfully (non-stream).")
-	return llmResp.Message.Content, nil
+	return llmResp.Message.Content, usage, nil
 }
 
 //------------------------------------------------------------------------------
@@ -87,15 +106,19 @@ func (c *DefaultLLMClient) GetChatResponse(messages []contextpkg.Message) (strin
 // StreamChatResponse reads lines from Ollama’s /api/chat as soon as they arrive.
 // Each line is expected to be a complete JSON object. When "done" = true, we stop.
 func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
-	model, endpoint := GetLLMConfig()
+	return c.streamWithUsage(messages, func(Usage) {})
+}
+
+// streamWithUsage streams like StreamChatResponse and calls onDone with the final
+// chunk's statistics before the channel is closed.
+func (c *DefaultLLMClient) streamWithUsage(messages []contextpkg.Message, onDone func(Usage)) (<-chan string, error) {
+	model, endpoint := ModelFor(c.Task)
 
 	reqBody := map[string]interface{}{
 		"model":    model,
 		"messages": messages,
 		"stream":   true,
-		"options": map[string]interface{}{
-			"num_ctx": 8192,
-		},
+		"options":  chatOptions,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -112,11 +135,11 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 	// Execute HTTP request
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, transportError(err)
 	}
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return nil, fmt.Errorf("non-200 status code: %d", resp.StatusCode)
+		return nil, statusError(resp.StatusCode, model)
 	}
 
 	outChan := make(chan string)
@@ -125,6 +148,9 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 		defer resp.Body.Close()
 		defer close(outChan)
 
+		usage := Usage{Model: model, Endpoint: endpoint}
+		defer func() { onDone(usage) }()
+
 		scanner := bufio.NewScanner(resp.Body)
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -139,8 +165,9 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 				continue
 			}
 
-			// If "done" is true, streaming has ended
+			// If "done" is true, streaming has ended; the final chunk carries the statistics
 			if chunk.Done {
+				usage = chunk.usage(model, endpoint)
 				break
 			}
 
@@ -168,6 +195,31 @@ type LLMResponse struct {
 	Message struct {
 		Content string `json:"content"`
 	} `json:"message"`
+	OllamaStats
+}
+
+// OllamaStats are the counters Ollama reports on a final response. Durations are in nanoseconds.
+type OllamaStats struct {
+	TotalDuration      int64 `json:"total_duration,omitempty"`
+	LoadDuration       int64 `json:"load_duration,omitempty"`
+	PromptEvalCount    int   `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int   `json:"eval_count,omitempty"`
+	EvalDuration       int64 `json:"eval_duration,omitempty"`
+}
+
+// usage converts the counters into a Usage for the given model and endpoint.
+func (s OllamaStats) usage(model, endpoint string) Usage {
+	return Usage{
+		Model:              model,
+		Endpoint:           endpoint,
+		PromptTokens:       s.PromptEvalCount,
+		CompletionTokens:   s.EvalCount,
+		TotalDuration:      time.Duration(s.TotalDuration),
+		LoadDuration:       time.Duration(s.LoadDuration),
+		PromptEvalDuration: time.Duration(s.PromptEvalDuration),
+		EvalDuration:       time.Duration(s.EvalDuration),
+	}
 }
 
 // OllamaStreamChunk is used during streaming (partial response).
@@ -178,15 +230,34 @@ type OllamaStreamChunk struct {
 		Content string   `json:"content,omitempty"`
 		Images  []string `json:"images,omitempty"`
 	} `json:"message,omitempty"`
-	Done bool `json:"done,omitempty"`
+	Done        bool `json:"done,omitempty"`
+	OllamaStats      // Set on the final chunk
 }
 
-// llmClient is the global instance implementing LLMClient.
-var llmClient LLMClient = &DefaultLLMClient{}
+// llmClient is the global instance implementing LLMClient. Every client is wrapped so
+// secrets are redacted before messages leave the process and each call is audited.
+var llmClient LLMClient = &guardedClient{inner: &DefaultLLMClient{}}
+
+func init() {
+	dce.SetPlannerClient(llmClient)
+}
 
 // SetLLMClient allows injecting a different LLMClient (useful for testing or future extensions).
+// The DCE task planner shares the same client.
 func SetLLMClient(client LLMClient) {
-	llmClient = client
+	llmClient = &guardedClient{inner: client}
+	dce.SetPlannerClient(llmClient)
+}
+
+// clientFor returns the client to use for task. The default client is routed to the
+// task's model; an injected client is used as is.
+func clientFor(task models.Task) LLMClient {
+	if guarded, ok := llmClient.(*guardedClient); ok {
+		if _, ok := guarded.inner.(*DefaultLLMClient); ok {
+			return &guardedClient{inner: &DefaultLLMClient{Task: task}}
+		}
+	}
+	return llmClient
 }
 
 //------------------------------------------------------------------------------
@@ -213,20 +284,26 @@ func HandleQuickAssist(conversationID, input string) (string, error) {
 	conv.AddMessage("user", input)
 
 	// 2) Build final context for LLM
-	context := conv.BuildContext()
+	context := withEditFormat(conv.BuildContext())
 
-	// 3) Stream from LLM
-	streamChan, err := llmClient.StreamChatResponse(context)
+	// 3) Let the model inspect the repository with tools when it can
+	finalResponse, usedTools, err := answerWithTools(models.TaskChat, context)
 	if err != nil {
-		return "", fmt.Errorf("failed to stream response: %w", err)
+		return "", fmt.Errorf("failed to get response from LLM: %w", err)
 	}
 
-	// 4) Collect the streaming chunks
-	var builder strings.Builder
-	for chunk := range streamChan {
-		builder.WriteString(chunk)
+	// 4) Otherwise stream from the LLM and collect the chunks
+	if !usedTools {
+		streamChan, err := clientFor(models.TaskChat).StreamChatResponse(context)
+		if err != nil {
+			return "", fmt.Errorf("failed to stream response: %w", err)
+		}
+		var builder strings.Builder
+		for chunk := range streamChan {
+			builder.WriteString(chunk)
+		}
+		finalResponse = builder.String()
 	}
-	finalResponse := builder.String()
 
 	// 5) Store assistant's final response in conversation
 	conv.AddMessage("assistant", finalResponse)
@@ -301,7 +378,7 @@ func HandleDCERequest(conversationID, input string) (string, error) {
 	}
 
 	// Augment conversation with filtered data
-	augmentedContext := dceInstance.AugmentContext(conv.BuildContext(), filteredData)
+	augmentedContext := redactMessages(dceInstance.AugmentContext(conv.BuildContext(), filteredData))
 	conv.SetMessages(augmentedContext)
 
 	// Save expanded context for debugging
@@ -313,10 +390,13 @@ func HandleDCERequest(conversationID, input string) (string, error) {
 	}
 
 	// Build final context
-	context := conv.BuildContext()
+	context := withEditFormat(conv.BuildContext())
 
-	// Retrieve response (non-streaming) from LLM
-	response, err := llmClient.GetChatResponse(context)
+	// Retrieve response (non-streaming) from LLM, letting it look further with tools
+	response, usedTools, err := answerWithTools(models.TaskChat, context)
+	if err == nil && !usedTools {
+		response, err = clientFor(models.TaskChat).GetChatResponse(context)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get response from LLM: %w", err)
 	}
@@ -347,7 +427,7 @@ You are an assistant designed to generate a detailed pull request (PR) descripti
 	conv.AddMessage("user", prompt)
 
 	// Get initial response (non-streaming)
-	response, err := llmClient.GetChatResponse(conv.BuildContext())
+	response, err := clientFor(models.TaskDraft).GetChatResponse(conv.BuildContext())
 	if err != nil {
 		return "", "", err
 	}
@@ -373,9 +453,17 @@ func GeneratePreDraftPR() (string, string, error) {
 		return "", "", errors.Wrap(err, "failed to get git diff")
 	}
 
+	return commitMsg, compactDiff(diff), nil
+}
+
+// maxPromptDiffLines caps how many diff lines are sent to the LLM for drafting.
+const maxPromptDiffLines = 1000
+
+// compactDiff shrinks a diff for prompting. PR drafts and commit messages share it
+// so both see the same view of the changes.
+func compactDiff(diff string) string {
 	// Intelligent truncation: prioritize added lines and metadata
-	truncatedDiff := contextpkg.TruncateDiff(diff, 1000) // Adjust max lines as needed
-	return commitMsg, truncatedDiff, nil
+	return contextpkg.TruncateDiff(diff, maxPromptDiffLines)
 }
 
 // GenerateDraftPR uses the LLM's chat endpoint to generate a PR draft (stateless).
@@ -397,7 +485,7 @@ func GenerateDraftPR(commitMessage, diffs string) (string, error) {
 		{Role: "user", Content: prompt},
 	}
 
-	response, err := llmClient.GetChatResponse(statelessMessages)
+	response, err := cachedChatResponse(models.TaskDraft, statelessMessages)
 	if err != nil {
 		return "", err
 	}
@@ -466,7 +554,7 @@ These are the git diffs for the repository:
 	}
 
 	// 9. Augment conversation with filtered data (this is the key DCE integration)
-	augmentedContext := dceInstance.AugmentContext(conv.BuildContext(), filteredData)
+	augmentedContext := redactMessages(dceInstance.AugmentContext(conv.BuildContext(), filteredData))
 	conv.SetMessages(augmentedContext)
 
 	// 10. Save context for debugging (optional but helpful)
@@ -478,7 +566,7 @@ These are the git diffs for the repository:
 	}
 
 	// 11. Get response from LLM with the augmented context
-	response, err := llmClient.GetChatResponse(augmentedContext)
+	response, err := clientFor(models.TaskSummary).GetChatResponse(augmentedContext)
 	if err != nil {
 		return "", fmt.Errorf("failed to get response from LLM: %w", err)
 	}
@@ -516,92 +604,82 @@ These are the git diffs for the repository:
 		{Role: "user", Content: prompt},
 	}
 
-	return llmClient.GetChatResponse(statelessMessages)
+	return cachedChatResponse(models.TaskSummary, statelessMessages)
 }
 
 // ------------------------------------------------------------------------------
 // UTILITY FUNCTIONS: LLM config resolution + model readiness
 // ------------------------------------------------------------------------------
 
-func GetLLMConfig() (string, string) {
-	endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT")
-	if endpoint == "" {
-		endpoint = "http://localhost:11434"
-	}
-
-	model := contextpkg.GetActiveModel()
-	if model != "" {
-		return model, endpoint
-	}
-
-	// Try to load available models via official endpoint
-	models, err := fetchOllamaModels(endpoint)
-	if err == nil && len(models) > 0 {
-		latest := models[0]
-		if name, ok := latest["name"].(string); ok {
-			contextpkg.SetActiveModel(name)
-			return name, endpoint
-		}
-	}
+// FallbackModel is pulled when no model is selected or installed.
+const FallbackModel = "qwen3"
 
-	// No models found — fallback to qwen3 and run it
-	logrus.Warn("No LLM model active or available; defaulting to 'qwen3'")
+// fallbackReadyTimeout bounds how long to wait for the fallback model to load.
+const fallbackReadyTimeout = 2 * time.Minute
 
-	// Try to pre-warm the model with a dummy chat request
-	ready := tryEnsureModelReady(endpoint, "qwen3")
-	if !ready {
-		logrus.Warn("Attempting to start Ollama model 'qwen3' manually...")
-		cmd := exec.Command("ollama", "run", "qwen3")
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Start(); err != nil {
-			logrus.Errorf("Failed to start Ollama: %v", err)
-		}
-		// Crude wait; improve with polling if needed
-		time.Sleep(3 * time.Second)
-	}
+var (
+	discoveredMu    sync.Mutex
+	discoveredModel string
+)
 
-	contextpkg.SetActiveModel("qwen3")
-	return "qwen3", endpoint
+// GetLLMConfig returns the default model and the Ollama endpoint.
+func GetLLMConfig() (string, string) {
+	return ModelFor(models.TaskDefault)
 }
 
-func fetchOllamaModels(endpoint string) ([]map[string]interface{}, error) {
-	resp, err := http.Get(endpoint + "/api/tags")
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
-	}
-	defer resp.Body.Close()
+// ModelFor resolves the model for a task, in order: the model set through the extension,
+// PRBUDDY_LLM_MODEL, the task's route or default saved with `models use`, the most recently
+// pulled model, and finally FallbackModel, pulled if necessary.
+func ModelFor(task models.Task) (string, string) {
+	endpoint := models.Endpoint()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	if model := contextpkg.GetActiveModel(); model != "" {
+		return model, endpoint
 	}
-
-	var result struct {
-		Models []map[string]interface{} `json:"models"`
+	if model := os.Getenv("PRBUDDY_LLM_MODEL"); model != "" {
+		return model, endpoint
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	cfg, err := models.LoadConfig()
+	if err != nil {
+		logrus.Warnf("Ignoring model selection: %v", err)
 	}
-	return result.Models, nil
+	if model := cfg.ModelFor(task); model != "" {
+		return model, endpoint
+	}
+	return discoverModel(endpoint), endpoint
 }
 
-// tryEnsureModelReady attempts to verify whether a model is loaded and available
-func tryEnsureModelReady(endpoint, model string) bool {
-	payload := map[string]interface{}{
-		"model": model,
-		"messages": []map[string]string{
-			{"role": "user", "content": "ping"},
-		},
-		"stream": false,
+// discoverModel picks the most recently pulled model once per process, pulling
+// FallbackModel when nothing is installed. The lock only guards the cached name, so
+// other callers are not blocked behind a download, and a failed pull is retried by
+// the next call.
+func discoverModel(endpoint string) string {
+	discoveredMu.Lock()
+	cached := discoveredModel
+	discoveredMu.Unlock()
+	if cached != "" {
+		return cached
+	}
+
+	model := FallbackModel
+	installed, err := models.List(endpoint)
+	if err == nil && len(installed) > 0 {
+		model = installed[0].Name
+	} else {
+		logrus.Warnf("No LLM model selected or installed; pulling '%s'", FallbackModel)
+		if err := models.Pull(endpoint, FallbackModel, nil); err != nil {
+			logrus.Errorf("Failed to pull %s: %v", FallbackModel, err)
+			return model
+		}
+		if err := models.WaitReady(endpoint, FallbackModel, fallbackReadyTimeout); err != nil {
+			logrus.Errorf("%v", err)
+		}
 	}
-	data, _ := json.Marshal(payload)
 
-	resp, err := http.Post(endpoint+"/api/chat", "application/json", bytes.NewReader(data))
-	if err != nil {
-		logrus.Warnf("Model readiness check failed: %v", err)
-		return false
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	if discoveredModel == "" {
+		discoveredModel = model
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == http.StatusOK
+	return discoveredModel
 }
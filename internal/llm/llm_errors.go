@@ -0,0 +1,53 @@
+// internal/llm/llm_errors.go
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	// ErrModelUnavailable means Ollama could not be reached or does not have the model.
+	ErrModelUnavailable = errors.New("model unavailable")
+	// ErrLLMTimeout means the model did not answer within the request timeout.
+	ErrLLMTimeout = errors.New("LLM request timed out")
+	// ErrToolsUnsupported means the client or model cannot call tools.
+	ErrToolsUnsupported = errors.New("model does not support tool calling")
+)
+
+// LLMTimeoutEnv overrides how long a non-streaming LLM call may take, e.g. "2m".
+const LLMTimeoutEnv = "PRBUDDY_LLM_TIMEOUT"
+
+const defaultLLMTimeout = 10 * time.Minute
+
+// chatHTTPClient is used for non-streaming calls so a stuck model fails with ErrLLMTimeout.
+func chatHTTPClient() *http.Client {
+	timeout := defaultLLMTimeout
+	if d, err := time.ParseDuration(os.Getenv(LLMTimeoutEnv)); err == nil && d > 0 {
+		timeout = d
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// transportError classifies a failed request to Ollama.
+func transportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrLLMTimeout, err)
+	}
+	return fmt.Errorf("%w: failed to reach the LLM: %v", ErrModelUnavailable, err)
+}
+
+// statusError classifies a non-200 response from Ollama; 404 means the model is not installed.
+func statusError(status int, model string) error {
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%w: model %q is not installed", ErrModelUnavailable, model)
+	}
+	return fmt.Errorf("LLM responded with status code %d", status)
+}
@@ -3,6 +3,7 @@ package llm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,9 @@ import (
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
+// MaxRequestBodyBytes bounds JSON request bodies; saved drafts are the largest payloads.
+const MaxRequestBodyBytes = 4 << 20
+
 // JSONHandler creates a handler for JSON requests/responses with unified error handling
 func JSONHandler[T any](logic func(T) (any, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -24,7 +28,13 @@ func JSONHandler[T any](logic func(T) (any, error)) http.HandlerFunc {
 
 		// Decode request
 		var req T
+		r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, fmt.Sprintf("Request body exceeds %d bytes", MaxRequestBodyBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
 			writeError(w, "Invalid request format", http.StatusBadRequest)
 			return
 		}
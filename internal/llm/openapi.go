@@ -0,0 +1,168 @@
+// internal/llm/openapi.go
+
+package llm
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPIDocument generates the OpenAPI 3 description of the /v1 API from its route
+// table, so the document cannot drift from the handlers.
+func OpenAPIDocument() map[string]any {
+	schemas := make(map[string]any)
+	errorResponse := map[string]any{
+		"description": "Error; see error.code",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(V1ErrorResponse{}), schemas)},
+		},
+	}
+
+	paths := make(map[string]any)
+	for _, rt := range v1Routes() {
+		op := map[string]any{
+			"operationId": rt.operationID,
+			"summary":     rt.summary,
+		}
+
+		var params []any
+		for _, match := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+			params = append(params, map[string]any{
+				"name": match[1], "in": "path", "required": true,
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if rt.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaFor(rt.request, schemas)},
+				},
+			}
+		}
+
+		success := map[string]any{"description": http.StatusText(rt.status)}
+		if rt.response != nil {
+			success["content"] = map[string]any{
+				"application/json": map[string]any{"schema": schemaFor(rt.response, schemas)},
+			}
+		}
+		op["responses"] = map[string]any{
+			strconv.Itoa(rt.status): success,
+			"default":               errorResponse,
+		}
+
+		item, _ := paths[rt.path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "PRBuddy-Go API",
+			"version": APIVersion,
+			"description": "Local API started by `prbuddy-go serve`. The port and the bearer token are in the " +
+				"`port` and `token` files of the prbuddy-go user cache directory. Error codes: " +
+				strings.Join([]string{CodeInvalidRequest, CodeUnauthorized, CodeForbidden, CodeNotFound,
+					CodeMethodNotAllowed, CodePayloadTooLarge, CodeNotAGitRepo, CodeModelUnavailable,
+					CodeLLMTimeout, CodeInternal}, ", ") + ".",
+		},
+		"servers": []any{map[string]any{
+			"url":       "http://localhost:{port}",
+			"variables": map[string]any{"port": map[string]any{"default": "8080"}},
+		}},
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// schemaFor describes t as JSON Schema, registering named structs in schemas.
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]any{} // Placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() || tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaFor(field.Type, schemas)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
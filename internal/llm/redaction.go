@@ -0,0 +1,28 @@
+// internal/llm/redaction.go
+
+package llm
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+)
+
+// redactMessages applies the repository's redaction rules and reports what was replaced.
+// Redacting already-redacted messages is a no-op, so context can be scrubbed early (before
+// it is logged) and again on send.
+func redactMessages(messages []contextpkg.Message) []contextpkg.Message {
+	redacted, found := redact.Default().RedactMessages(messages)
+	if len(found) == 0 {
+		return redacted
+	}
+	logrus.Warnf("Redacted before sending to the LLM: %s", redact.Summary(found))
+	for _, r := range found {
+		if r.Path != "" {
+			logrus.Infof("  %s (%s in %s)", r.Placeholder, r.Detector, r.Path)
+		} else {
+			logrus.Infof("  %s (%s)", r.Placeholder, r.Detector)
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,40 @@
+// internal/llm/response_cache.go
+
+package llm
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/cache"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+)
+
+// cachedChatResponse serves stateless prompts from the on-disk response cache. Only the
+// default Ollama client is cached: an injected client may not be deterministic for the
+// same model and messages. Interactive conversations must not use this.
+func cachedChatResponse(task models.Task, messages []contextpkg.Message) (string, error) {
+	client := clientFor(task)
+	guarded, ok := client.(*guardedClient)
+	if !ok || !cache.Enabled() {
+		return client.GetChatResponse(messages)
+	}
+	if _, ok := guarded.inner.(*DefaultLLMClient); !ok {
+		return client.GetChatResponse(messages)
+	}
+
+	model, _ := ModelFor(task)
+	key := cache.Key(model, chatOptions, messages)
+	if response, ok := cache.Get(key); ok {
+		logrus.Infof("Using cached response from %s (run with --no-cache to regenerate).", model)
+		return response, nil
+	}
+
+	response, err := client.GetChatResponse(messages)
+	if err != nil {
+		return "", err
+	}
+	if err := cache.Put(key, model, response); err != nil {
+		logrus.Warnf("Failed to cache LLM response: %v", err)
+	}
+	return response, nil
+}
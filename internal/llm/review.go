@@ -0,0 +1,126 @@
+// internal/llm/review.go
+
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/review"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxReviewBatchLines caps the annotated diff lines sent to the LLM in one request.
+// Larger diffs are reviewed file by file in several batches.
+const maxReviewBatchLines = 800
+
+// ReviewDiff asks the LLM to review a unified diff and returns findings that were
+// validated against the diff's line numbers.
+func ReviewDiff(diff string) (*review.Report, error) {
+	files := utils.ParseUnifiedDiff(diff)
+
+	var findings []review.Finding
+	for _, batch := range reviewBatches(files) {
+		batchFindings, err := reviewBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, batchFindings...)
+	}
+
+	report := review.Validate(findings, files)
+	for _, d := range report.Dropped {
+		logrus.Infof("Dropped review finding for %s:%d: %s", d.Finding.File, d.Finding.StartLine, d.Reason)
+	}
+	return report, nil
+}
+
+// reviewBatches groups the annotated file diffs so each batch stays under maxReviewBatchLines.
+// A single oversized file still gets its own batch.
+func reviewBatches(files []utils.FileDiff) []string {
+	var batches []string
+	var current strings.Builder
+	currentLines := 0
+
+	for _, fd := range files {
+		if fd.IsDeleted() || len(fd.Hunks) == 0 {
+			continue
+		}
+		annotated := annotateFileDiff(fd)
+		lines := strings.Count(annotated, "\n")
+		if currentLines > 0 && currentLines+lines > maxReviewBatchLines {
+			batches = append(batches, current.String())
+			current.Reset()
+			currentLines = 0
+		}
+		current.WriteString(annotated)
+		currentLines += lines
+	}
+	if currentLines > 0 {
+		batches = append(batches, current.String())
+	}
+	return batches
+}
+
+// annotateFileDiff renders a file's hunks with new-side line numbers so the model can
+// anchor findings precisely. Removed lines carry no number since they cannot be commented on.
+func annotateFileDiff(fd utils.FileDiff) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("=== %s ===\n", fd.Path()))
+	for _, h := range fd.Hunks {
+		b.WriteString(h.Header + "\n")
+		line := h.NewStart
+		for _, l := range h.Lines {
+			switch {
+			case strings.HasPrefix(l, "+"), strings.HasPrefix(l, " "):
+				b.WriteString(fmt.Sprintf("%5d %s\n", line, l))
+				line++
+			default:
+				b.WriteString(fmt.Sprintf("%5s %s\n", "", l))
+			}
+		}
+	}
+	return b.String()
+}
+
+// reviewBatch sends one batch to the LLM and parses its findings.
+func reviewBatch(annotatedDiff string) ([]review.Finding, error) {
+	prompt := fmt.Sprintf(`
+Review the following code changes. Each line is prefixed with its line number in the new version of the file.
+
+%s
+
+---
+!TASK:
+1. Report only real problems introduced or exposed by these changes: bugs, security issues, error handling, concurrency, performance, maintainability.
+2. Anchor every finding to line numbers shown above, on added ("+") lines where possible.
+3. Do not comment on removed lines or on code that is not shown.
+4. Respond with JSON only, matching this schema:
+{"findings": [{"file": "path/to/file.go", "start_line": 10, "end_line": 12, "severity": "error|warning|info", "category": "bug|security|error-handling|concurrency|performance|maintainability|style", "message": "what is wrong", "suggestion": "how to fix it"}]}
+5. If there is nothing worth reporting, respond with {"findings": []}.
+`, annotatedDiff)
+
+	response, err := clientFor(models.TaskReview).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a senior engineer performing a careful, precise code review."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review from LLM: %w", err)
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse review response: %w", err)
+	}
+	var parsed struct {
+		Findings []review.Finding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal review findings: %w", err)
+	}
+	return parsed.Findings, nil
+}
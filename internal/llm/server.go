@@ -9,10 +9,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/metrics"
+	"github.com/soyuz43/prbuddy-go/internal/models"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -42,30 +46,41 @@ func StartServer(cfg ServerConfig) error {
 	}
 
 	port := listener.Addr().(*net.TCPAddr).Port
+	token, err := NewSessionToken()
+	if err != nil {
+		return err
+	}
+	// The token is written first so a client that sees the port can always authenticate.
+	if err := utils.WriteTokenFile(token); err != nil {
+		return fmt.Errorf("token file write failed: %w", err)
+	}
 	if err := utils.WritePortFile(port); err != nil {
 		return fmt.Errorf("port file write failed: %w", err)
 	}
 
-	router := http.NewServeMux()
-	registerHandlers(router)
-
 	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Host, port),
-		Handler: router,
+		Addr:              fmt.Sprintf("%s:%d", cfg.Host, port),
+		Handler:           NewAPIHandler(token, port),
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 
 	return manageServerLifecycle(server, listener, cfg.InactivityTimeout)
 }
 
+// registerHandlers mounts the original routes used by the VS Code extension. New
+// clients should use the /v1 API (see registerV1).
 func registerHandlers(router *http.ServeMux) {
 	router.HandleFunc("/quickassist", quickAssistHandler())
 	router.HandleFunc("/dce", dceHandler())
+	router.HandleFunc("/dce/suggestions", dceSuggestionsHandler())
+	router.HandleFunc("/dce/suggestions/snooze", dceSnoozeHandler())
 	router.HandleFunc("/quickassist/clear", quickAssistClearHandler())
 	router.HandleFunc("/extension/drafts", saveDraftHandler())
 	router.HandleFunc("/extension/drafts/load", loadDraftHandler())
 	router.HandleFunc("/what", whatHandler())
 	router.HandleFunc("/extension/models", listModelsHandler())
 	router.HandleFunc("/extension/model", setModelHandler())
+	router.HandleFunc("/metrics", metricsHandler())
 }
 
 func manageServerLifecycle(server *http.Server, listener net.Listener, timeout time.Duration) error {
@@ -95,6 +110,7 @@ func manageServerLifecycle(server *http.Server, listener net.Listener, timeout t
 	}
 
 	_ = utils.DeletePortFile()
+	_ = utils.DeleteTokenFile()
 	fmt.Println("Server shutdown completed successfully")
 	return nil
 }
@@ -128,6 +144,16 @@ type (
 		Input          string `json:"input"`
 	}
 
+	SuggestionsRequest struct {
+		ConversationID string `json:"conversationId"`
+	}
+
+	SnoozeRequest struct {
+		ConversationID string `json:"conversationId"`
+		SuggestionID   string `json:"suggestionId"`
+		Duration       string `json:"duration"` // Go duration string, defaults to 1h
+	}
+
 	ClearRequest struct {
 		ConversationID string `json:"conversationId"`
 	}
@@ -161,6 +187,109 @@ func dceHandler() http.HandlerFunc {
 	})
 }
 
+func dceSuggestionsHandler() http.HandlerFunc {
+	return JSONHandler(func(req SuggestionsRequest) (any, error) {
+		littleguy, err := dceSessionFor(req.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		fresh := littleguy.CheckForQueries()
+		return map[string]interface{}{
+			"new":         fresh,
+			"suggestions": littleguy.Suggestions(),
+		}, nil
+	})
+}
+
+func dceSnoozeHandler() http.HandlerFunc {
+	return JSONHandler(func(req SnoozeRequest) (any, error) {
+		littleguy, err := dceSessionFor(req.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		duration := time.Hour
+		if req.Duration != "" {
+			if duration, err = time.ParseDuration(req.Duration); err != nil || duration <= 0 {
+				return nil, fmt.Errorf("invalid duration %q", req.Duration)
+			}
+		}
+		if !littleguy.SnoozeSuggestion(req.SuggestionID, duration) {
+			return nil, fmt.Errorf("unknown suggestion %q", req.SuggestionID)
+		}
+		return map[string]string{"status": "snoozed"}, nil
+	})
+}
+
+const (
+	// maxSuggestionSessions caps the DCE sessions created for suggestion requests.
+	maxSuggestionSessions = 32
+	// suggestionSessionIdle is how long an unused suggestion session is kept.
+	suggestionSessionIdle = 30 * time.Minute
+)
+
+// suggestionSession is a DCE session created by dceSessionFor.
+type suggestionSession struct {
+	littleguy *dce.LittleGuy
+	lastUsed  time.Time
+}
+
+// suggestionSessions tracks the sessions created by dceSessionFor. Sessions started
+// with /dce are not tracked and never expire here.
+var suggestionSessions = struct {
+	sync.Mutex
+	byID map[string]*suggestionSession
+}{byID: make(map[string]*suggestionSession)}
+
+// dceSessionFor returns the DCE session for a conversation, creating an empty one so
+// suggestions are deduplicated across requests from the same editor session. Created
+// sessions are dropped after suggestionSessionIdle without use, and the least recently
+// used one is dropped when there would be more than maxSuggestionSessions.
+func dceSessionFor(conversationID string) (*dce.LittleGuy, error) {
+	if conversationID == "" {
+		return nil, fmt.Errorf("conversationId is required")
+	}
+
+	suggestionSessions.Lock()
+	defer suggestionSessions.Unlock()
+	now := time.Now()
+	for id, session := range suggestionSessions.byID {
+		if now.Sub(session.lastUsed) > suggestionSessionIdle {
+			dropSuggestionSession(id)
+		}
+	}
+
+	if littleguy, ok := dce.GetDCEContextManager().GetContext(conversationID); ok {
+		if session, tracked := suggestionSessions.byID[conversationID]; tracked {
+			session.lastUsed = now
+		}
+		return littleguy, nil
+	}
+
+	for len(suggestionSessions.byID) >= maxSuggestionSessions {
+		oldest := ""
+		for id, session := range suggestionSessions.byID {
+			if oldest == "" || session.lastUsed.Before(suggestionSessions.byID[oldest].lastUsed) {
+				oldest = id
+			}
+		}
+		dropSuggestionSession(oldest)
+	}
+	littleguy := dce.NewLittleGuy(conversationID, nil)
+	suggestionSessions.byID[conversationID] = &suggestionSession{littleguy: littleguy, lastUsed: now}
+	return littleguy, nil
+}
+
+// dropSuggestionSession forgets a session created by dceSessionFor, unless /dce has
+// since replaced it. Callers must hold suggestionSessions.
+func dropSuggestionSession(conversationID string) {
+	manager := dce.GetDCEContextManager()
+	if littleguy, ok := manager.GetContext(conversationID); ok && littleguy == suggestionSessions.byID[conversationID].littleguy {
+		littleguy.StopMonitoring()
+		manager.RemoveContext(conversationID)
+	}
+	delete(suggestionSessions.byID, conversationID)
+}
+
 func quickAssistClearHandler() http.HandlerFunc {
 	return JSONHandler(func(req ClearRequest) (any, error) {
 		if req.ConversationID == "" {
@@ -205,11 +334,7 @@ func whatHandler() http.HandlerFunc {
 
 func listModelsHandler() http.HandlerFunc {
 	return JSONHandler(func(_ struct{}) (any, error) {
-		endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT")
-		if endpoint == "" {
-			endpoint = "http://localhost:11434"
-		}
-		return fetchOllamaModels(endpoint)
+		return models.List(models.Endpoint())
 	})
 }
 
@@ -225,3 +350,15 @@ func setModelHandler() http.HandlerFunc {
 		}, nil
 	})
 }
+
+// metricsHandler exposes this server's LLM call metrics in the Prometheus text format.
+func metricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, fmt.Sprintf("Method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		metrics.WritePrometheus(w, metrics.Default.Snapshot())
+	}
+}
@@ -0,0 +1,104 @@
+// internal/llm/split.go
+
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/split"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxSplitUnitLines caps how many changed lines of each unit are shown to the LLM.
+const maxSplitUnitLines = 15
+
+// ProposeSplit asks the LLM to cluster staged units into a sequence of logical commits,
+// starting from the package-proximity grouping. The returned plan always covers every
+// unit exactly once; units the model leaves out are grouped by proximity.
+func ProposeSplit(units []split.Unit) (*split.Plan, error) {
+	proximity := split.ProximityGroups(units)
+
+	var unitList strings.Builder
+	for _, u := range units {
+		unitList.WriteString(describeSplitUnit(u))
+	}
+	var groupList strings.Builder
+	for i, g := range proximity.Groups {
+		groupList.WriteString(fmt.Sprintf("%d. %s\n", i+1, strings.Join(g.Units, ", ")))
+	}
+
+	prompt := fmt.Sprintf(`
+The following staged changes should be split into a sequence of small, logical commits.
+Each change unit has an ID.
+
+**Change Units:**
+%s
+**Initial grouping by package:**
+%s
+!TASK:
+1. Group the units into commits that each do one thing. Keep related units together even across packages (e.g. a function and its callers, code and its tests).
+2. Order the commits so each one builds on the previous ones.
+3. Every unit ID must appear in exactly one commit.
+4. Give each commit a Conventional Commits message header (type(scope): subject).
+5. Respond with JSON only: {"commits": [{"message": "feat(llm): add review prompt", "units": ["u1", "u3"]}]}
+`, unitList.String(), groupList.String())
+
+	response, err := clientFor(models.TaskSplit).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a meticulous developer who curates clean, reviewable commit history."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get split proposal from LLM: %w", err)
+	}
+
+	raw, err := utils.ExtractJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse split proposal: %w", err)
+	}
+	var parsed struct {
+		Commits []struct {
+			Message string   `json:"message"`
+			Units   []string `json:"units"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal split proposal: %w", err)
+	}
+
+	plan := &split.Plan{}
+	for _, c := range parsed.Commits {
+		plan.Groups = append(plan.Groups, split.Group{Message: strings.TrimSpace(c.Message), Units: c.Units})
+	}
+	plan.Normalize(units)
+	return plan, nil
+}
+
+// describeSplitUnit renders a unit's location and the first few changed lines.
+func describeSplitUnit(u split.Unit) string {
+	var b strings.Builder
+	if u.Whole {
+		b.WriteString(fmt.Sprintf("[%s] %s (whole file)\n", u.ID, u.File))
+	} else {
+		b.WriteString(fmt.Sprintf("[%s] %s %s\n", u.ID, u.File, u.Diff.Hunks[0].Header))
+	}
+
+	shown := 0
+	for _, h := range u.Diff.Hunks {
+		for _, l := range h.Lines {
+			if !strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "-") {
+				continue
+			}
+			if shown == maxSplitUnitLines {
+				b.WriteString("    ...\n")
+				return b.String()
+			}
+			b.WriteString("    " + l + "\n")
+			shown++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,290 @@
+// internal/llm/test_gen.go
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const (
+	// DefaultTestGenIterations is the number of repair rounds attempted after the first draft.
+	DefaultTestGenIterations = 3
+	// testGenTimeout bounds a single `go test` run of the generated file.
+	testGenTimeout = 2 * time.Minute
+	// maxTestOutputLines caps the compiler/test output fed back to the model.
+	maxTestOutputLines = 80
+)
+
+var packageClausePattern = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+
+// TestRunner runs `go test -run pattern` in pkgDir and returns the combined output.
+type TestRunner func(pkgDir, pattern string) (string, error)
+
+// TestGenOptions configures GenerateTests.
+type TestGenOptions struct {
+	MaxIterations int        // Repair rounds after the first draft; zero disables repairs
+	OutputPath    string     // Repo-relative test file path; derived from the source file if empty
+	Force         bool       // Overwrite an existing test file
+	Restore       bool       // Put the working tree back once the test passes; the file is returned in Content
+	Runner        TestRunner // Defaults to RunGoTest
+}
+
+// TestGenResult describes a generated test file.
+type TestGenResult struct {
+	Function   string   // Display name of the function under test
+	File       string   // Repo-relative path of the written test file
+	Tests      []string // TestXxx functions in the generated file
+	Iterations int      // Number of model drafts it took, including the first
+	Output     string   // Output of the last `go test` run
+	Content    string   // The generated file
+}
+
+// GenerateTests asks the LLM for a table-driven test of target, writes it next to the
+// source file and runs it. Failing compiler or test output is fed back to the model
+// for up to opts.MaxIterations repairs. If the test never passes, the file is removed
+// (or the previous contents restored when overwriting) and an error is returned
+// together with the last result. With opts.Restore the passing file is only on disk
+// while it runs, for callers such as editors that apply it themselves.
+//
+// target is a function name, a method as Type.Method, or either prefixed with the
+// file path, e.g. "internal/utils/diff.go:ParseUnifiedDiff".
+func GenerateTests(target string, opts TestGenOptions) (*TestGenResult, error) {
+	if opts.MaxIterations < 0 {
+		return nil, fmt.Errorf("iterations must not be negative, got %d", opts.MaxIterations)
+	}
+	if opts.Runner == nil {
+		opts.Runner = RunGoTest
+	}
+
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository path: %w", err)
+	}
+
+	fn, functions, err := treesitter.FindFunction(repoPath, target)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := treesitter.FunctionSource(repoPath, fn)
+	if err != nil {
+		return nil, err
+	}
+	fileContent, err := os.ReadFile(filepath.Join(repoPath, fn.File))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fn.File, err)
+	}
+	pkgMatch := packageClausePattern.FindSubmatch(fileContent)
+	if pkgMatch == nil {
+		return nil, fmt.Errorf("no package clause in %s", fn.File)
+	}
+	pkgName := string(pkgMatch[1])
+
+	outPath := defaultTestPath(repoPath, fn)
+	absOut := filepath.Join(repoPath, outPath)
+	if opts.OutputPath != "" {
+		root := repoPath
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		rel, abs, err := utils.ResolveRepoPath(root, opts.OutputPath)
+		if err != nil {
+			return nil, err
+		}
+		if rel == "" || !strings.HasSuffix(rel, "_test.go") {
+			return nil, fmt.Errorf("invalid test file path %q (must end in _test.go)", opts.OutputPath)
+		}
+		outPath, absOut = rel, abs
+	}
+	previous, readErr := os.ReadFile(absOut)
+	existed := readErr == nil
+	if existed && !opts.Force {
+		return nil, fmt.Errorf("%s already exists (use --force to overwrite or --out to choose another file)", outPath)
+	}
+
+	display := fn.Name
+	if fn.Receiver != "" {
+		display = fn.Receiver + "." + fn.Name
+	}
+	result := &TestGenResult{Function: display, File: outPath}
+
+	messages := []contextpkg.Message{
+		{Role: "system", Content: "You are an expert Go engineer who writes idiomatic, table-driven unit tests that compile on the first try."},
+		{Role: "user", Content: buildTestGenPrompt(fn, display, pkgName, source, calleeSignatures(fn, functions))},
+	}
+
+	for attempt := 1; attempt <= opts.MaxIterations+1; attempt++ {
+		result.Iterations = attempt
+
+		response, err := clientFor(models.TaskTestGen).GetChatResponse(messages)
+		if err != nil {
+			restoreTestFile(absOut, previous, existed)
+			return result, fmt.Errorf("failed to get test from LLM: %w", err)
+		}
+		messages = append(messages, contextpkg.Message{Role: "assistant", Content: response})
+
+		code := utils.ExtractCodeBlock(response, "go")
+		result.Tests = testFunctionNames(outPath, code)
+		result.Content = code
+
+		var feedback string
+		if len(result.Tests) == 0 {
+			feedback = "The file you returned contains no TestXxx functions."
+		} else {
+			if err := os.WriteFile(absOut, []byte(code), 0644); err != nil {
+				restoreTestFile(absOut, previous, existed)
+				return result, fmt.Errorf("failed to write %s: %w", outPath, err)
+			}
+
+			pattern := "^(" + strings.Join(result.Tests, "|") + ")$"
+			output, runErr := opts.Runner(filepath.Dir(absOut), pattern)
+			result.Output = output
+			if runErr == nil {
+				if opts.Restore {
+					restoreTestFile(absOut, previous, existed)
+				}
+				return result, nil
+			}
+			logrus.Infof("Generated test for %s failed (attempt %d): %v", display, attempt, runErr)
+			feedback = fmt.Sprintf("Running `go test -run '%s'` failed:\n\n%s", pattern,
+				truncateLines(output, maxTestOutputLines))
+		}
+
+		messages = append(messages, contextpkg.Message{
+			Role: "user",
+			Content: feedback + `
+
+!TASK: Fix the test file. Return the complete corrected file in a single go code block. ` +
+				`Do not modify the function under test.`,
+		})
+	}
+
+	restoreTestFile(absOut, previous, existed)
+	return result, fmt.Errorf("generated test for %s still failing after %d attempts", display, result.Iterations)
+}
+
+// RunGoTest runs `go test -run pattern .` inside pkgDir.
+func RunGoTest(pkgDir, pattern string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), testGenTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-count=1", "-run", pattern, ".")
+	cmd.Dir = pkgDir
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(out), fmt.Errorf("go test timed out after %s", testGenTimeout)
+	}
+	return string(out), err
+}
+
+// defaultTestPath returns <source>_test.go, or <source>_<func>_test.go if that already exists.
+func defaultTestPath(repoPath string, fn treesitter.FunctionInfo) string {
+	base := strings.TrimSuffix(fn.File, ".go")
+	path := base + "_test.go"
+	if _, err := os.Stat(filepath.Join(repoPath, path)); err == nil {
+		path = base + "_" + strings.ToLower(fn.Name) + "_test.go"
+	}
+	return path
+}
+
+// calleeSignatures lists the signatures of project functions that fn calls.
+func calleeSignatures(fn treesitter.FunctionInfo, functions []treesitter.FunctionInfo) []string {
+	called := make(map[string]bool)
+	deps := fn.Dependencies
+	for _, group := range [][]string{deps.Handlers, deps.Utilities, deps.Invocations} {
+		for _, name := range group {
+			called[name] = true
+		}
+	}
+
+	var signatures []string
+	seen := make(map[string]bool)
+	for _, f := range functions {
+		if !called[f.Name] || f.Signature == "" || seen[f.Signature] || strings.HasSuffix(f.File, "_test.go") {
+			continue
+		}
+		seen[f.Signature] = true
+		signatures = append(signatures, fmt.Sprintf("%s  // %s", f.Signature, f.File))
+	}
+	return signatures
+}
+
+// buildTestGenPrompt assembles the first test generation prompt.
+func buildTestGenPrompt(fn treesitter.FunctionInfo, display, pkgName, source string, callees []string) string {
+	calleeSection := "(none defined in this repository)"
+	if len(callees) > 0 {
+		calleeSection = strings.Join(callees, "\n")
+	}
+
+	return fmt.Sprintf(`
+Write a Go unit test for the function below.
+
+**Package:** %s
+**File:** %s
+**Function:** %s
+**Signature:** %s
+
+**Source:**
+`+"```go\n%s\n```"+`
+
+**Functions it calls (signatures only):**
+%s
+
+!TASK:
+1. Write a complete _test.go file in package %s (same package, so unexported identifiers are accessible).
+2. Use a table-driven test named Test%s with t.Run subtests.
+3. Only use the standard library. Do not touch the network. Use t.TempDir() for any files.
+4. Only assert behavior that is evident from the source above.
+5. Return the whole file in a single go code block with no commentary.
+`, pkgName, fn.File, display, fn.Signature, source, calleeSection, pkgName, strings.ReplaceAll(display, ".", "_"))
+}
+
+// testFunctionNames lists the TestXxx functions declared in code.
+func testFunctionNames(file, code string) []string {
+	functions, err := treesitter.ParseGoSource(file, []byte(code))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, fn := range functions {
+		if fn.Receiver == "" && strings.HasPrefix(fn.Name, "Test") && !utils.StringSliceContains(names, fn.Name) {
+			names = append(names, fn.Name)
+		}
+	}
+	return names
+}
+
+// restoreTestFile removes a failed generated file, or puts back what it replaced.
+func restoreTestFile(path string, previous []byte, existed bool) {
+	if existed {
+		if err := os.WriteFile(path, previous, 0644); err != nil {
+			logrus.Errorf("Failed to restore %s: %v", path, err)
+		}
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Failed to remove %s: %v", path, err)
+	}
+}
+
+// truncateLines keeps the first max lines of s.
+func truncateLines(s string, max int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= max {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[:max], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-max)
+}
@@ -0,0 +1,239 @@
+// internal/llm/tool_chat.go
+
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/soyuz43/prbuddy-go/internal/agent"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// LLMAPIEnv selects the wire format of tool-calling requests: "ollama" (default) for
+// /api/chat, or "openai" for an OpenAI-compatible /v1/chat/completions endpoint at
+// PRBUDDY_LLM_ENDPOINT.
+const LLMAPIEnv = "PRBUDDY_LLM_API"
+
+// LLMAPIKeyEnv is sent as a bearer token to OpenAI-compatible endpoints that need one.
+const LLMAPIKeyEnv = "PRBUDDY_LLM_API_KEY"
+
+// ToolCaller is implemented by clients that support function calling. The reply is the
+// assistant message, carrying either ToolCalls or the final Content.
+type ToolCaller interface {
+	ChatWithTools(messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, error)
+}
+
+// toolUsageReporter is the usage-reporting variant of ToolCaller, see usageReporter.
+type toolUsageReporter interface {
+	toolChatWithUsage(messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, Usage, error)
+}
+
+// toolDefinition is the tool shape shared by Ollama and the OpenAI API.
+type toolDefinition struct {
+	Type     string           `json:"type"`
+	Function agent.Definition `json:"function"`
+}
+
+func toolDefinitions(tools []agent.Definition) []toolDefinition {
+	defs := make([]toolDefinition, len(tools))
+	for i, tool := range tools {
+		defs[i] = toolDefinition{Type: "function", Function: tool}
+	}
+	return defs
+}
+
+func (c *DefaultLLMClient) ChatWithTools(messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, error) {
+	reply, _, err := c.toolChatWithUsage(messages, tools)
+	return reply, err
+}
+
+func (c *DefaultLLMClient) toolChatWithUsage(messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, Usage, error) {
+	model, endpoint := ModelFor(c.Task)
+	switch api := strings.ToLower(strings.TrimSpace(os.Getenv(LLMAPIEnv))); api {
+	case "", "ollama":
+		return ollamaToolChat(model, endpoint, messages, tools)
+	case "openai":
+		return openAIToolChat(model, endpoint, messages, tools)
+	default:
+		return contextpkg.Message{}, Usage{Model: model, Endpoint: endpoint}, fmt.Errorf("unknown %s %q; use ollama or openai", LLMAPIEnv, api)
+	}
+}
+
+// postJSON sends a non-streaming request and decodes the JSON response into out.
+func postJSON(url, apiKey, model string, body interface{}, out interface{}) error {
+	jsonBody, err := utils.MarshalJSON(body)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request body")
+	}
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(jsonBody))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := chatHTTPClient().Do(req)
+	if err != nil {
+		return transportError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return toolStatusError(resp, model)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		if transportErr := transportError(err); errors.Is(transportErr, ErrLLMTimeout) {
+			return transportErr
+		}
+		return errors.Wrap(err, "failed to decode LLM response")
+	}
+	return nil
+}
+
+// toolStatusError is statusError for tool requests: models without tool support are
+// rejected with a client error that mentions tools.
+func toolStatusError(resp *http.Response, model string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(string(body)), "tool") {
+		return fmt.Errorf("%w: %s", ErrToolsUnsupported, strings.TrimSpace(string(body)))
+	}
+	return statusError(resp.StatusCode, model)
+}
+
+//------------------------------------------------------------------------------
+// Ollama /api/chat
+//------------------------------------------------------------------------------
+
+func ollamaToolChat(model, endpoint string, messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, Usage, error) {
+	request := map[string]interface{}{
+		"model":    model,
+		"messages": messages,
+		"options":  chatOptions,
+		"stream":   false,
+	}
+	if len(tools) > 0 {
+		request["tools"] = toolDefinitions(tools)
+	}
+
+	var response struct {
+		Message contextpkg.Message `json:"message"`
+		OllamaStats
+	}
+	if err := postJSON(endpoint+"/api/chat", "", model, request, &response); err != nil {
+		return contextpkg.Message{}, Usage{Model: model, Endpoint: endpoint}, err
+	}
+	reply := response.Message
+	reply.Role = "assistant"
+	for i := range reply.ToolCalls {
+		normalizeArguments(&reply.ToolCalls[i].Function)
+	}
+	return reply, response.usage(model, endpoint), nil
+}
+
+//------------------------------------------------------------------------------
+// OpenAI-compatible /v1/chat/completions
+//------------------------------------------------------------------------------
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall differs from Ollama's in carrying the arguments as a JSON string.
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func openAIToolChat(model, endpoint string, messages []contextpkg.Message, tools []agent.Definition) (contextpkg.Message, Usage, error) {
+	usage := Usage{Model: model, Endpoint: endpoint}
+
+	wire := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		out := openAIMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			var oc openAIToolCall
+			oc.ID, oc.Type = call.ID, "function"
+			oc.Function.Name, oc.Function.Arguments = call.Function.Name, string(call.Function.Arguments)
+			out.ToolCalls = append(out.ToolCalls, oc)
+		}
+		wire = append(wire, out)
+	}
+	request := map[string]interface{}{
+		"model":    model,
+		"messages": wire,
+		"stream":   false,
+	}
+	if len(tools) > 0 {
+		request["tools"] = toolDefinitions(tools)
+	}
+
+	var response struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	url := strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1") + "/v1/chat/completions"
+	if err := postJSON(url, os.Getenv(LLMAPIKeyEnv), model, request, &response); err != nil {
+		return contextpkg.Message{}, usage, err
+	}
+	if len(response.Choices) == 0 {
+		return contextpkg.Message{}, usage, fmt.Errorf("empty response from LLM")
+	}
+	usage.PromptTokens = response.Usage.PromptTokens
+	usage.CompletionTokens = response.Usage.CompletionTokens
+
+	message := response.Choices[0].Message
+	reply := contextpkg.Message{Role: "assistant", Content: message.Content}
+	for i, oc := range message.ToolCalls {
+		call := contextpkg.ToolCall{ID: oc.ID}
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_%d", i)
+		}
+		call.Function.Name = oc.Function.Name
+		call.Function.Arguments = json.RawMessage(oc.Function.Arguments)
+		normalizeArguments(&call.Function)
+		reply.ToolCalls = append(reply.ToolCalls, call)
+	}
+	return reply, usage, nil
+}
+
+// normalizeArguments makes Arguments a JSON object. Some models send an encoded string
+// even where the format expects an object; anything unparseable is kept as a JSON string
+// so the tool reports it back to the model.
+func normalizeArguments(fn *contextpkg.ToolCallFunction) {
+	raw := strings.TrimSpace(string(fn.Arguments))
+	if raw == "" || raw == "null" {
+		fn.Arguments = json.RawMessage("{}")
+		return
+	}
+	var encoded string
+	if json.Unmarshal([]byte(raw), &encoded) == nil {
+		raw = encoded
+	}
+	if json.Valid([]byte(raw)) {
+		fn.Arguments = json.RawMessage(raw)
+		return
+	}
+	quoted, _ := json.Marshal(raw)
+	fn.Arguments = quoted
+}
@@ -0,0 +1,72 @@
+// internal/llm/why.go
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/history"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+)
+
+const (
+	// maxWhyDiffLines caps each commit's change shown to the model.
+	maxWhyDiffLines = 60
+	// maxWhyDraftLines caps how much of a stored PR draft is shown per commit.
+	maxWhyDraftLines = 40
+)
+
+// ExplainHistory asks the LLM to narrate how the target's code came to look the way it
+// does, from the commits that shaped it (oldest first) and the current source. Stored PR
+// drafts are looked up for each commit, filled into its Draft field and given to the
+// model as recorded rationale.
+func ExplainHistory(target history.Target, source string, commits []history.Commit) (string, error) {
+	var log strings.Builder
+	for i := range commits {
+		if draft, ok := FindDraftForCommit(commits[i].SHA); ok {
+			commits[i].Draft = draft
+		}
+		c := commits[i]
+		fmt.Fprintf(&log, "### %s %s %s: %s\n", c.SHA[:7], c.Date, c.Author, c.Subject)
+		if c.Body != "" {
+			log.WriteString(c.Body + "\n")
+		}
+		if c.BlamedLines > 0 {
+			fmt.Fprintf(&log, "Last touched %d of the current lines.\n", c.BlamedLines)
+		}
+		if c.Draft != "" {
+			log.WriteString("PR draft:\n" + contextpkg.TruncateDiff(c.Draft, maxWhyDraftLines) + "\n")
+		}
+		if c.Diff != "" {
+			log.WriteString("```diff\n" + contextpkg.TruncateDiff(c.Diff, maxWhyDiffLines) + "\n```\n")
+		}
+		log.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`
+Explain why this code looks the way it does, using its history.
+
+**Code:** %s
+%s
+
+**History (oldest first):**
+%s
+!TASK:
+1. Narrate how the code evolved, commit by commit, grouping minor changes.
+2. Explain the rationale for its current shape, citing commit messages and PR drafts by short SHA.
+3. Where no reason was recorded, say so instead of guessing.
+4. Finish with anything a newcomer should know before changing it.
+5. Be concise and use markdown.
+`, target, "```\n"+source+"\n```", log.String())
+
+	response, err := clientFor(models.TaskChat).GetChatResponse([]contextpkg.Message{
+		{Role: "system", Content: "You are a senior engineer explaining the history of a codebase to a new team member."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get history explanation from LLM: %w", err)
+	}
+	return response, nil
+}
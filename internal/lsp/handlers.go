@@ -0,0 +1,416 @@
+// internal/lsp/handlers.go
+
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/review"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxHoverRelations caps the callers and callees listed in a hover.
+const maxHoverRelations = 10
+
+// ------------------------------------------------------------------------------
+// Hover
+// ------------------------------------------------------------------------------
+
+func (s *Server) hover(raw json.RawMessage) (interface{}, error) {
+	var params TextDocumentPositionParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	text, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil
+	}
+	name, rng := identifierAt(text, params.Position)
+	if name == "" {
+		return nil, nil
+	}
+	file, err := s.relativePath(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil
+	}
+	index, err := s.functions()
+	if err != nil {
+		return nil, err
+	}
+	matches := index.lookup(name, file)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: hoverMarkdown(index, matches[0])},
+		Range:    &rng,
+	}, nil
+}
+
+func hoverMarkdown(index *functionIndex, fn treesitter.FunctionInfo) string {
+	var b strings.Builder
+	signature := fn.Signature
+	if signature == "" {
+		signature = "func " + codegraph.DisplayName(fn)
+	}
+	fmt.Fprintf(&b, "```go\n%s\n```\n\n", signature)
+	fmt.Fprintf(&b, "`%s:%d`", fn.File, fn.StartLine)
+	if len(fn.Returns) > 0 {
+		fmt.Fprintf(&b, "\n\n**Returns:** %s", strings.Join(fn.Returns, ", "))
+	}
+
+	if called := codegraph.Invocations(fn); len(called) > 0 {
+		b.WriteString("\n\n**Calls:** ")
+		b.WriteString(limitList(called))
+	}
+	var callers []string
+	for _, caller := range index.callers(fn) {
+		callers = append(callers, fmt.Sprintf("%s (%s:%d)", codegraph.DisplayName(caller), caller.File, caller.StartLine))
+	}
+	if len(callers) > 0 {
+		b.WriteString("\n\n**Called by:** ")
+		b.WriteString(limitList(callers))
+	}
+	return b.String()
+}
+
+func limitList(items []string) string {
+	if len(items) <= maxHoverRelations {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxHoverRelations], ", "), len(items)-maxHoverRelations)
+}
+
+// identifierAt returns the Go identifier under pos and its range.
+func identifierAt(text string, pos Position) (string, Range) {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return "", Range{}
+	}
+	line := []rune(lines[pos.Line])
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	start := runeIndex(line, pos.Character)
+	end := start
+	for start > 0 && isIdent(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdent(line[end]) {
+		end++
+	}
+	if start == end || unicode.IsDigit(line[start]) {
+		return "", Range{}
+	}
+	return string(line[start:end]), Range{
+		Start: Position{Line: pos.Line, Character: utf16Len(line[:start])},
+		End:   Position{Line: pos.Line, Character: utf16Len(line[:end])},
+	}
+}
+
+// runeIndex converts an LSP character offset, in UTF-16 code units, to an index into
+// line. Offsets past the end, or inside a surrogate pair, round down.
+func runeIndex(line []rune, character int) int {
+	units := 0
+	for i, r := range line {
+		units += utf16.RuneLen(r)
+		if units > character {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// utf16Len returns the length of runes in UTF-16 code units.
+func utf16Len(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// ------------------------------------------------------------------------------
+// Code actions
+// ------------------------------------------------------------------------------
+
+func (s *Server) codeActions(raw json.RawMessage) (interface{}, error) {
+	var params CodeActionParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	uri := params.TextDocument.URI
+	actions := []CodeAction{}
+
+	if file, err := s.relativePath(uri); err == nil && strings.HasSuffix(file, ".go") {
+		index, err := s.functions()
+		if err != nil {
+			return nil, err
+		}
+		if fn, ok := index.enclosing(file, params.Range.Start.Line+1); ok {
+			name := codegraph.DisplayName(fn)
+			actions = append(actions, CodeAction{
+				Title:   "PRBuddy: Explain " + name,
+				Kind:    "quickfix",
+				Command: &Command{Title: "Explain function", Command: CommandExplainFunction, Arguments: []interface{}{uri, params.Range.Start.Line}},
+			})
+			if !strings.HasSuffix(file, "_test.go") {
+				actions = append(actions, CodeAction{
+					Title:   "PRBuddy: Generate test for " + name,
+					Kind:    "quickfix",
+					Command: &Command{Title: "Generate test", Command: CommandGenerateTest, Arguments: []interface{}{uri, params.Range.Start.Line}},
+				})
+			}
+		}
+	}
+
+	if params.Range.Start != params.Range.End {
+		actions = append(actions, CodeAction{
+			Title:   "PRBuddy: Review selection",
+			Kind:    "quickfix",
+			Command: &Command{Title: "Review selection", Command: CommandReviewSelection, Arguments: []interface{}{uri, params.Range}},
+		})
+	}
+	actions = append(actions, CodeAction{
+		Title:   "PRBuddy: Draft PR from last commit",
+		Kind:    "source",
+		Command: &Command{Title: "Draft PR", Command: CommandDraftPR},
+	})
+	return actions, nil
+}
+
+// ------------------------------------------------------------------------------
+// Commands
+// ------------------------------------------------------------------------------
+
+func (s *Server) executeCommand(raw json.RawMessage) (interface{}, error) {
+	var params ExecuteCommandParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	var result string
+	var err error
+	switch params.Command {
+	case CommandExplainFunction:
+		result, err = s.explainFunction(params.Arguments)
+	case CommandGenerateTest:
+		result, err = s.generateTest(params.Arguments)
+	case CommandReviewSelection:
+		result, err = s.reviewSelection(params.Arguments)
+	case CommandDraftPR:
+		result, err = draftPR()
+	default:
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown command: " + params.Command}
+	}
+	if err != nil {
+		s.showMessage(MessageError, "PRBuddy: "+err.Error())
+		return nil, err
+	}
+	s.showMessage(MessageInfo, result)
+	return result, nil
+}
+
+// functionArgs decodes the [uri, line] arguments of the function commands.
+func (s *Server) functionArgs(args []json.RawMessage) (treesitter.FunctionInfo, string, error) {
+	var uri string
+	var line int
+	if len(args) != 2 || json.Unmarshal(args[0], &uri) != nil || json.Unmarshal(args[1], &line) != nil {
+		return treesitter.FunctionInfo{}, "", &rpcError{Code: codeInvalidParams, Message: "expected arguments [uri, line]"}
+	}
+	file, err := s.relativePath(uri)
+	if err != nil {
+		return treesitter.FunctionInfo{}, "", err
+	}
+	index, err := s.functions()
+	if err != nil {
+		return treesitter.FunctionInfo{}, "", err
+	}
+	fn, ok := index.enclosing(file, line+1)
+	if !ok {
+		return treesitter.FunctionInfo{}, "", fmt.Errorf("no function at %s:%d", file, line+1)
+	}
+	return fn, uri, nil
+}
+
+func (s *Server) explainFunction(args []json.RawMessage) (string, error) {
+	fn, uri, err := s.functionArgs(args)
+	if err != nil {
+		return "", err
+	}
+	text, err := s.document(uri)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(text, "\n")
+	if fn.EndLine > len(lines) {
+		return "", fmt.Errorf("%s has changed since it was indexed", fn.File)
+	}
+	source := strings.Join(lines[fn.StartLine-1:fn.EndLine], "\n")
+	return llm.ExplainFunction(codegraph.DisplayName(fn), fn.File, source)
+}
+
+// generateTest has the LLM write and validate a test, then hands the file to the
+// editor as a workspace edit so it is created like any other edit and can be undone.
+func (s *Server) generateTest(args []json.RawMessage) (string, error) {
+	fn, _, err := s.functionArgs(args)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	client, repoPath := s.client, s.repoPath
+	s.mu.Unlock()
+	if !client.canCreateFiles() {
+		return "", fmt.Errorf("the editor cannot create files through workspace/applyEdit")
+	}
+
+	result, err := llm.GenerateTests(fn.File+":"+codegraph.DisplayName(fn), llm.TestGenOptions{
+		MaxIterations: llm.DefaultTestGenIterations,
+		Restore:       true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	uri := pathToURI(filepath.Join(repoPath, filepath.FromSlash(result.File)))
+	params := ApplyWorkspaceEditParams{
+		Label: "Generate test for " + codegraph.DisplayName(fn),
+		Edit: WorkspaceEdit{DocumentChanges: []interface{}{
+			CreateFile{Kind: "create", URI: uri},
+			TextDocumentEdit{
+				TextDocument: VersionedTextDocumentIdentifier{URI: uri},
+				Edits:        []TextEdit{{NewText: result.Content}},
+			},
+		}},
+	}
+	var applied ApplyWorkspaceEditResult
+	if err := s.request("workspace/applyEdit", params, &applied); err != nil {
+		return "", err
+	}
+	if !applied.Applied {
+		reason := applied.FailureReason
+		if reason == "" {
+			reason = "rejected by the editor"
+		}
+		return "", fmt.Errorf("could not create %s: %s", result.File, reason)
+	}
+	return fmt.Sprintf("Created %s (%s) after %d attempt(s)", result.File, strings.Join(result.Tests, ", "), result.Iterations), nil
+}
+
+func (s *Server) reviewSelection(args []json.RawMessage) (string, error) {
+	var uri string
+	var rng Range
+	if len(args) != 2 || json.Unmarshal(args[0], &uri) != nil || json.Unmarshal(args[1], &rng) != nil {
+		return "", &rpcError{Code: codeInvalidParams, Message: "expected arguments [uri, range]"}
+	}
+	file, err := s.relativePath(uri)
+	if err != nil {
+		return "", err
+	}
+	text, err := s.document(uri)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(text, "\n")
+	end := rng.End.Line
+	if rng.End.Character == 0 && end > rng.Start.Line {
+		end-- // A selection ending at column 0 does not include that line
+	}
+	if rng.Start.Line < 0 || end >= len(lines) || end < rng.Start.Line {
+		return "", &rpcError{Code: codeInvalidParams, Message: "selection is outside the document"}
+	}
+
+	report, err := llm.ReviewDiff(selectionDiff(file, rng.Start.Line+1, lines[rng.Start.Line:end+1]))
+	if err != nil {
+		return "", err
+	}
+	s.publishFindings(uri, file, report)
+	return fmt.Sprintf("Review found %d issue(s) in the selection", len(report.Findings)), nil
+}
+
+// selectionDiff presents the selected lines as an addition so the review engine's
+// line-number validation applies to them unchanged.
+func selectionDiff(file string, startLine int, lines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", file, file, file, file)
+	fmt.Fprintf(&b, "@@ -0,0 +%d,%d @@\n", startLine, len(lines))
+	for _, line := range lines {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
+
+// reviewSavedFile reviews a saved file's uncommitted changes and publishes the findings.
+func (s *Server) reviewSavedFile(uri string) {
+	file, err := s.relativePath(uri)
+	if err != nil {
+		return
+	}
+	diff, err := utils.ExecGit("diff", "HEAD", "--no-color", "--", file)
+	if err != nil || strings.TrimSpace(diff) == "" {
+		s.publishFindings(uri, file, &review.Report{})
+		return
+	}
+	report, err := llm.ReviewDiff(diff)
+	if err != nil {
+		s.showMessage(MessageWarning, "PRBuddy: review failed: "+err.Error())
+		return
+	}
+	s.publishFindings(uri, file, report)
+}
+
+// publishFindings replaces the document's diagnostics with the findings for file.
+func (s *Server) publishFindings(uri, file string, report *review.Report) {
+	diagnostics := []Diagnostic{}
+	for _, f := range report.Findings {
+		if f.File != file {
+			continue
+		}
+		message := f.Message
+		if f.Suggestion != "" {
+			message += "\n\nSuggestion: " + f.Suggestion
+		}
+		endLine := f.EndLine
+		if endLine < f.StartLine {
+			endLine = f.StartLine
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range: Range{
+				Start: Position{Line: f.StartLine - 1},
+				End:   Position{Line: endLine}, // Through the end of the last line
+			},
+			Severity: diagnosticSeverity(f.Severity),
+			Code:     f.Category,
+			Source:   diagnosticSource,
+			Message:  message,
+		})
+	}
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func diagnosticSeverity(severity string) int {
+	switch severity {
+	case review.SeverityError:
+		return SeverityError
+	case review.SeverityWarning:
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+func draftPR() (string, error) {
+	commitMessage, diffs, err := llm.GeneratePreDraftPR()
+	if err != nil {
+		return "", err
+	}
+	return llm.GenerateDraftPR(commitMessage, diffs)
+}
@@ -0,0 +1,106 @@
+// internal/lsp/index.go
+
+package lsp
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/soyuz43/prbuddy-go/internal/mapquery"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// functionIndex is the project map of the workspace, kept current with open buffers.
+type functionIndex struct {
+	repoPath string
+
+	mu     sync.RWMutex
+	byFile map[string][]treesitter.FunctionInfo // Repo-relative path -> functions
+	query  *mapquery.Index                      // Call index of byFile; nil when stale
+}
+
+// buildIndex parses every tracked and untracked Go file under repoPath.
+func buildIndex(repoPath string) (*functionIndex, error) {
+	files, err := utils.ExecGit("-C", repoPath, "ls-files", "--cached", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	functions, err := treesitter.ParseGoFiles(repoPath, utils.SplitLines(files))
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &functionIndex{repoPath: repoPath, byFile: make(map[string][]treesitter.FunctionInfo)}
+	for _, fn := range functions {
+		ix.byFile[fn.File] = append(ix.byFile[fn.File], fn)
+	}
+	return ix, nil
+}
+
+// update reparses one file from its (possibly unsaved) contents.
+func (ix *functionIndex) update(file string, content []byte) {
+	functions, err := treesitter.ParseGoSource(file, content)
+	if err != nil {
+		return // Keep the last good parse while the buffer does not parse
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.byFile[file] = functions
+	ix.query = nil
+}
+
+// enclosing returns the function whose declaration spans the one-based line.
+func (ix *functionIndex) enclosing(file string, line int) (treesitter.FunctionInfo, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	for _, fn := range ix.byFile[file] {
+		if fn.StartLine <= line && line <= fn.EndLine {
+			return fn, true
+		}
+	}
+	return treesitter.FunctionInfo{}, false
+}
+
+// lookup finds functions named name, those in preferFile first.
+func (ix *functionIndex) lookup(name, preferFile string) []treesitter.FunctionInfo {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var matches []treesitter.FunctionInfo
+	for _, functions := range ix.byFile {
+		for _, fn := range functions {
+			if fn.Name == name {
+				matches = append(matches, fn)
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if (matches[i].File == preferFile) != (matches[j].File == preferFile) {
+			return matches[i].File == preferFile
+		}
+		if matches[i].File != matches[j].File {
+			return matches[i].File < matches[j].File
+		}
+		return matches[i].StartLine < matches[j].StartLine
+	})
+	return matches
+}
+
+// callers returns the functions that call fn.
+func (ix *functionIndex) callers(fn treesitter.FunctionInfo) []treesitter.FunctionInfo {
+	return ix.calls().Callers(fn)
+}
+
+// calls returns the call index of the current functions, rebuilt after an update.
+func (ix *functionIndex) calls() *mapquery.Index {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.query == nil {
+		var functions []treesitter.FunctionInfo
+		for _, fileFunctions := range ix.byFile {
+			functions = append(functions, fileFunctions...)
+		}
+		ix.query = mapquery.New(ix.repoPath, functions)
+	}
+	return ix.query
+}
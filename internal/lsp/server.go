@@ -0,0 +1,422 @@
+// internal/lsp/server.go
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Commands offered as code actions and accepted by workspace/executeCommand.
+const (
+	CommandExplainFunction = "prbuddy.explainFunction" // Arguments: uri, zero-based line
+	CommandGenerateTest    = "prbuddy.generateTest"    // Arguments: uri, zero-based line
+	CommandReviewSelection = "prbuddy.reviewSelection" // Arguments: uri, range
+	CommandDraftPR         = "prbuddy.draftPR"         // No arguments
+)
+
+// diagnosticSource labels everything this server publishes.
+const diagnosticSource = "prbuddy"
+
+// clientRequestTimeout bounds the wait for the client to answer a server request.
+const clientRequestTimeout = time.Minute
+
+// Server is a Language Server Protocol server over a pair of streams, usually stdio.
+type Server struct {
+	in  *bufio.Reader
+	out *writer
+
+	mu          sync.Mutex
+	repoPath    string
+	opts        InitializationOptions
+	client      ClientCapabilities
+	docs        map[string]string // URI -> current text
+	index       *functionIndex
+	initialized bool
+	shutdown    bool
+
+	nextID int                   // Last ID of a request sent to the client
+	calls  map[int]chan *message // Requests to the client awaiting a response, by ID
+	closed chan struct{}         // Closed when Run returns
+
+	pending sync.WaitGroup // Long-running commands
+}
+
+// NewServer returns a server reading requests from in and writing to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:     bufio.NewReader(in),
+		out:    &writer{out: out},
+		docs:   make(map[string]string),
+		calls:  make(map[int]chan *message),
+		closed: make(chan struct{}),
+	}
+}
+
+// errExitWithoutShutdown is returned when the client sends exit before shutdown.
+var errExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// Run serves until the client sends exit or closes the input stream.
+func (s *Server) Run() error {
+	defer s.pending.Wait()
+	defer close(s.closed)
+	for {
+		req, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			s.out.write(&message{ID: nullID(), Error: rpcErr})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.Method == "exit" {
+			s.mu.Lock()
+			shutdown := s.shutdown
+			s.mu.Unlock()
+			if !shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		s.dispatch(req)
+	}
+}
+
+func nullID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
+
+// dispatch handles one message. Commands that call the LLM run in the background so
+// hovers and edits stay responsive.
+func (s *Server) dispatch(req *message) {
+	if req.Method == "" && req.ID != nil {
+		s.deliver(req)
+		return
+	}
+	if req.ID == nil {
+		s.handleNotification(req)
+		return
+	}
+
+	s.mu.Lock()
+	ready := s.initialized || req.Method == "initialize"
+	s.mu.Unlock()
+	if !ready {
+		s.reply(req, nil, &rpcError{Code: codeNotInitialized, Message: "server not initialized"})
+		return
+	}
+
+	if req.Method == "workspace/executeCommand" {
+		s.pending.Add(1)
+		go func() {
+			defer s.pending.Done()
+			result, err := s.executeCommand(req.Params)
+			s.reply(req, result, err)
+		}()
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+	case "textDocument/hover":
+		result, err = s.hover(req.Params)
+	case "textDocument/codeAction":
+		result, err = s.codeActions(req.Params)
+	default:
+		err = &rpcError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+	}
+	s.reply(req, result, err)
+}
+
+func (s *Server) reply(req *message, result interface{}, err error) {
+	resp := &message{ID: req.ID}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else if result == nil {
+		resp.Result = json.RawMessage("null")
+	} else {
+		resp.Result = result
+	}
+	if writeErr := s.out.write(resp); writeErr != nil {
+		logrus.Errorf("Failed to write LSP response: %v", writeErr)
+	}
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	if err := s.out.write(&message{Method: method, Params: data}); err != nil {
+		logrus.Errorf("Failed to write LSP notification: %v", err)
+	}
+}
+
+func (s *Server) showMessage(kind int, text string) {
+	s.notify("window/showMessage", ShowMessageParams{Type: kind, Message: text})
+}
+
+// request sends a request to the client and decodes its response into result. It must
+// not be called from the Run loop, which delivers the response.
+func (s *Server) request(method string, params, result interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	ch := make(chan *message, 1)
+	s.calls[id] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.calls, id)
+		s.mu.Unlock()
+	}()
+
+	rawID := json.RawMessage(strconv.Itoa(id))
+	if err := s.out.write(&message{ID: &rawID, Method: method, Params: data}); err != nil {
+		return err
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s failed: %s", method, resp.Error.Message)
+		}
+		// Result was decoded generically; round-trip it into the caller's type.
+		raw, err := json.Marshal(resp.Result)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, result)
+	case <-time.After(clientRequestTimeout):
+		return fmt.Errorf("%s: no response from the editor after %s", method, clientRequestTimeout)
+	case <-s.closed:
+		return fmt.Errorf("%s: connection closed", method)
+	}
+}
+
+// deliver hands a response from the client to the request waiting for it.
+func (s *Server) deliver(resp *message) {
+	id, err := strconv.Atoi(string(*resp.ID))
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	ch, ok := s.calls[id]
+	s.mu.Unlock()
+	if ok {
+		select {
+		case ch <- resp:
+		default: // A duplicate response; the first one is being handled
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Lifecycle and document sync
+// ------------------------------------------------------------------------------
+
+func (s *Server) initialize(raw json.RawMessage) (interface{}, error) {
+	var params InitializeParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	root := params.RootPath
+	if params.RootURI != "" {
+		if path, err := uriToPath(params.RootURI); err == nil {
+			root = path
+		}
+	}
+	if root != "" {
+		// git and the LLM helpers work on the current directory.
+		if err := os.Chdir(root); err != nil {
+			return nil, fmt.Errorf("failed to enter workspace %s: %w", root, err)
+		}
+	}
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("workspace is not a git repository: %w", err)
+	}
+
+	s.mu.Lock()
+	s.repoPath = repoPath
+	s.opts = params.InitializationOptions
+	s.client = params.Capabilities
+	s.initialized = true
+	s.mu.Unlock()
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    1, // Full document sync
+				"save":      map[string]bool{"includeText": false},
+			},
+			"hoverProvider":      true,
+			"codeActionProvider": true,
+			"executeCommandProvider": map[string]interface{}{
+				"commands": []string{CommandExplainFunction, CommandGenerateTest, CommandReviewSelection, CommandDraftPR},
+			},
+		},
+		"serverInfo": map[string]string{"name": "prbuddy-go"},
+	}, nil
+}
+
+func (s *Server) handleNotification(req *message) {
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.setDocument(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			// Full sync: the last change holds the whole document.
+			s.setDocument(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.mu.Lock()
+			delete(s.docs, params.TextDocument.URI)
+			s.mu.Unlock()
+		}
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.mu.Lock()
+			reviewOnSave := s.opts.ReviewOnSave
+			s.mu.Unlock()
+			if reviewOnSave {
+				s.pending.Add(1)
+				go func() {
+					defer s.pending.Done()
+					s.reviewSavedFile(params.TextDocument.URI)
+				}()
+			}
+		}
+	}
+}
+
+func (s *Server) setDocument(uri, text string) {
+	s.mu.Lock()
+	s.docs[uri] = text
+	index := s.index
+	s.mu.Unlock()
+
+	if index != nil {
+		if file, err := s.relativePath(uri); err == nil && strings.HasSuffix(file, ".go") {
+			index.update(file, []byte(text))
+		}
+	}
+}
+
+// document returns the open buffer for uri, or the file on disk.
+func (s *Server) document(uri string) (string, error) {
+	s.mu.Lock()
+	text, ok := s.docs[uri]
+	s.mu.Unlock()
+	if ok {
+		return text, nil
+	}
+	path, err := uriToPath(uri)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// relativePath converts a document URI to a repo-relative slash path.
+func (s *Server) relativePath(uri string) (string, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	repoPath := s.repoPath
+	s.mu.Unlock()
+
+	rel, err := filepath.Rel(repoPath, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// functions returns the project map, building it on first use and folding in the
+// buffers that were opened before it existed.
+func (s *Server) functions() (*functionIndex, error) {
+	s.mu.Lock()
+	index, repoPath := s.index, s.repoPath
+	s.mu.Unlock()
+	if index != nil {
+		return index, nil
+	}
+
+	index, err := buildIndex(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build project map: %w", err)
+	}
+	s.mu.Lock()
+	if s.index != nil {
+		index = s.index
+	} else {
+		s.index = index
+	}
+	docs := make(map[string]string, len(s.docs))
+	for uri, text := range s.docs {
+		docs[uri] = text
+	}
+	s.mu.Unlock()
+
+	for uri, text := range docs {
+		if file, err := s.relativePath(uri); err == nil && strings.HasSuffix(file, ".go") {
+			index.update(file, []byte(text))
+		}
+	}
+	return index, nil
+}
+
+func decodeParams(raw json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// internal/mapquery/mapquery.go
+
+package mapquery
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+)
+
+// Filter selects functions from a project map. Empty fields match everything and set
+// fields must all match.
+type Filter struct {
+	Func     string // Name or Type.Method; may use * and ? wildcards
+	File     string // Repo-relative file or directory prefix; may use wildcards
+	Calls    string // Functions that call this name
+	CalledBy string // Functions this one calls
+	Returns  string // Functions with a result of this type, e.g. "error" or "*Patch"
+	Unused   bool   // Functions nothing in the project calls
+}
+
+// Result is a matching function with its callers and callees in the map.
+type Result struct {
+	treesitter.FunctionInfo
+	Callers []string `json:"callers"` // As Type.Method or Name
+	Callees []string `json:"callees"` // Project functions only
+}
+
+// Index answers queries over a project map. Calls are resolved by codegraph, which
+// prefers the caller's own package and otherwise the packages its file imports, so
+// calls into the standard library do not match project functions of the same name.
+type Index struct {
+	Functions []treesitter.FunctionInfo // Repo-relative paths, sorted by file and line
+
+	byName  map[string][]int
+	callers [][]int
+	callees [][]int
+}
+
+// New indexes the functions of a project map of the repository at repoPath. The
+// package clauses and imports are read from the files the functions are in.
+func New(repoPath string, functions []treesitter.FunctionInfo) *Index {
+	ix := &Index{byName: make(map[string][]int)}
+	var files []string
+	seen := make(map[string]bool)
+	for _, fn := range functions {
+		fn.File = treesitter.RepoRelativePath(fn.File)
+		ix.Functions = append(ix.Functions, fn)
+		if !seen[fn.File] {
+			seen[fn.File] = true
+			files = append(files, fn.File)
+		}
+	}
+	sort.SliceStable(ix.Functions, func(i, j int) bool {
+		if ix.Functions[i].File != ix.Functions[j].File {
+			return ix.Functions[i].File < ix.Functions[j].File
+		}
+		return ix.Functions[i].StartLine < ix.Functions[j].StartLine
+	})
+	for i, fn := range ix.Functions {
+		ix.byName[fn.Name] = append(ix.byName[fn.Name], i)
+	}
+
+	ix.callers = make([][]int, len(ix.Functions))
+	ix.callees = make([][]int, len(ix.Functions))
+	graph := codegraph.BuildWithTests(repoPath, files, ix.Functions)
+	for g, fn := range graph.Functions {
+		i, ok := ix.find(fn)
+		if !ok {
+			continue
+		}
+		for _, callee := range graph.Callees(g) {
+			if j, ok := ix.find(graph.Functions[callee]); ok {
+				ix.callees[i] = append(ix.callees[i], j)
+				ix.callers[j] = append(ix.callers[j], i)
+			}
+		}
+	}
+	return ix
+}
+
+// Query returns the functions matching every set field of f, in file order.
+func (ix *Index) Query(f Filter) []Result {
+	callsTargets := ix.lookup(f.Calls)
+	calledBy := make(map[int]bool)
+	for _, i := range ix.lookup(f.CalledBy) {
+		for _, j := range ix.callees[i] {
+			calledBy[j] = true
+		}
+	}
+	file := strings.TrimPrefix(filepath.ToSlash(f.File), "./")
+
+	results := []Result{}
+	for i, fn := range ix.Functions {
+		switch {
+		case f.Func != "" && !matchName(f.Func, fn):
+			continue
+		case file != "" && !matchFile(file, fn.File):
+			continue
+		case f.Calls != "" && !ix.callsAny(i, callsTargets):
+			continue
+		case f.CalledBy != "" && !calledBy[i]:
+			continue
+		case f.Returns != "" && !returns(fn, f.Returns):
+			continue
+		case f.Unused && (len(ix.callers[i]) > 0 || IsEntryPoint(fn)):
+			continue
+		}
+		results = append(results, ix.result(i))
+	}
+	return results
+}
+
+// Callers returns the functions that call fn.
+func (ix *Index) Callers(fn treesitter.FunctionInfo) []treesitter.FunctionInfo {
+	return ix.related(fn, ix.callers)
+}
+
+// Callees returns the project functions fn calls.
+func (ix *Index) Callees(fn treesitter.FunctionInfo) []treesitter.FunctionInfo {
+	return ix.related(fn, ix.callees)
+}
+
+func (ix *Index) related(fn treesitter.FunctionInfo, edges [][]int) []treesitter.FunctionInfo {
+	i, ok := ix.find(fn)
+	if !ok {
+		return nil
+	}
+	out := make([]treesitter.FunctionInfo, 0, len(edges[i]))
+	for _, j := range edges[i] {
+		out = append(out, ix.Functions[j])
+	}
+	return out
+}
+
+// find locates fn in the index by file and start line.
+func (ix *Index) find(fn treesitter.FunctionInfo) (int, bool) {
+	file := treesitter.RepoRelativePath(fn.File)
+	for _, i := range ix.byName[fn.Name] {
+		if f := ix.Functions[i]; f.File == file && f.StartLine == fn.StartLine {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lookup returns the functions a --calls or --called-by argument names.
+func (ix *Index) lookup(name string) []int {
+	if name == "" {
+		return nil
+	}
+	var out []int
+	for i, fn := range ix.Functions {
+		if matchName(name, fn) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (ix *Index) callsAny(i int, targets []int) bool {
+	for _, j := range ix.callees[i] {
+		for _, t := range targets {
+			if j == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ix *Index) result(i int) Result {
+	r := Result{FunctionInfo: ix.Functions[i], Callers: []string{}, Callees: []string{}}
+	for _, j := range ix.callers[i] {
+		r.Callers = appendUnique(r.Callers, codegraph.DisplayName(ix.Functions[j]))
+	}
+	for _, j := range ix.callees[i] {
+		r.Callees = appendUnique(r.Callees, codegraph.DisplayName(ix.Functions[j]))
+	}
+	return r
+}
+
+// matchName matches a Name or Type.Method pattern against fn.
+func matchName(pattern string, fn treesitter.FunctionInfo) bool {
+	target := fn.Name
+	if strings.Contains(pattern, ".") {
+		target = codegraph.DisplayName(fn)
+	}
+	ok, err := path.Match(pattern, target)
+	return err == nil && ok
+}
+
+// matchFile matches a file, a directory prefix or a wildcard pattern.
+func matchFile(pattern, file string) bool {
+	if ok, err := path.Match(pattern, file); err == nil && ok {
+		return true
+	}
+	dir := strings.TrimSuffix(pattern, "/")
+	return file == dir || strings.HasPrefix(file, dir+"/")
+}
+
+// returns reports whether one of fn's results has the given type.
+func returns(fn treesitter.FunctionInfo, typ string) bool {
+	for _, r := range fn.Returns {
+		if r == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEntryPoint reports whether fn is called by the runtime or the test driver rather
+// than by project code.
+func IsEntryPoint(fn treesitter.FunctionInfo) bool {
+	if fn.Receiver == "" && (fn.Name == "main" || fn.Name == "init") {
+		return true
+	}
+	if !strings.HasSuffix(fn.File, "_test.go") {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if strings.HasPrefix(fn.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,115 @@
+// internal/mcp/protocol.go
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ------------------------------------------------------------------------------
+// JSON-RPC 2.0 over the MCP stdio transport (one message per line)
+// ------------------------------------------------------------------------------
+
+// message is a request, response or notification.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// readMessage returns the next non-empty line as a message. Lines may be arbitrarily
+// long, since tool arguments can carry whole files.
+func readMessage(r *bufio.Reader) (*message, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var msg message
+		if jsonErr := json.Unmarshal(line, &msg); jsonErr != nil {
+			return nil, &rpcError{Code: codeParseError, Message: jsonErr.Error()}
+		}
+		return &msg, nil
+	}
+}
+
+// writer serializes messages from concurrent handlers onto the output stream.
+type writer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *writer) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.out.Write(append(body, '\n'))
+	return err
+}
+
+// ------------------------------------------------------------------------------
+// MCP types (the subset this server uses)
+// ------------------------------------------------------------------------------
+
+// supportedVersions are the protocol revisions this server speaks, newest first.
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+type InitializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	ClientInfo      struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"clientInfo"`
+}
+
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult reports tool failures in-band with IsError, so the model can see them.
+type CallToolResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
@@ -0,0 +1,160 @@
+// internal/mcp/server.go
+
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/redact"
+)
+
+// Server is a Model Context Protocol server exposing PRBuddy's repository knowledge
+// as tools, over a pair of streams (usually stdio).
+type Server struct {
+	in    *bufio.Reader
+	out   *writer
+	tools []tool
+
+	pending sync.WaitGroup // Tool calls in flight
+}
+
+// NewServer returns a server reading requests from in and writing to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:    bufio.NewReader(in),
+		out:   &writer{out: out},
+		tools: defaultTools(),
+	}
+}
+
+// Run serves until the client closes the input stream.
+func (s *Server) Run() error {
+	defer s.pending.Wait()
+	for {
+		req, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			id := json.RawMessage("null")
+			s.out.write(&message{ID: &id, Error: rpcErr})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.dispatch(req)
+	}
+}
+
+// dispatch handles one message. Tool calls run in the background because several of
+// them call the LLM, and clients keep sending pings meanwhile.
+func (s *Server) dispatch(req *message) {
+	if req.ID == nil {
+		return // notifications/initialized, notifications/cancelled: nothing to do
+	}
+
+	if req.Method == "tools/call" {
+		s.pending.Add(1)
+		go func() {
+			defer s.pending.Done()
+			result, err := s.callTool(req.Params)
+			s.reply(req, result, err)
+		}()
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": s.toolList()}
+	default:
+		err = &rpcError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+	}
+	s.reply(req, result, err)
+}
+
+func (s *Server) reply(req *message, result interface{}, err error) {
+	resp := &message{ID: req.ID}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+	if writeErr := s.out.write(resp); writeErr != nil {
+		logrus.Errorf("Failed to write MCP response: %v", writeErr)
+	}
+}
+
+func (s *Server) initialize(raw json.RawMessage) (interface{}, error) {
+	var params InitializeParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	// Answer with the client's revision when we speak it, otherwise our newest.
+	version := supportedVersions[0]
+	for _, v := range supportedVersions {
+		if v == params.ProtocolVersion {
+			version = v
+		}
+	}
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+		"serverInfo":      map[string]string{"name": "prbuddy-go", "version": "1.0.0"},
+		"instructions": "Tools for the git repository this server runs in: what changed, the function-level " +
+			"project map, the developer's DCE task list, and PR drafts.",
+	}, nil
+}
+
+func (s *Server) toolList() []Tool {
+	list := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		list = append(list, t.Tool)
+	}
+	return list
+}
+
+func (s *Server) callTool(raw json.RawMessage) (interface{}, error) {
+	var params CallToolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	for _, t := range s.tools {
+		if t.Name != params.Name {
+			continue
+		}
+		args := params.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		text, err := t.run(args)
+		if err != nil {
+			text, _ = redact.Default().Redact(err.Error())
+			return &CallToolResult{Content: []Content{{Type: "text", Text: text}}, IsError: true}, nil
+		}
+		// Results leave the repository, so they get the same redaction as LLM prompts.
+		text, found := redact.Default().Redact(text)
+		if len(found) > 0 {
+			logrus.Warnf("Redacted from %s result: %s", t.Name, redact.Summary(found))
+		}
+		return &CallToolResult{Content: []Content{{Type: "text", Text: text}}}, nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}
+}
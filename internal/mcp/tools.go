@@ -0,0 +1,287 @@
+// internal/mcp/tools.go
+
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/mapquery"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// maxDiffLines caps the diff returned by what_changed.
+const maxDiffLines = 2000
+
+// maxProjectMapFunctions caps the functions returned by project_map; callers narrow
+// larger repositories with the path argument.
+const maxProjectMapFunctions = 500
+
+// tool is an MCP tool and the function that runs it on raw JSON arguments.
+type tool struct {
+	Tool
+	run func(args json.RawMessage) (string, error)
+}
+
+// newTool adapts a typed handler, decoding the call's arguments into Args.
+func newTool[Args any](name, description string, schema map[string]interface{}, handler func(Args) (string, error)) tool {
+	return tool{
+		Tool: Tool{Name: name, Description: description, InputSchema: schema},
+		run: func(raw json.RawMessage) (string, error) {
+			var args Args
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			return handler(args)
+		},
+	}
+}
+
+// objectSchema builds a JSON Schema object from property schemas.
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string, enum ...string) map[string]interface{} {
+	property := map[string]interface{}{"type": "string", "description": description}
+	if len(enum) > 0 {
+		property["enum"] = enum
+	}
+	return property
+}
+
+func defaultTools() []tool {
+	return []tool{
+		newTool("what_changed",
+			"Unified diff of the repository's changes: uncommitted work (staged, unstaged and untracked files) or the last commit.",
+			objectSchema(map[string]interface{}{
+				"scope": stringProperty("Which changes to show (default: local)", "local", "last_commit"),
+			}),
+			whatChanged),
+		newTool("project_map",
+			"Function-level map of the Go code: every function and method with its file, lines, signature, return types and the calls it makes.",
+			objectSchema(map[string]interface{}{
+				"path": stringProperty("Only include files under this repo-relative path prefix"),
+			}),
+			projectMap),
+		newTool("find_function",
+			"Locate a Go function or method (Name or Type.Name) and return its source, location, the calls it makes and its callers.",
+			objectSchema(map[string]interface{}{
+				"name": stringProperty("Function name, or Type.Method for a method"),
+				"file": stringProperty("Optional repo-relative file to search in"),
+			}, "name"),
+			findFunction),
+		newTool("task_list",
+			"The developer's current task list from the PRBuddy DCE (dynamic context engine) session, with open and completed tasks.",
+			objectSchema(map[string]interface{}{}),
+			taskList),
+		newTool("draft_pr",
+			"Generate a pull request title and description for the latest commit using the local LLM.",
+			objectSchema(map[string]interface{}{}),
+			draftPR),
+		newTool("saved_drafts",
+			"List the PR drafts PRBuddy has saved, or return the saved draft conversation for one commit.",
+			objectSchema(map[string]interface{}{
+				"commit": stringProperty("Commit hash (at least 7 characters) whose draft to return; omit to list drafts"),
+				"branch": stringProperty("Branch the draft was saved under (default: current branch)"),
+			}),
+			savedDrafts),
+	}
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ------------------------------------------------------------------------------
+// Tool handlers
+// ------------------------------------------------------------------------------
+
+func whatChanged(args struct {
+	Scope string `json:"scope"`
+}) (string, error) {
+	mode := utils.DiffAllLocalChanges
+	switch args.Scope {
+	case "", "local":
+	case "last_commit":
+		mode = utils.DiffSinceLastCommit
+	default:
+		return "", fmt.Errorf("unknown scope %q; use local or last_commit", args.Scope)
+	}
+
+	diff, err := utils.GetDiffs(mode)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "No changes.", nil
+	}
+	return contextpkg.TruncateDiff(diff, maxDiffLines), nil
+}
+
+// buildProjectMap maps the repository's Go functions with repo-relative paths.
+func buildProjectMap() ([]treesitter.FunctionInfo, string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get repository path: %w", err)
+	}
+	projectMap, err := treesitter.NewGoParser().BuildProjectMap(repoPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build project map: %w", err)
+	}
+	// Project map paths start with the repository name; tools speak repo-relative paths.
+	for i := range projectMap.Functions {
+		projectMap.Functions[i].File = treesitter.RepoRelativePath(projectMap.Functions[i].File)
+	}
+	return projectMap.Functions, repoPath, nil
+}
+
+func projectMap(args struct {
+	Path string `json:"path"`
+}) (string, error) {
+	functions, _, err := buildProjectMap()
+	if err != nil {
+		return "", err
+	}
+	prefix := strings.TrimPrefix(filepath.ToSlash(args.Path), "./")
+
+	result := struct {
+		Functions []treesitter.FunctionInfo `json:"functions"`
+		Truncated int                       `json:"truncated,omitempty"` // Functions left out
+	}{Functions: []treesitter.FunctionInfo{}}
+	for _, fn := range functions {
+		if !strings.HasPrefix(fn.File, prefix) {
+			continue
+		}
+		if len(result.Functions) == maxProjectMapFunctions {
+			result.Truncated++
+			continue
+		}
+		result.Functions = append(result.Functions, fn)
+	}
+	return toJSON(result)
+}
+
+// functionMatch is a find_function result.
+type functionMatch struct {
+	treesitter.FunctionInfo
+	Source  string   `json:"source"`
+	Callers []string `json:"callers"`
+}
+
+func findFunction(args struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}) (string, error) {
+	if strings.TrimSpace(args.Name) == "" {
+		return "", errors.New("name is required")
+	}
+	functions, repoPath, err := buildProjectMap()
+	if err != nil {
+		return "", err
+	}
+
+	receiver, name := "", args.Name
+	if i := strings.LastIndex(args.Name, "."); i >= 0 {
+		receiver, name = args.Name[:i], args.Name[i+1:]
+	}
+	file := strings.TrimPrefix(filepath.ToSlash(args.File), "./")
+
+	index := mapquery.New(repoPath, functions)
+	matches := []functionMatch{}
+	for _, fn := range index.Functions {
+		if fn.Name != name || (receiver != "" && fn.Receiver != receiver) || (file != "" && fn.File != file) {
+			continue
+		}
+		match := functionMatch{FunctionInfo: fn, Source: functionSource(repoPath, fn), Callers: []string{}}
+		for _, caller := range index.Callers(fn) {
+			match.Callers = append(match.Callers, fmt.Sprintf("%s (%s:%d)", codegraph.DisplayName(caller), caller.File, caller.StartLine))
+		}
+		matches = append(matches, match)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no function named %s found", args.Name)
+	}
+	return toJSON(matches)
+}
+
+func functionSource(repoPath string, fn treesitter.FunctionInfo) string {
+	data, err := os.ReadFile(filepath.Join(repoPath, fn.File))
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if fn.StartLine < 1 || fn.EndLine > len(lines) {
+		return ""
+	}
+	return strings.Join(lines[fn.StartLine-1:fn.EndLine], "\n")
+}
+
+func taskList(struct{}) (string, error) {
+	state, err := dce.LoadTaskState()
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		return "No DCE session has saved a task list yet. Start one with `prbuddy-go dce`.", nil
+	}
+	return toJSON(state)
+}
+
+func draftPR(struct{}) (string, error) {
+	commitMessage, diffs, err := llm.GeneratePreDraftPR()
+	if err != nil {
+		return "", err
+	}
+	return llm.GenerateDraftPR(commitMessage, diffs)
+}
+
+func savedDrafts(args struct {
+	Commit string `json:"commit"`
+	Branch string `json:"branch"`
+}) (string, error) {
+	if args.Commit == "" {
+		drafts, err := llm.ListDrafts()
+		if err != nil {
+			return "", err
+		}
+		if len(drafts) == 0 {
+			return "No saved drafts.", nil
+		}
+		return toJSON(drafts)
+	}
+
+	if len(args.Commit) < 7 {
+		return "", errors.New("commit must have at least 7 characters")
+	}
+	branch := args.Branch
+	if branch == "" {
+		current, err := utils.GetCurrentBranch()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = strings.TrimSpace(current)
+	}
+	messages, err := llm.LoadDraftContext(branch, args.Commit)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(messages)
+}
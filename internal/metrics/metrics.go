@@ -0,0 +1,231 @@
+// internal/metrics/metrics.go
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Call is the measurement of one LLM call. Token counts and model-side durations come
+// from Ollama's final response chunk; Latency is measured by the client.
+type Call struct {
+	Command            string
+	Model              string
+	PromptTokens       int
+	CompletionTokens   int
+	LoadDuration       time.Duration
+	PromptEvalDuration time.Duration
+	EvalDuration       time.Duration
+	Latency            time.Duration
+	Failed             bool
+}
+
+// Aggregate sums calls.
+type Aggregate struct {
+	Calls              int           `json:"calls"`
+	Errors             int           `json:"errors"`
+	PromptTokens       int           `json:"prompt_tokens"`
+	CompletionTokens   int           `json:"completion_tokens"`
+	LoadDuration       time.Duration `json:"load_duration_ns"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration_ns"`
+	EvalDuration       time.Duration `json:"eval_duration_ns"`
+	Latency            time.Duration `json:"latency_ns"`
+}
+
+func (a *Aggregate) add(c Call) {
+	a.Calls++
+	if c.Failed {
+		a.Errors++
+	}
+	a.PromptTokens += c.PromptTokens
+	a.CompletionTokens += c.CompletionTokens
+	a.LoadDuration += c.LoadDuration
+	a.PromptEvalDuration += c.PromptEvalDuration
+	a.EvalDuration += c.EvalDuration
+	a.Latency += c.Latency
+}
+
+func (a *Aggregate) merge(b Aggregate) {
+	a.Calls += b.Calls
+	a.Errors += b.Errors
+	a.PromptTokens += b.PromptTokens
+	a.CompletionTokens += b.CompletionTokens
+	a.LoadDuration += b.LoadDuration
+	a.PromptEvalDuration += b.PromptEvalDuration
+	a.EvalDuration += b.EvalDuration
+	a.Latency += b.Latency
+}
+
+// TokensPerSecond is the generation speed: completion tokens per second of evaluation.
+func (a Aggregate) TokensPerSecond() float64 {
+	if a.EvalDuration <= 0 {
+		return 0
+	}
+	return float64(a.CompletionTokens) / a.EvalDuration.Seconds()
+}
+
+// PromptTokensPerSecond is the prompt processing speed.
+func (a Aggregate) PromptTokensPerSecond() float64 {
+	if a.PromptEvalDuration <= 0 {
+		return 0
+	}
+	return float64(a.PromptTokens) / a.PromptEvalDuration.Seconds()
+}
+
+// AverageLatency is the mean wall-clock time per call.
+func (a Aggregate) AverageLatency() time.Duration {
+	if a.Calls == 0 {
+		return 0
+	}
+	return a.Latency / time.Duration(a.Calls)
+}
+
+// key identifies a (command, model) series.
+type key struct {
+	Command string
+	Model   string
+}
+
+// Registry accumulates calls per command and model. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.Mutex
+	series map[key]*Aggregate
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{series: make(map[key]*Aggregate)}
+}
+
+// Default collects the calls made by this process.
+var Default = NewRegistry()
+
+// Record adds a call to the registry.
+func (r *Registry) Record(c Call) {
+	if c.Model == "" {
+		c.Model = "unknown"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{c.Command, c.Model}
+	if r.series[k] == nil {
+		r.series[k] = &Aggregate{}
+	}
+	r.series[k].add(c)
+}
+
+// Series is the aggregate for one (command, model) pair.
+type Series struct {
+	Command string `json:"command"`
+	Model   string `json:"model"`
+	Aggregate
+}
+
+// Snapshot is a consistent copy of a registry, with per-command and per-model roll-ups.
+type Snapshot struct {
+	Total     Aggregate            `json:"total"`
+	ByCommand map[string]Aggregate `json:"by_command"`
+	ByModel   map[string]Aggregate `json:"by_model"`
+	Series    []Series             `json:"series"`
+}
+
+// Snapshot copies the registry.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := Snapshot{ByCommand: make(map[string]Aggregate), ByModel: make(map[string]Aggregate)}
+	for k, agg := range r.series {
+		s.Series = append(s.Series, Series{Command: k.Command, Model: k.Model, Aggregate: *agg})
+		s.Total.merge(*agg)
+
+		byCommand := s.ByCommand[k.Command]
+		byCommand.merge(*agg)
+		s.ByCommand[k.Command] = byCommand
+
+		byModel := s.ByModel[k.Model]
+		byModel.merge(*agg)
+		s.ByModel[k.Model] = byModel
+	}
+	sort.Slice(s.Series, func(i, j int) bool {
+		if s.Series[i].Command != s.Series[j].Command {
+			return s.Series[i].Command < s.Series[j].Command
+		}
+		return s.Series[i].Model < s.Series[j].Model
+	})
+	return s
+}
+
+// Record adds a call to the Default registry.
+func Record(c Call) {
+	Default.Record(c)
+}
+
+// FormatTable renders per-model and per-command tables for the terminal.
+func FormatTable(s Snapshot) string {
+	if s.Total.Calls == 0 {
+		return "No LLM calls recorded.\n"
+	}
+	var b strings.Builder
+	writeTable(&b, "MODEL", s.ByModel)
+	b.WriteString("\n")
+	writeTable(&b, "COMMAND", s.ByCommand)
+	return b.String()
+}
+
+func writeTable(w io.Writer, label string, rows map[string]Aggregate) {
+	names := make([]string, 0, len(rows))
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCALLS\tERRORS\tPROMPT TOK\tOUTPUT TOK\tPROMPT TOK/S\tOUTPUT TOK/S\tAVG LATENCY\n", label)
+	for _, name := range names {
+		a := rows[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f\t%.1f\t%s\n", name, a.Calls, a.Errors,
+			a.PromptTokens, a.CompletionTokens, a.PromptTokensPerSecond(), a.TokensPerSecond(),
+			a.AverageLatency().Round(time.Millisecond))
+	}
+	tw.Flush()
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format, one
+// series per (command, model) pair.
+func WritePrometheus(w io.Writer, s Snapshot) {
+	counters := []struct {
+		name, help string
+		value      func(Aggregate) float64
+	}{
+		{"prbuddy_llm_calls_total", "LLM calls made.", func(a Aggregate) float64 { return float64(a.Calls) }},
+		{"prbuddy_llm_errors_total", "LLM calls that failed.", func(a Aggregate) float64 { return float64(a.Errors) }},
+		{"prbuddy_llm_prompt_tokens_total", "Prompt tokens evaluated.", func(a Aggregate) float64 { return float64(a.PromptTokens) }},
+		{"prbuddy_llm_completion_tokens_total", "Tokens generated.", func(a Aggregate) float64 { return float64(a.CompletionTokens) }},
+		{"prbuddy_llm_load_seconds_total", "Time spent loading models.", func(a Aggregate) float64 { return a.LoadDuration.Seconds() }},
+		{"prbuddy_llm_prompt_eval_seconds_total", "Time spent evaluating prompts.", func(a Aggregate) float64 { return a.PromptEvalDuration.Seconds() }},
+		{"prbuddy_llm_eval_seconds_total", "Time spent generating tokens.", func(a Aggregate) float64 { return a.EvalDuration.Seconds() }},
+		{"prbuddy_llm_latency_seconds_total", "Wall-clock time of LLM calls.", func(a Aggregate) float64 { return a.Latency.Seconds() }},
+	}
+
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+		for _, series := range s.Series {
+			fmt.Fprintf(w, "%s{command=\"%s\",model=\"%s\"} %g\n", c.name,
+				escapeLabel(series.Command), escapeLabel(series.Model), c.value(series.Aggregate))
+		}
+	}
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
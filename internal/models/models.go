@@ -0,0 +1,119 @@
+// internal/models/models.go
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// ConfigFile holds the persisted model selection in .git/pr_buddy_db.
+const ConfigFile = "models.json"
+
+// Task identifies a kind of LLM work that can be routed to its own model.
+type Task string
+
+const (
+	TaskDefault   Task = ""
+	TaskChat      Task = "chat"       // quickassist and DCE conversations
+	TaskCommitMsg Task = "commit-msg" // commit message drafting
+	TaskDraft     Task = "draft"      // PR drafts
+	TaskReview    Task = "review"     // code review
+	TaskSummary   Task = "summary"    // `what` and `guide` summaries
+	TaskChangelog Task = "changelog"  // release notes
+	TaskSplit     Task = "split"      // commit splitting
+	TaskTestGen   Task = "test-gen"   // test generation
+)
+
+// Tasks lists every routable task.
+var Tasks = []Task{TaskChat, TaskCommitMsg, TaskDraft, TaskReview, TaskSummary, TaskChangelog, TaskSplit, TaskTestGen}
+
+// ParseTask validates a task name from the command line.
+func ParseTask(name string) (Task, error) {
+	for _, t := range Tasks {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return TaskDefault, fmt.Errorf("unknown task %q", name)
+}
+
+// Config is the persisted selection: a default model and optional per-task overrides,
+// e.g. a small fast model for commit messages and a larger one for drafts and reviews.
+type Config struct {
+	Default string          `json:"default,omitempty"`
+	Tasks   map[Task]string `json:"tasks,omitempty"`
+}
+
+// ModelFor returns the configured model for task, falling back to the default.
+func (c Config) ModelFor(task Task) string {
+	if model := c.Tasks[task]; model != "" {
+		return model
+	}
+	return c.Default
+}
+
+// RoutedTasks returns the tasks with an explicit model, sorted by name.
+func (c Config) RoutedTasks() []Task {
+	var tasks []Task
+	for t, model := range c.Tasks {
+		if model != "" {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i] < tasks[j] })
+	return tasks
+}
+
+func configPath() (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository path: %w", err)
+	}
+	return filepath.Join(repoPath, ".git", "pr_buddy_db", ConfigFile), nil
+}
+
+// LoadConfig reads the persisted selection. A missing file, or running outside a
+// repository, yields an empty Config.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	path, err := configPath()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read model config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse model config: %w", err)
+	}
+	return cfg, nil
+}
+
+// SaveConfig persists the selection.
+func SaveConfig(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	data, err := utils.MarshalJSON(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal model config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write model config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// internal/models/ollama.go
+
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is where Ollama listens unless PRBUDDY_LLM_ENDPOINT says otherwise.
+const DefaultEndpoint = "http://localhost:11434"
+
+// Endpoint returns the Ollama base URL.
+func Endpoint() string {
+	if endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	return DefaultEndpoint
+}
+
+// Details are the model attributes Ollama reports in /api/tags and /api/show.
+type Details struct {
+	Format            string `json:"format,omitempty"`
+	Family            string `json:"family,omitempty"`
+	ParameterSize     string `json:"parameter_size,omitempty"`
+	QuantizationLevel string `json:"quantization_level,omitempty"`
+}
+
+// Model is an installed model as listed by /api/tags.
+type Model struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest,omitempty"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Details    Details   `json:"details"`
+}
+
+// Info is what /api/show reports about a single model.
+type Info struct {
+	Name          string                 `json:"name"`
+	Details       Details                `json:"details"`
+	Parameters    string                 `json:"parameters,omitempty"`
+	ModelInfo     map[string]interface{} `json:"model_info,omitempty"`
+	ContextLength int                    `json:"context_length,omitempty"`
+}
+
+// PullProgress is one status line streamed by /api/pull.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// List returns the installed models, most recently modified first.
+func List(endpoint string) ([]Model, error) {
+	resp, err := http.Get(endpoint + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Models []Model `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	sort.SliceStable(result.Models, func(i, j int) bool {
+		return result.Models[i].ModifiedAt.After(result.Models[j].ModifiedAt)
+	})
+	return result.Models, nil
+}
+
+// Installed reports whether name is among models, accepting a missing ":latest" tag.
+func Installed(models []Model, name string) bool {
+	for _, m := range models {
+		if m.Name == name || m.Name == name+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// Show returns details for one model.
+func Show(endpoint, name string) (*Info, error) {
+	resp, err := post(endpoint+"/api/show", map[string]interface{}{"model": name})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("model %q is not installed", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	info := Info{Name: name}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	info.Name = name
+	for key, value := range info.ModelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			info.ContextLength = int(n)
+		}
+	}
+	return &info, nil
+}
+
+// Pull downloads a model, reporting each status line to progress (which may be nil).
+func Pull(endpoint, name string, progress func(PullProgress)) error {
+	resp, err := post(endpoint+"/api/pull", map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var last PullProgress
+	for scanner.Scan() {
+		var p PullProgress
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		if p.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", name, p.Error)
+		}
+		if progress != nil {
+			progress(p)
+		}
+		last = p
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	if last.Status != "success" {
+		return fmt.Errorf("pull of %s ended without success (last status %q)", name, last.Status)
+	}
+	return nil
+}
+
+// WaitReady asks Ollama to load the model and polls with backoff until it answers or
+// the timeout expires. Loading a large model can take far longer than a fixed sleep.
+func WaitReady(endpoint, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := 250 * time.Millisecond
+	var lastErr error
+	for {
+		// A generate request without a prompt only loads the model into memory.
+		resp, err := post(endpoint+"/api/generate", map[string]interface{}{"model": name, "stream": false})
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			lastErr = fmt.Errorf("ollama returned status %d", resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("model %s not ready after %v: %w", name, timeout, lastErr)
+		}
+		time.Sleep(delay)
+		if delay < 2*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+func post(url string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,83 @@
+// internal/patch/apply.go
+
+package patch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Check reports whether the patch applies cleanly to the working tree, using
+// git apply --check. Nothing is changed.
+func (p *Patch) Check() error {
+	if err := p.gitApply("--check"); err != nil {
+		return fmt.Errorf("the changes do not apply cleanly: %w", err)
+	}
+	return nil
+}
+
+// Apply checks the patch, snapshots the files it touches and applies it to the working
+// tree. The snapshot is what Undo restores.
+func (p *Patch) Apply() (*Snapshot, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	snap, err := takeSnapshot(p.Root, p.Files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot files before applying: %w", err)
+	}
+	if err := p.gitApply(); err != nil {
+		return nil, fmt.Errorf("failed to apply changes: %w", err)
+	}
+	if err := snap.save(p.Root); err != nil {
+		return snap, fmt.Errorf("changes applied, but the undo snapshot could not be saved: %w", err)
+	}
+	return snap, nil
+}
+
+// gitApply runs git apply on the diff from the repository root. --recount tolerates
+// the wrong hunk line counts models often produce.
+func (p *Patch) gitApply(extra ...string) error {
+	f, err := os.CreateTemp("", "prbuddy-patch-*.diff")
+	if err != nil {
+		return fmt.Errorf("failed to create patch file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(p.Diff); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write patch file: %w", err)
+	}
+	f.Close()
+
+	args := append([]string{"-C", p.Root, "apply", "--recount", "--whitespace=nowarn"}, extra...)
+	_, err = utils.ExecGitRaw(nil, append(args, f.Name())...)
+	return err
+}
+
+// Preview writes the diff with added lines in green, removed lines in red and hunk
+// headers in cyan. Colors are dropped when w is not a terminal.
+func Preview(w io.Writer, diff string) {
+	header := color.New(color.Bold)
+	added := color.New(color.FgGreen)
+	removed := color.New(color.FgRed)
+	hunk := color.New(color.FgCyan)
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			header.Fprintln(w, line)
+		case strings.HasPrefix(line, "@@"):
+			hunk.Fprintln(w, line)
+		case strings.HasPrefix(line, "+"):
+			added.Fprintln(w, line)
+		case strings.HasPrefix(line, "-"):
+			removed.Fprintln(w, line)
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+}
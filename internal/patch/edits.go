@@ -0,0 +1,246 @@
+// internal/patch/edits.go
+
+package patch
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines around each change in generated hunks.
+const contextLines = 3
+
+// maxDiffCells bounds the line-matching table; larger changes become one replacement.
+const maxDiffCells = 4_000_000
+
+// diffFromEdits applies edits to the files in the repository at root in memory and
+// returns the result as a unified diff, with the paths it touches. Edits to the same
+// file apply in order, each to the result of the previous one.
+func diffFromEdits(root string, edits []Edit) (string, []string, error) {
+	var order []string
+	byPath := make(map[string][]Edit)
+	for _, e := range edits {
+		if _, ok := byPath[e.Path]; !ok {
+			order = append(order, e.Path)
+		}
+		byPath[e.Path] = append(byPath[e.Path], e)
+	}
+
+	var b strings.Builder
+	var touched []string
+	for _, path := range order {
+		abs, err := checkPath(root, path)
+		if err != nil {
+			return "", nil, err
+		}
+		original, err := os.ReadFile(abs)
+		existed := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("cannot read %s: %w", path, err)
+		}
+
+		content := string(original)
+		for _, e := range byPath[path] {
+			content, err = applyEdit(path, content, existed, e)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		if existed && content == string(original) {
+			continue
+		}
+		b.WriteString(unifiedDiff(path, string(original), content, !existed))
+		touched = append(touched, path)
+	}
+	return b.String(), touched, nil
+}
+
+// applyEdit replaces the single occurrence of e.Search in content.
+func applyEdit(path, content string, existed bool, e Edit) (string, error) {
+	if e.Search == "" {
+		if existed || content != "" {
+			return "", fmt.Errorf("%s: empty SEARCH block for a file that already exists", path)
+		}
+		return e.Replace, nil
+	}
+	if !existed && content == "" {
+		return "", fmt.Errorf("%s: file does not exist", path)
+	}
+	switch n := strings.Count(content, e.Search); n {
+	case 1:
+		return strings.Replace(content, e.Search, e.Replace, 1), nil
+	case 0:
+		return "", fmt.Errorf("%s: SEARCH block not found: %q", path, firstLine(e.Search))
+	default:
+		return "", fmt.Errorf("%s: SEARCH block matches %d places; it needs more context: %q", path, n, firstLine(e.Search))
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// op is one line of an edit script: ' ' kept, '-' removed or '+' added. Lines keep
+// their newline, so a missing final newline counts as a change.
+type op struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff renders the change from oldText to newText as a unified diff for path.
+func unifiedDiff(path, oldText, newText string, created bool) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	if created {
+		b.WriteString(fileHeader("/dev/null", path))
+	} else {
+		b.WriteString(fileHeader(path, path))
+	}
+
+	// oldPos[k] and newPos[k] count the old and new lines before ops[k].
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for k, o := range ops {
+		oldPos[k+1], newPos[k+1] = oldPos[k], newPos[k]
+		if o.kind != '+' {
+			oldPos[k+1]++
+		}
+		if o.kind != '-' {
+			newPos[k+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := max(i-contextLines, 0)
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			run := 0
+			for end+run < len(ops) && ops[end+run].kind == ' ' {
+				run++
+			}
+			if end+run == len(ops) || run > 2*contextLines {
+				end += min(run, contextLines)
+				break
+			}
+			end += run
+		}
+
+		oldCount, newCount := oldPos[end]-oldPos[start], newPos[end]-newPos[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldPos[start], oldCount), hunkRange(newPos[start], newCount))
+		for _, o := range ops[start:end] {
+			b.WriteByte(o.kind)
+			b.WriteString(strings.TrimSuffix(o.line, "\n"))
+			b.WriteByte('\n')
+			if !strings.HasSuffix(o.line, "\n") {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats one side of a hunk header; empty ranges point at the line before.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a minimal edit script from a to b using a longest common
+// subsequence of the lines between the common prefix and suffix.
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+func diffMiddle(a, b []string) []op {
+	var ops []op
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, op{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,332 @@
+// internal/patch/patch.go
+
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// ErrNoPatch is returned by FromResponse when a response suggests no code changes.
+var ErrNoPatch = errors.New("no code changes found in the response")
+
+// Edit is one search/replace block: the single occurrence of Search in Path is replaced
+// by Replace. An empty Search creates Path with Replace as its content.
+type Edit struct {
+	Path    string
+	Search  string
+	Replace string
+}
+
+// Patch is a set of suggested changes to the repository at Root, as a unified diff that
+// git apply accepts. Every path it touches has been checked to lie inside the
+// repository and not to be ignored by git.
+type Patch struct {
+	Root  string
+	Diff  string
+	Files []string // Repo-relative paths the diff touches, in diff order
+}
+
+// FromResponse collects the unified diffs and search/replace blocks in an LLM response
+// into a Patch for the repository at root. It returns ErrNoPatch when there are none,
+// and an error when an edit cannot be located or touches a path outside the repository
+// or one that git ignores.
+func FromResponse(root, response string) (*Patch, error) {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	diffs := extractDiffs(response)
+	edits, err := extractEdits(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 && len(edits) == 0 {
+		return nil, ErrNoPatch
+	}
+
+	p := &Patch{Root: root}
+	var b strings.Builder
+	for _, d := range diffs {
+		normalized, paths, err := normalizeDiff(d)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(normalized)
+		p.Files = appendUnique(p.Files, paths...)
+	}
+	if len(edits) > 0 {
+		diff, paths, err := diffFromEdits(root, edits)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(diff)
+		p.Files = appendUnique(p.Files, paths...)
+	}
+	if b.Len() == 0 {
+		return nil, ErrNoPatch
+	}
+	p.Diff = b.String()
+
+	for _, path := range p.Files {
+		if _, err := checkPath(root, path); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// checkPath confines a suggested path to the repository and refuses git-ignored files.
+func checkPath(root, path string) (string, error) {
+	rel, abs, err := utils.ResolveRepoPath(root, path)
+	if err != nil {
+		return "", err
+	}
+	if rel == "" {
+		return "", fmt.Errorf("invalid path %q", path)
+	}
+	if utils.IsGitIgnored(root, rel) {
+		return "", fmt.Errorf("%s is ignored by git and cannot be edited", rel)
+	}
+	return abs, nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !utils.StringSliceContains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+//------------------------------------------------------------------------------
+// Unified diffs
+//------------------------------------------------------------------------------
+
+var fencePattern = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+-]*)")
+
+// extractDiffs returns the unified diffs in a response: fenced blocks tagged diff or
+// patch, or whose first line is a diff header, and otherwise an unfenced diff.
+func extractDiffs(response string) []string {
+	lines := strings.Split(response, "\n")
+	var diffs []string
+	fenced := false
+	for i := 0; i < len(lines); i++ {
+		m := fencePattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		fenced = true
+		var body []string
+		j := i + 1
+		for ; j < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[j]), m[1]); j++ {
+			body = append(body, lines[j])
+		}
+		lang := strings.ToLower(m[2])
+		if lang == "diff" || lang == "patch" || lang == "udiff" || isDiffStart(body, 0) {
+			diffs = append(diffs, strings.Join(body, "\n"))
+		}
+		i = j
+	}
+	if fenced {
+		return diffs
+	}
+	for i := range lines {
+		if isDiffStart(lines, i) {
+			return []string{strings.Join(lines[i:], "\n")}
+		}
+	}
+	return nil
+}
+
+// isDiffStart reports whether a diff header starts at the first non-blank line from i.
+func isDiffStart(lines []string, i int) bool {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i >= len(lines) {
+		return false
+	}
+	if strings.HasPrefix(lines[i], "diff --git ") {
+		return true
+	}
+	return strings.HasPrefix(lines[i], "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+}
+
+// normalizeDiff rewrites a diff with the headers git apply expects, whatever prefixes
+// and extended headers the model used, and returns the paths it touches. New, deleted
+// and renamed files are recognised from the "---" and "+++" lines alone.
+func normalizeDiff(diff string) (string, []string, error) {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	var b strings.Builder
+	var paths []string
+	inFile, blanks := false, 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			oldPath, newPath := headerPath(line), headerPath(lines[i+1])
+			if oldPath == "" || newPath == "" {
+				return "", nil, fmt.Errorf("malformed diff header %q", line)
+			}
+			b.WriteString(fileHeader(oldPath, newPath))
+			for _, p := range []string{oldPath, newPath} {
+				if p != "/dev/null" {
+					paths = appendUnique(paths, p)
+				}
+			}
+			inFile = true
+			i++
+			continue
+		}
+		if !inFile {
+			continue // Extended headers and anything before the first file
+		}
+		switch {
+		case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, " "), strings.HasPrefix(line, "+"),
+			strings.HasPrefix(line, "-"), strings.HasPrefix(line, "\\"):
+			b.WriteString(strings.Repeat(" \n", blanks) + line + "\n")
+			blanks = 0
+		case line == "":
+			blanks++ // Models often strip the space from blank context lines
+		default:
+			inFile, blanks = false, 0 // "diff --git", "index" or trailing prose
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil, fmt.Errorf("diff has no file headers")
+	}
+	return b.String(), paths, nil
+}
+
+// headerPath extracts the path from a "---" or "+++" line, without a/ or b/ prefix.
+func headerPath(line string) string {
+	path := strings.TrimSpace(line[4:])
+	if tab := strings.Index(path, "\t"); tab >= 0 {
+		path = path[:tab]
+	}
+	path = strings.Trim(strings.TrimSpace(path), `"`)
+	if path == "/dev/null" {
+		return path
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		path = path[2:]
+	}
+	return path
+}
+
+// fileHeader renders a git diff header. The "diff --git" line keeps --recount from
+// reading the next file's "---" line as a removal, and git then needs new, deleted and
+// renamed files spelled out.
+func fileHeader(oldPath, newPath string) string {
+	var b strings.Builder
+	switch {
+	case oldPath == "/dev/null":
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\nnew file mode 100644\n", newPath, newPath)
+		fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s\n", newPath)
+	case newPath == "/dev/null":
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\ndeleted file mode 100644\n", oldPath, oldPath)
+		fmt.Fprintf(&b, "--- a/%s\n+++ /dev/null\n", oldPath)
+	default:
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", oldPath, newPath)
+		if oldPath != newPath {
+			fmt.Fprintf(&b, "rename from %s\nrename to %s\n", oldPath, newPath)
+		}
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", oldPath, newPath)
+	}
+	return b.String()
+}
+
+//------------------------------------------------------------------------------
+// Search/replace blocks
+//------------------------------------------------------------------------------
+
+var (
+	searchMarker  = regexp.MustCompile(`^<{5,9} ?SEARCH\s*$`)
+	dividerMarker = regexp.MustCompile(`^={5,9}\s*$`)
+	replaceMarker = regexp.MustCompile(`^>{5,9} ?REPLACE\s*$`)
+)
+
+// extractEdits returns the search/replace blocks in a response:
+//
+//	path/to/file.go
+//	<<<<<<< SEARCH
+//	old lines
+//	=======
+//	new lines
+//	>>>>>>> REPLACE
+//
+// The path is the last line before the block that looks like one (code fences aside);
+// consecutive blocks without a path line of their own reuse the previous path.
+func extractEdits(response string) ([]Edit, error) {
+	lines := strings.Split(response, "\n")
+	var edits []Edit
+	candidate, lastPath := "", ""
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if !searchMarker.MatchString(line) {
+			if path := pathLine(line); path != "" {
+				candidate = path
+			} else if strings.TrimSpace(line) != "" && !fencePattern.MatchString(line) {
+				candidate = ""
+			}
+			continue
+		}
+
+		path := candidate
+		if path == "" {
+			path = lastPath
+		}
+		if path == "" {
+			return nil, fmt.Errorf("SEARCH block on line %d does not name a file", i+1)
+		}
+
+		var search, replace []string
+		section := &search
+		j := i + 1
+		for ; j < len(lines); j++ {
+			l := strings.TrimRight(lines[j], "\r")
+			if section == &search && dividerMarker.MatchString(l) {
+				section = &replace
+				continue
+			}
+			if section == &replace && replaceMarker.MatchString(l) {
+				break
+			}
+			*section = append(*section, l)
+		}
+		if j == len(lines) {
+			return nil, fmt.Errorf("unterminated SEARCH block for %s on line %d", path, i+1)
+		}
+		edits = append(edits, Edit{Path: path, Search: joinLines(search), Replace: joinLines(replace)})
+		candidate, lastPath, i = "", path, j
+	}
+	return edits, nil
+}
+
+// pathLine returns the file path on a line such as "cmd/root.go" or "**`cmd/root.go`**",
+// or "" if the line is not just a path.
+func pathLine(line string) string {
+	path := strings.TrimSpace(line)
+	path = strings.TrimPrefix(path, "#")
+	path = strings.Trim(strings.TrimSpace(path), "*`'\":")
+	if path == "" || strings.ContainsAny(path, " \t<>=") || strings.HasPrefix(path, "```") {
+		return ""
+	}
+	if !strings.Contains(path, ".") && !strings.Contains(path, "/") {
+		return ""
+	}
+	return path
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
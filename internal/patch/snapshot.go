@@ -0,0 +1,153 @@
+// internal/patch/snapshot.go
+
+package patch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNothingToUndo is returned by Undo when no applied patch is recorded.
+var ErrNothingToUndo = errors.New("no applied changes to undo")
+
+// ErrChangedSinceApply is returned by Undo when files were edited after the patch was
+// applied, so restoring them would discard that work.
+var ErrChangedSinceApply = errors.New("files changed since the suggested edit was applied")
+
+// Snapshot records the files a patch touched as they were before it was applied.
+// Snapshots are kept in .git/pr_buddy_db/patches, one JSON file each.
+type Snapshot struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Files     []FileState `json:"files"`
+}
+
+// FileState is one file before and after a patch.
+type FileState struct {
+	Path        string      `json:"path"`
+	Existed     bool        `json:"existed"`
+	Mode        os.FileMode `json:"mode,omitempty"`
+	Content     []byte      `json:"content,omitempty"`
+	AppliedHash string      `json:"applied_hash,omitempty"` // sha256 after applying; empty if the patch removed the file
+}
+
+func snapshotDir(root string) string {
+	return filepath.Join(root, ".git", "pr_buddy_db", "patches")
+}
+
+func takeSnapshot(root string, paths []string) (*Snapshot, error) {
+	now := time.Now()
+	snap := &Snapshot{ID: now.Format("20060102-150405.000000"), CreatedAt: now}
+	for _, path := range paths {
+		state := FileState{Path: path}
+		abs := filepath.Join(root, filepath.FromSlash(path))
+		if info, err := os.Stat(abs); err == nil {
+			content, err := os.ReadFile(abs)
+			if err != nil {
+				return nil, err
+			}
+			state.Existed, state.Mode, state.Content = true, info.Mode().Perm(), content
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		snap.Files = append(snap.Files, state)
+	}
+	return snap, nil
+}
+
+// save records the post-apply hash of every file and writes the snapshot.
+func (s *Snapshot) save(root string) error {
+	for i := range s.Files {
+		s.Files[i].AppliedHash = fileHash(filepath.Join(root, filepath.FromSlash(s.Files[i].Path)))
+	}
+	dir := snapshotDir(root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, s.ID+".json"), data, 0644)
+}
+
+// fileHash returns the sha256 of a file's content, or "" if it does not exist.
+func fileHash(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Latest returns the most recently applied snapshot in the repository at root.
+func Latest(root string) (*Snapshot, error) {
+	matches, err := filepath.Glob(filepath.Join(snapshotDir(root), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	sort.Strings(matches)
+	data, err := os.ReadFile(matches[len(matches)-1])
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot %s: %w", filepath.Base(matches[len(matches)-1]), err)
+	}
+	return &snap, nil
+}
+
+// Undo restores the files of the most recently applied patch and discards its snapshot,
+// so repeated calls walk back through earlier patches. Unless force is set, it refuses
+// with ErrChangedSinceApply when any of those files was edited after the patch.
+func Undo(root string, force bool) (*Snapshot, error) {
+	snap, err := Latest(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		var changed []string
+		for _, f := range snap.Files {
+			if fileHash(filepath.Join(root, filepath.FromSlash(f.Path))) != f.AppliedHash {
+				changed = append(changed, f.Path)
+			}
+		}
+		if len(changed) > 0 {
+			return snap, fmt.Errorf("%w: %s", ErrChangedSinceApply, strings.Join(changed, ", "))
+		}
+	}
+
+	for _, f := range snap.Files {
+		abs := filepath.Join(root, filepath.FromSlash(f.Path))
+		if !f.Existed {
+			if err := os.Remove(abs); err != nil && !os.IsNotExist(err) {
+				return snap, fmt.Errorf("failed to remove %s: %w", f.Path, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+			return snap, fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+		if err := os.WriteFile(abs, f.Content, f.Mode); err != nil {
+			return snap, fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(snapshotDir(root), snap.ID+".json")); err != nil {
+		return snap, fmt.Errorf("files restored, but the snapshot could not be removed: %w", err)
+	}
+	return snap, nil
+}
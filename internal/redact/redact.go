@@ -0,0 +1,478 @@
+// internal/redact/redact.go
+
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Detector names reported for built-in rules.
+const (
+	DetectorAWSAccessKey = "aws-access-key"
+	DetectorAWSSecretKey = "aws-secret-key"
+	DetectorPrivateKey   = "private-key"
+	DetectorJWT          = "jwt"
+	DetectorEnvValue     = "env-value"
+	DetectorHighEntropy  = "high-entropy"
+	DetectorDeniedPath   = "denied-path"
+)
+
+// ConfigFile is the repository-local redaction config, relative to .git/pr_buddy_db.
+const ConfigFile = "redaction.json"
+
+// DefaultEntropyThreshold is the Shannon entropy (bits per character) above which a
+// long mixed-case alphanumeric token is treated as a secret.
+const DefaultEntropyThreshold = 4.0
+
+// minEntropyTokenLength keeps short identifiers and words out of the entropy check.
+const minEntropyTokenLength = 24
+
+// Pattern is a user-defined detector. If the regex has a capture group, only the
+// first group is redacted; otherwise the whole match is.
+type Pattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// Config controls what is redacted.
+type Config struct {
+	Patterns         []Pattern `json:"patterns,omitempty"`          // Extra detectors
+	DenyPaths        []string  `json:"deny_paths,omitempty"`        // Globs whose file contents are never sent, e.g. "secrets/**"
+	DisableEntropy   bool      `json:"disable_entropy,omitempty"`   // Turn off the high-entropy token check
+	EntropyThreshold float64   `json:"entropy_threshold,omitempty"` // Defaults to DefaultEntropyThreshold
+}
+
+// DefaultDenyPaths are always withheld: key material is never useful context.
+var DefaultDenyPaths = []string{"*.pem", "*.key", "*.p12", "*.pfx", "id_rsa*", "id_ecdsa*", "id_ed25519*"}
+
+// Redaction records one replaced secret. The secret itself is never stored.
+type Redaction struct {
+	Detector    string `json:"detector"`
+	Placeholder string `json:"placeholder"`
+	Path        string `json:"path,omitempty"` // File the secret was found in, when known
+}
+
+// rule is a compiled detector; group selects the submatch to replace (0 for the whole match).
+// accept, if set, filters matched values.
+type rule struct {
+	name   string
+	re     *regexp.Regexp
+	group  int
+	accept func(value string) bool
+}
+
+var builtinRules = []rule{
+	{name: DetectorPrivateKey, re: regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{name: DetectorAWSAccessKey, re: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16}\b`)},
+	{name: DetectorAWSSecretKey, re: regexp.MustCompile(`(?i)aws[a-z0-9_.-]*(?:secret|sk)[a-z0-9_.-]*["']?\s*[:=]+\s*["']?([A-Za-z0-9/+=]{40})\b`), group: 1},
+	{name: DetectorJWT, re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{4,}\.eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`)},
+	// Assignments to secret-looking environment variables, anywhere in the text. Numeric
+	// values (MAX_TOKENS=8192) are settings, not secrets.
+	{name: DetectorEnvValue, re: regexp.MustCompile(`(?m)\b(?:export\s+)?[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_]*\s*=\s*["']?([^\s"'$#]+)`), group: 1, accept: hasLetter},
+}
+
+// envFileAssignment matches every assignment inside a .env file, optionally prefixed by a diff marker.
+var envFileAssignment = regexp.MustCompile(`(?m)^[+\- ]?\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=\s*["']?([^"'\n]+?)["']?\s*$`)
+
+// sectionHeader marks where a file's content starts in prompts: a git diff header or
+// the DCE's "File: <path>" snapshot header. Diff paths may contain spaces or be quoted,
+// so they are parsed by utils.ParseUnifiedDiff rather than by the pattern.
+var sectionHeader = regexp.MustCompile(`(?m)^(?:diff --git .+|File: (.+))$`)
+
+// entropyToken matches candidate tokens for the entropy check.
+var entropyToken = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+// hexToken matches git object ids, checksums and UUIDs, which are random but not secret.
+var hexToken = regexp.MustCompile(`^[0-9a-fA-F-]+$`)
+
+// Redactor replaces secrets with placeholders. The same secret always maps to the same
+// placeholder for the lifetime of a Redactor, so the model can still tell values apart.
+type Redactor struct {
+	rules     []rule
+	denyPaths []string
+	entropy   float64 // 0 disables the entropy check
+
+	mu           sync.Mutex
+	placeholders map[string]string    // detector + secret -> placeholder
+	applied      map[string]Redaction // placeholder -> first redaction that produced it
+	counters     map[string]int
+}
+
+// New compiles a Redactor from cfg on top of the built-in detectors.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{
+		rules:        append([]rule{}, builtinRules...),
+		denyPaths:    append(append([]string{}, DefaultDenyPaths...), cfg.DenyPaths...),
+		entropy:      cfg.EntropyThreshold,
+		placeholders: make(map[string]string),
+		applied:      make(map[string]Redaction),
+		counters:     make(map[string]int),
+	}
+	if r.entropy == 0 {
+		r.entropy = DefaultEntropyThreshold
+	}
+	if cfg.DisableEntropy {
+		r.entropy = 0
+	}
+
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p.Name, err)
+		}
+		name := p.Name
+		if name == "" {
+			name = "custom"
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		r.rules = append(r.rules, rule{name: name, re: re, group: group})
+	}
+	return r, nil
+}
+
+// LoadConfig reads .git/pr_buddy_db/redaction.json. A missing file yields the defaults.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, ".git", "pr_buddy_db", ConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read redaction config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse redaction config: %w", err)
+	}
+	return cfg, nil
+}
+
+var (
+	defaultsMu sync.Mutex
+	defaults   = make(map[string]*Redactor)
+)
+
+// Default returns the shared Redactor for the current repository, built from its config.
+// An invalid config falls back to the built-in detectors rather than disabling redaction.
+func Default() *Redactor {
+	repoPath, _ := utils.GetRepoPath()
+
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	if r, ok := defaults[repoPath]; ok {
+		return r
+	}
+
+	cfg, err := LoadConfig()
+	r, compileErr := New(cfg)
+	if err != nil || compileErr != nil {
+		fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Redaction config ignored: %v\n", firstError(err, compileErr))
+		r, _ = New(Config{})
+	}
+	defaults[repoPath] = r
+	return r
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RedactMessages returns a copy of messages with every message's content redacted.
+func (r *Redactor) RedactMessages(messages []contextpkg.Message) ([]contextpkg.Message, []Redaction) {
+	var all []Redaction
+	out := make([]contextpkg.Message, len(messages))
+	for i, msg := range messages {
+		redacted, found := r.Redact(msg.Content)
+		msg.Content = redacted
+		out[i] = msg
+		all = append(all, found...)
+	}
+	return out, all
+}
+
+// Redact replaces secrets in text. Content of files on the path denylist is withheld
+// entirely; .env files have every assigned value replaced.
+func (r *Redactor) Redact(text string) (string, []Redaction) {
+	if text == "" {
+		return text, nil
+	}
+
+	var redactions []Redaction
+	var b strings.Builder
+	for _, section := range splitSections(text) {
+		switch {
+		case section.path != "" && (r.denied(section.path) || r.denied(section.oldPath)):
+			placeholder := r.placeholder(DetectorDeniedPath, section.path)
+			b.WriteString(section.header + "\n" + placeholder + "\n")
+			if strings.TrimSpace(section.body) != placeholder {
+				redactions = append(redactions, r.record(Redaction{Detector: DetectorDeniedPath, Placeholder: placeholder, Path: section.path}))
+			}
+			continue
+		case section.path != "" && isEnvFile(section.path):
+			body, found := r.apply(section.body, rule{name: DetectorEnvValue, re: envFileAssignment, group: 1}, section.path)
+			section.body = body
+			redactions = append(redactions, found...)
+		}
+
+		body := section.body
+		for _, ru := range r.rules {
+			var found []Redaction
+			body, found = r.apply(body, ru, section.path)
+			redactions = append(redactions, found...)
+		}
+		if r.entropy > 0 {
+			var found []Redaction
+			body, found = r.redactEntropy(body, section.path)
+			redactions = append(redactions, found...)
+		}
+		b.WriteString(section.header + body)
+	}
+	return b.String(), redactions
+}
+
+// apply replaces the selected submatch of every match of ru.
+func (r *Redactor) apply(text string, ru rule, file string) (string, []Redaction) {
+	var redactions []Redaction
+	var b strings.Builder
+	last := 0
+	for _, m := range ru.re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[2*ru.group], m[2*ru.group+1]
+		if start < 0 || start < last || isPlaceholder(text[start:end]) {
+			continue
+		}
+		if ru.accept != nil && !ru.accept(text[start:end]) {
+			continue
+		}
+		placeholder := r.placeholder(ru.name, text[start:end])
+		b.WriteString(text[last:start] + placeholder)
+		last = end
+		redactions = append(redactions, r.record(Redaction{Detector: ru.name, Placeholder: placeholder, Path: file}))
+	}
+	if last == 0 {
+		return text, nil
+	}
+	b.WriteString(text[last:])
+	return b.String(), redactions
+}
+
+// redactEntropy replaces long random-looking tokens.
+func (r *Redactor) redactEntropy(text, file string) (string, []Redaction) {
+	var redactions []Redaction
+	result := entropyToken.ReplaceAllStringFunc(text, func(token string) string {
+		if !looksRandom(token, r.entropy) {
+			return token
+		}
+		placeholder := r.placeholder(DetectorHighEntropy, token)
+		redactions = append(redactions, r.record(Redaction{Detector: DetectorHighEntropy, Placeholder: placeholder, Path: file}))
+		return placeholder
+	})
+	return result, redactions
+}
+
+// looksRandom reports whether a token is long, mixes upper case, lower case and digits,
+// is not plain hex, and has at least threshold bits of entropy per character.
+func looksRandom(token string, threshold float64) bool {
+	if len(token) < minEntropyTokenLength || hexToken.MatchString(token) {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit && ShannonEntropy(token) >= threshold
+}
+
+// ShannonEntropy returns the entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+	n := float64(len([]rune(s)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// placeholderPattern matches placeholders produced by placeholder.
+var placeholderPattern = regexp.MustCompile(`\[REDACTED:[a-zA-Z0-9_.-]+-\d+\]`)
+
+// Applied returns the redactions whose placeholders appear in messages, in order of first
+// appearance. It covers text redacted earlier, e.g. before it was logged, as long as the
+// same Redactor produced it.
+func (r *Redactor) Applied(messages []contextpkg.Message) []Redaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[string]bool)
+	var applied []Redaction
+	for _, msg := range messages {
+		for _, p := range placeholderPattern.FindAllString(msg.Content, -1) {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if red, ok := r.applied[p]; ok {
+				applied = append(applied, red)
+			}
+		}
+	}
+	return applied
+}
+
+// record remembers where a placeholder came from and returns the redaction.
+func (r *Redactor) record(red Redaction) Redaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.applied[red.Placeholder]; !ok {
+		r.applied[red.Placeholder] = red
+	}
+	return red
+}
+
+// placeholder returns the stable placeholder for a secret value.
+func (r *Redactor) placeholder(detector, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := detector + "\x00" + value
+	if p, ok := r.placeholders[key]; ok {
+		return p
+	}
+	r.counters[detector]++
+	p := fmt.Sprintf("[REDACTED:%s-%d]", detector, r.counters[detector])
+	r.placeholders[key] = p
+	return p
+}
+
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) != -1
+}
+
+func isPlaceholder(s string) bool {
+	return strings.HasPrefix(s, "[REDACTED:")
+}
+
+// Denied reports whether a repository path matches the path denylist, so its contents
+// must never be sent.
+func (r *Redactor) Denied(file string) bool {
+	return r.denied(file)
+}
+
+// denied reports whether a repository path matches the path denylist. Patterns ending in
+// "/" or "/**" match a directory prefix; others match the full path or the base name.
+func (r *Redactor) denied(file string) bool {
+	for _, pattern := range r.denyPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			pattern = prefix + "/"
+		}
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(file, pattern) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isEnvFile(file string) bool {
+	base := path.Base(file)
+	return base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// section is a span of text attributed to one file (or to none, before the first header).
+type section struct {
+	header  string // The header line without its newline; "" for leading text
+	path    string
+	oldPath string // The pre-change path of a diff section, so renames out of the denylist still count
+	body    string // Everything after the header line, including its newline
+}
+
+// splitSections splits text at diff and "File:" headers so detectors know which file
+// they are looking at.
+func splitSections(text string) []section {
+	matches := sectionHeader.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return []section{{body: text}}
+	}
+
+	sections := []section{{body: text[:matches[0][0]]}}
+	for i, m := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		s := section{header: text[m[0]:m[1]], body: text[m[1]:end]}
+		if m[2] >= 0 {
+			s.path = text[m[2]:m[3]]
+		} else if files := utils.ParseUnifiedDiff(text[m[0]:end]); len(files) > 0 {
+			s.path, s.oldPath = files[0].Path(), files[0].OldPath
+		}
+		sections = append(sections, s)
+	}
+	return sections
+}
+
+// Summary describes redactions by detector, e.g. "3 value(s) redacted: jwt x1, env-value x2".
+func Summary(redactions []Redaction) string {
+	if len(redactions) == 0 {
+		return "nothing redacted"
+	}
+	counts := make(map[string]int)
+	for _, r := range redactions {
+		counts[r.Detector]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s x%d", name, counts[name])
+	}
+	return fmt.Sprintf("%d value(s) redacted: %s", len(redactions), strings.Join(parts, ", "))
+}
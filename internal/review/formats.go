@@ -0,0 +1,243 @@
+// internal/review/formats.go
+
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Output formats supported by Render.
+const (
+	FormatTerminal = "terminal"
+	FormatSARIF    = "sarif"
+	FormatRDJSON   = "rdjson"
+)
+
+const toolName = "prbuddy-go"
+
+// Render formats the report as terminal text, SARIF 2.1.0 or reviewdog rdjson.
+func Render(report *Report, format string) (string, error) {
+	switch format {
+	case "", FormatTerminal:
+		return FormatTerminalReport(report), nil
+	case FormatSARIF:
+		return marshalIndent(toSARIF(report))
+	case FormatRDJSON:
+		return marshalIndent(toRDJSON(report))
+	default:
+		return "", fmt.Errorf("unknown format %q (expected %s, %s or %s)", format, FormatTerminal, FormatSARIF, FormatRDJSON)
+	}
+}
+
+// FormatTerminalReport renders findings grouped by file with colored severities.
+func FormatTerminalReport(report *Report) string {
+	if len(report.Findings) == 0 {
+		return utils.Green("No findings.") + "\n"
+	}
+
+	var b strings.Builder
+	currentFile := ""
+	for _, f := range report.Findings {
+		if f.File != currentFile {
+			if currentFile != "" {
+				b.WriteString("\n")
+			}
+			currentFile = f.File
+			b.WriteString(utils.Bold(f.File) + "\n")
+		}
+
+		lines := fmt.Sprintf("%d", f.StartLine)
+		if f.EndLine > f.StartLine {
+			lines = fmt.Sprintf("%d-%d", f.StartLine, f.EndLine)
+		}
+		b.WriteString(fmt.Sprintf("  %s %s [%s] %s\n", colorSeverity(f.Severity), lines, f.Category, f.Message))
+		if f.Suggestion != "" {
+			b.WriteString(fmt.Sprintf("      %s %s\n", utils.Cyan("suggestion:"), f.Suggestion))
+		}
+	}
+	b.WriteString(fmt.Sprintf("\n%d finding(s)\n", len(report.Findings)))
+	return b.String()
+}
+
+func colorSeverity(severity string) string {
+	switch severity {
+	case SeverityError:
+		return utils.Red("error  ")
+	case SeverityWarning:
+		return utils.Yellow("warning")
+	default:
+		return utils.Cyan("info   ")
+	}
+}
+
+// fullMessage appends the suggestion to the message for formats without a separate field.
+func fullMessage(f Finding) string {
+	if f.Suggestion == "" {
+		return f.Message
+	}
+	return f.Message + "\nSuggestion: " + f.Suggestion
+}
+
+func marshalIndent(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal review output: %w", err)
+	}
+	return string(data), nil
+}
+
+// -----------------------------------------------------------------------------
+// SARIF 2.1.0
+// -----------------------------------------------------------------------------
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+			EndLine   int `json:"endLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func toSARIF(report *Report) sarifLog {
+	categories := make(map[string]bool)
+	results := make([]sarifResult, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		categories[f.Category] = true
+
+		level := "note"
+		switch f.Severity {
+		case SeverityError:
+			level = "error"
+		case SeverityWarning:
+			level = "warning"
+		}
+
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = f.File
+		loc.PhysicalLocation.Region.StartLine = f.StartLine
+		loc.PhysicalLocation.Region.EndLine = f.EndLine
+
+		results = append(results, sarifResult{
+			RuleID:    f.Category,
+			Level:     level,
+			Message:   sarifMessage{Text: fullMessage(f)},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	rules := make([]sarifRule, 0, len(categories))
+	for c := range categories {
+		rules = append(rules, sarifRule{ID: c})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: "https://github.com/soyuz43/prbuddy-go",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// reviewdog rdjson
+// -----------------------------------------------------------------------------
+
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+}
+
+type rdjsonDiagnostic struct {
+	Message  string         `json:"message"`
+	Location rdjsonLocation `json:"location"`
+	Severity string         `json:"severity"`
+	Source   rdjsonSource   `json:"source"`
+	Code     struct {
+		Value string `json:"value"`
+	} `json:"code"`
+}
+
+type rdjsonLocation struct {
+	Path  string `json:"path"`
+	Range struct {
+		Start struct {
+			Line int `json:"line"`
+		} `json:"start"`
+		End struct {
+			Line int `json:"line"`
+		} `json:"end"`
+	} `json:"range"`
+}
+
+func toRDJSON(report *Report) rdjsonResult {
+	diagnostics := make([]rdjsonDiagnostic, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		d := rdjsonDiagnostic{
+			Message:  fullMessage(f),
+			Severity: strings.ToUpper(f.Severity),
+			Source:   rdjsonSource{Name: toolName},
+		}
+		d.Code.Value = f.Category
+		d.Location.Path = f.File
+		d.Location.Range.Start.Line = f.StartLine
+		d.Location.Range.End.Line = f.EndLine
+		diagnostics = append(diagnostics, d)
+	}
+	return rdjsonResult{Source: rdjsonSource{Name: toolName}, Diagnostics: diagnostics}
+}
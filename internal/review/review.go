@@ -0,0 +1,170 @@
+// internal/review/review.go
+
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Severity levels a finding may carry, from most to least severe.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a single review comment anchored to lines of the new side of a diff.
+type Finding struct {
+	File       string `json:"file"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Severity   string `json:"severity"`
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// DroppedFinding is a finding rejected during validation, with the reason.
+type DroppedFinding struct {
+	Finding Finding `json:"finding"`
+	Reason  string  `json:"reason"`
+}
+
+// Report is the validated outcome of a review.
+type Report struct {
+	Findings []Finding        `json:"findings"`
+	Dropped  []DroppedFinding `json:"dropped,omitempty"`
+}
+
+// Scope selects which changes are reviewed.
+type Scope struct {
+	Range  string // Commit range such as "v1.0..HEAD"
+	Branch string // Base branch; reviews the current branch's changes since it diverged
+}
+
+// Describe renders the scope for log output.
+func (s Scope) Describe() string {
+	switch {
+	case s.Range != "":
+		return "commit range " + s.Range
+	case s.Branch != "":
+		return "changes since " + s.Branch
+	default:
+		return "staged changes"
+	}
+}
+
+// LoadDiff returns the unified diff for the scope. Staged changes are reviewed by default.
+func LoadDiff(scope Scope) (string, error) {
+	var diff string
+	var err error
+	switch {
+	case scope.Range != "":
+		diff, err = utils.ExecGit("diff", "--no-color", scope.Range)
+	case scope.Branch != "":
+		diff, err = utils.ExecGit("diff", "--no-color", scope.Branch+"...HEAD")
+	default:
+		diff, err = utils.ExecGit("diff", "--cached", "--no-color")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for %s: %w", scope.Describe(), err)
+	}
+	return diff, nil
+}
+
+// NormalizeSeverity maps free-form severities onto error, warning or info.
+func NormalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "error", "critical", "high", "blocker":
+		return SeverityError
+	case "info", "low", "note", "nit", "suggestion":
+		return SeverityInfo
+	default:
+		return SeverityWarning
+	}
+}
+
+// SeverityRank orders severities; higher is more severe.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Validate keeps only findings anchored to lines that appear on the new side of the
+// diff (added or context lines). The start line must be in the diff; the end line is
+// trimmed to the contiguous run of diff lines that follows it.
+func Validate(findings []Finding, files []utils.FileDiff) *Report {
+	visible := make(map[string]map[int]bool)
+	for _, fd := range files {
+		if fd.IsDeleted() {
+			continue
+		}
+		lines := make(map[int]bool)
+		for _, h := range fd.Hunks {
+			for _, l := range h.NewSideLines() {
+				lines[l] = true
+			}
+		}
+		visible[fd.Path()] = lines
+	}
+
+	report := &Report{}
+	for _, f := range findings {
+		f.File = strings.TrimPrefix(strings.TrimPrefix(f.File, "b/"), "./")
+		f.Severity = NormalizeSeverity(f.Severity)
+		f.Category = strings.ToLower(strings.TrimSpace(f.Category))
+		if f.Category == "" {
+			f.Category = "general"
+		}
+
+		lines, ok := visible[f.File]
+		switch {
+		case !ok:
+			report.Dropped = append(report.Dropped, DroppedFinding{f, "file is not part of the diff"})
+			continue
+		case strings.TrimSpace(f.Message) == "":
+			report.Dropped = append(report.Dropped, DroppedFinding{f, "empty message"})
+			continue
+		case !lines[f.StartLine]:
+			report.Dropped = append(report.Dropped, DroppedFinding{f, fmt.Sprintf("line %d is not in the diff", f.StartLine)})
+			continue
+		}
+
+		end := f.StartLine
+		for end < f.EndLine && lines[end+1] {
+			end++
+		}
+		f.EndLine = end
+		report.Findings = append(report.Findings, f)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.StartLine < b.StartLine
+	})
+	return report
+}
+
+// MaxSeverity returns the most severe level among the findings, or "" if there are none.
+func (r *Report) MaxSeverity() string {
+	max := ""
+	for _, f := range r.Findings {
+		if max == "" || SeverityRank(f.Severity) > SeverityRank(max) {
+			max = f.Severity
+		}
+	}
+	return max
+}
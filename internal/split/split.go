@@ -0,0 +1,321 @@
+// internal/split/split.go
+
+package split
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Unit is the smallest piece of a staged change that can be moved between commits:
+// a single hunk, or a whole file when its change cannot be split (new, deleted,
+// renamed, mode-only or binary files).
+type Unit struct {
+	ID    string
+	File  string
+	Diff  utils.FileDiff // Header plus only the hunks that belong to this unit
+	Whole bool           // True when the unit carries the entire file change
+}
+
+// Group is one proposed commit.
+type Group struct {
+	Message string
+	Units   []string // Unit IDs, in diff order
+}
+
+// Plan is an ordered sequence of proposed commits covering every unit exactly once.
+type Plan struct {
+	Groups []Group
+}
+
+// wholeFileMarkers are header lines that make a file change indivisible.
+var wholeFileMarkers = []string{
+	"new file mode", "deleted file mode", "rename from", "copy from",
+	"old mode", "Binary files", "GIT binary patch",
+}
+
+// StagedDiff returns the staged diff byte-for-byte, including binary patches.
+func StagedDiff() (string, error) {
+	diff, err := utils.ExecGitRaw(nil, "diff", "--cached", "--binary", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	return diff, nil
+}
+
+// CollectUnits splits a diff into units with IDs u1, u2, ... in diff order.
+func CollectUnits(diff string) []Unit {
+	var units []Unit
+	for _, fd := range utils.ParseUnifiedDiff(diff) {
+		if isWholeFile(fd) {
+			units = append(units, Unit{ID: fmt.Sprintf("u%d", len(units)+1), File: fd.Path(), Diff: fd, Whole: true})
+			continue
+		}
+		for _, h := range fd.Hunks {
+			single := fd
+			single.Hunks = []utils.Hunk{h}
+			units = append(units, Unit{ID: fmt.Sprintf("u%d", len(units)+1), File: fd.Path(), Diff: single})
+		}
+	}
+	return units
+}
+
+func isWholeFile(fd utils.FileDiff) bool {
+	if len(fd.Hunks) <= 1 {
+		return true
+	}
+	for _, line := range fd.Header {
+		for _, marker := range wholeFileMarkers {
+			if strings.HasPrefix(line, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProximityGroups groups units by package directory, so code and its _test.go files
+// land together. Groups are ordered by first appearance.
+func ProximityGroups(units []Unit) *Plan {
+	index := make(map[string]int)
+	plan := &Plan{}
+	for _, u := range units {
+		dir := path.Dir(u.File)
+		i, ok := index[dir]
+		if !ok {
+			i = len(plan.Groups)
+			index[dir] = i
+			plan.Groups = append(plan.Groups, Group{Message: DefaultMessage([]string{u.File})})
+		}
+		plan.Groups[i].Units = append(plan.Groups[i].Units, u.ID)
+	}
+	return plan
+}
+
+// DefaultMessage builds a fallback Conventional Commits message for a set of files.
+func DefaultMessage(files []string) string {
+	scope := utils.InferCommitScope(files)
+	subject := "update " + path.Dir(files[0])
+	if path.Dir(files[0]) == "." {
+		subject = "update " + files[0]
+	}
+	msg := utils.ConventionalCommit{Type: "chore", Scope: scope, Subject: subject}
+	msg.Normalize()
+	return msg.Header()
+}
+
+// Normalize makes the plan cover every unit exactly once: unknown and duplicate IDs are
+// dropped, empty groups removed, and leftover units appended as proximity groups.
+// Units inside each group are put back in diff order so patches apply cleanly.
+func (p *Plan) Normalize(units []Unit) {
+	order := make(map[string]int, len(units))
+	for i, u := range units {
+		order[u.ID] = i
+	}
+
+	assigned := make(map[string]bool)
+	var groups []Group
+	for _, g := range p.Groups {
+		var ids []string
+		for _, id := range g.Units {
+			if _, ok := order[id]; ok && !assigned[id] {
+				assigned[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		sort.Slice(ids, func(i, j int) bool { return order[ids[i]] < order[ids[j]] })
+		if strings.TrimSpace(g.Message) == "" {
+			g.Message = DefaultMessage(filesOf(units, ids))
+		}
+		g.Units = ids
+		groups = append(groups, g)
+	}
+
+	var leftover []Unit
+	for _, u := range units {
+		if !assigned[u.ID] {
+			leftover = append(leftover, u)
+		}
+	}
+	if len(leftover) > 0 {
+		groups = append(groups, ProximityGroups(leftover).Groups...)
+	}
+	p.Groups = groups
+}
+
+// filesOf returns the distinct files touched by the given unit IDs.
+func filesOf(units []Unit, ids []string) []string {
+	var files []string
+	for _, u := range units {
+		if utils.StringSliceContains(ids, u.ID) && !utils.StringSliceContains(files, u.File) {
+			files = append(files, u.File)
+		}
+	}
+	return files
+}
+
+// BuildPatch renders the units of a group as one patch, merging hunks of the same file
+// under a single file header.
+func BuildPatch(units []Unit, ids []string) string {
+	var b strings.Builder
+	written := make(map[string]bool)
+	for i, u := range units {
+		if !utils.StringSliceContains(ids, u.ID) || written[u.ID] {
+			continue
+		}
+
+		header := strings.Join(trimTrailingEmpty(u.Diff.Header), "\n")
+		b.WriteString(header + "\n")
+		// Gather this file's later hunks in the same group under the same header.
+		for _, other := range units[i:] {
+			if other.File != u.File || !utils.StringSliceContains(ids, other.ID) {
+				continue
+			}
+			written[other.ID] = true
+			for _, h := range other.Diff.Hunks {
+				b.WriteString(h.Header + "\n")
+				for _, l := range h.Lines {
+					b.WriteString(l + "\n")
+				}
+			}
+		}
+		if u.Whole && strings.Contains(header, "GIT binary patch") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func trimTrailingEmpty(lines []string) []string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Apply commits each group in order using only the index, so the working tree is never
+// touched; commits skip pre-commit hooks, which could otherwise rewrite working files.
+// Only the last commit runs the post-commit hook, so PRBuddy drafts one PR for the split
+// rather than one per commit.
+// Before anything is committed the plan is rehearsed in a scratch index and must
+// reproduce the staged tree exactly. If a real commit fails, the original staged
+// content is restored in the index on top of whatever was already committed.
+// It returns the SHAs of the created commits.
+func Apply(plan *Plan, units []Unit) ([]string, error) {
+	stagedTree, err := utils.ExecGit("write-tree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to record staged tree: %w", err)
+	}
+
+	if err := rehearse(plan, units, stagedTree); err != nil {
+		return nil, err
+	}
+
+	if _, err := utils.ExecGit("read-tree", "HEAD"); err != nil {
+		return nil, fmt.Errorf("failed to reset index: %w", err)
+	}
+
+	// An empty hooks directory switches off post-commit for all but the last commit.
+	noHooks, err := os.MkdirTemp("", "prbuddy-split-hooks-")
+	if err != nil {
+		restoreIndex(stagedTree)
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	defer os.RemoveAll(noHooks)
+
+	var shas []string
+	for i, g := range plan.Groups {
+		if err := applyToIndex(nil, BuildPatch(units, g.Units)); err != nil {
+			restoreIndex(stagedTree)
+			return shas, fmt.Errorf("commit %d: %w", i+1, err)
+		}
+		commitArgs := []string{"commit", "-q", "--no-verify", "-m", g.Message}
+		if i < len(plan.Groups)-1 {
+			commitArgs = append([]string{"-c", "core.hooksPath=" + noHooks}, commitArgs...)
+		}
+		if _, err := utils.ExecGit(commitArgs...); err != nil {
+			restoreIndex(stagedTree)
+			return shas, fmt.Errorf("commit %d: %w", i+1, err)
+		}
+		sha, err := utils.GetLatestCommit()
+		if err != nil {
+			restoreIndex(stagedTree)
+			return shas, err
+		}
+		shas = append(shas, sha)
+	}
+
+	finalTree, err := utils.ExecGit("rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return shas, err
+	}
+	if finalTree != stagedTree {
+		restoreIndex(stagedTree)
+		return shas, fmt.Errorf("split commits do not reproduce the staged changes; remaining changes are staged again")
+	}
+	return shas, nil
+}
+
+// rehearse applies the whole plan to a scratch index built from HEAD and checks that it
+// ends at the staged tree.
+func rehearse(plan *Plan, units []Unit, stagedTree string) error {
+	scratch, err := os.CreateTemp("", "prbuddy-split-index-")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch index: %w", err)
+	}
+	scratch.Close()
+	os.Remove(scratch.Name()) // git refuses to read an empty file as an index
+	defer os.Remove(scratch.Name())
+
+	env := []string{"GIT_INDEX_FILE=" + scratch.Name()}
+	if _, err := utils.ExecGitRaw(env, "read-tree", "HEAD"); err != nil {
+		return fmt.Errorf("failed to prepare scratch index: %w", err)
+	}
+	for i, g := range plan.Groups {
+		if err := applyToIndex(env, BuildPatch(units, g.Units)); err != nil {
+			return fmt.Errorf("proposed commit %d does not apply: %w", i+1, err)
+		}
+	}
+	tree, err := utils.ExecGitRaw(env, "write-tree")
+	if err != nil {
+		return fmt.Errorf("failed to write scratch tree: %w", err)
+	}
+	if strings.TrimSpace(tree) != stagedTree {
+		return fmt.Errorf("proposed commits do not reproduce the staged changes")
+	}
+	return nil
+}
+
+// applyToIndex runs `git apply --cached` with the patch written to a temporary file.
+func applyToIndex(env []string, patch string) error {
+	f, err := os.CreateTemp("", "prbuddy-split-*.patch")
+	if err != nil {
+		return fmt.Errorf("failed to create patch file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(patch); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write patch file: %w", err)
+	}
+	f.Close()
+
+	if _, err := utils.ExecGitRaw(env, "apply", "--cached", "--whitespace=nowarn", f.Name()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// restoreIndex puts the original staged tree back into the index.
+func restoreIndex(tree string) {
+	if _, err := utils.ExecGit("read-tree", tree); err != nil {
+		fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Failed to restore staged changes (tree %s): %v\n", tree, err)
+	}
+}
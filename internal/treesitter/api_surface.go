@@ -0,0 +1,261 @@
+// internal/treesitter/api_surface.go
+
+package treesitter
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// API symbol kinds.
+const (
+	APIKindFunc      = "func"
+	APIKindMethod    = "method"
+	APIKindStruct    = "struct"
+	APIKindInterface = "interface"
+	APIKindType      = "type"
+)
+
+// APISymbol is an exported declaration of a Go package. Signatures are normalized so
+// parameter names and formatting do not count as changes.
+type APISymbol struct {
+	Package   string   `json:"package"`           // Package directory, relative to the repository root
+	Name      string   `json:"name"`              // "Func", "Type" or "Type.Method"
+	Kind      string   `json:"kind"`              // One of the APIKind constants
+	Signature string   `json:"signature"`         // e.g. "func(int, ...string) (int, error)"
+	Members   []string `json:"members,omitempty"` // Exported struct fields or interface methods
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+}
+
+// ParseAPI returns the package name of a Go file and its exported API symbols.
+// pkgDir is recorded on every symbol.
+func ParseAPI(pkgDir, file string, content []byte) (string, []APISymbol, error) {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(golang.GetLanguage())
+
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil || tree == nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	pkgName := ""
+	var symbols []APISymbol
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		node := root.NamedChild(i)
+		line := int(node.StartPoint().Row) + 1
+
+		switch node.Type() {
+		case "package_clause":
+			if node.NamedChildCount() > 0 {
+				pkgName = node.NamedChild(0).Content(content)
+			}
+
+		case "function_declaration":
+			name := node.ChildByFieldName("name").Content(content)
+			if !token.IsExported(name) {
+				continue
+			}
+			symbols = append(symbols, APISymbol{
+				Package: pkgDir, Name: name, Kind: APIKindFunc,
+				Signature: funcSignature(node, content), File: file, Line: line,
+			})
+
+		case "method_declaration":
+			name := node.ChildByFieldName("name").Content(content)
+			receiver := node.ChildByFieldName("receiver")
+			recvType := receiverTypeName(receiver, content)
+			if !token.IsExported(name) || !token.IsExported(recvType) {
+				continue
+			}
+			recvText := ""
+			if receiver.NamedChildCount() > 0 {
+				if t := receiver.NamedChild(0).ChildByFieldName("type"); t != nil {
+					recvText = normalizeType(t.Content(content))
+				}
+			}
+			symbols = append(symbols, APISymbol{
+				Package: pkgDir, Name: recvType + "." + name, Kind: APIKindMethod,
+				Signature: "(" + recvText + ") " + funcSignature(node, content), File: file, Line: line,
+			})
+
+		case "type_declaration":
+			for j := 0; j < int(node.NamedChildCount()); j++ {
+				spec := node.NamedChild(j)
+				if sym, ok := typeSymbol(spec, content); ok {
+					sym.Package, sym.File, sym.Line = pkgDir, file, int(spec.StartPoint().Row)+1
+					symbols = append(symbols, sym)
+				}
+			}
+		}
+	}
+	return pkgName, symbols, nil
+}
+
+// typeSymbol describes an exported type_spec or type_alias.
+func typeSymbol(spec *sitter.Node, content []byte) (APISymbol, bool) {
+	if spec.Type() != "type_spec" && spec.Type() != "type_alias" {
+		return APISymbol{}, false
+	}
+	nameNode, typeNode := spec.ChildByFieldName("name"), spec.ChildByFieldName("type")
+	if nameNode == nil || typeNode == nil || !token.IsExported(nameNode.Content(content)) {
+		return APISymbol{}, false
+	}
+
+	sym := APISymbol{Name: nameNode.Content(content), Kind: APIKindType}
+	typeParams := ""
+	if tp := spec.ChildByFieldName("type_parameters"); tp != nil {
+		typeParams = normalizeType(tp.Content(content))
+	}
+
+	switch {
+	case spec.Type() == "type_alias":
+		sym.Signature = "= " + normalizeType(typeNode.Content(content))
+	case typeNode.Type() == "struct_type":
+		sym.Kind = APIKindStruct
+		sym.Signature = "struct" + typeParams
+		sym.Members = structMembers(typeNode, content)
+	case typeNode.Type() == "interface_type":
+		sym.Kind = APIKindInterface
+		sym.Signature = "interface" + typeParams
+		sym.Members = interfaceMembers(typeNode, content)
+	default:
+		sym.Signature = typeParams + normalizeType(typeNode.Content(content))
+	}
+	return sym, true
+}
+
+// structMembers lists exported fields as "Name Type" and embedded types as their type.
+func structMembers(structNode *sitter.Node, content []byte) []string {
+	var members []string
+	var fieldList *sitter.Node
+	for i := 0; i < int(structNode.NamedChildCount()); i++ {
+		if c := structNode.NamedChild(i); c.Type() == "field_declaration_list" {
+			fieldList = c
+		}
+	}
+	if fieldList == nil {
+		return nil
+	}
+
+	for i := 0; i < int(fieldList.NamedChildCount()); i++ {
+		field := fieldList.NamedChild(i)
+		if field.Type() != "field_declaration" {
+			continue
+		}
+		typeNode := field.ChildByFieldName("type")
+		if typeNode == nil {
+			continue
+		}
+
+		var names []string
+		for j := 0; j < int(field.NamedChildCount()); j++ {
+			if c := field.NamedChild(j); c.Type() == "field_identifier" {
+				names = append(names, c.Content(content))
+			}
+		}
+
+		if len(names) == 0 {
+			// Embedded field: keep a leading "*" that is not part of the type node.
+			embedded := content[field.StartByte():typeNode.EndByte()]
+			members = append(members, normalizeType(string(embedded)))
+			continue
+		}
+		for _, name := range names {
+			if token.IsExported(name) {
+				members = append(members, name+" "+normalizeType(typeNode.Content(content)))
+			}
+		}
+	}
+	return members
+}
+
+// interfaceMembers lists methods as "Name(params) results" and embedded/union elements as text.
+func interfaceMembers(ifaceNode *sitter.Node, content []byte) []string {
+	var members []string
+	for i := 0; i < int(ifaceNode.NamedChildCount()); i++ {
+		elem := ifaceNode.NamedChild(i)
+		switch elem.Type() {
+		case "method_elem", "method_spec":
+			name := elem.ChildByFieldName("name").Content(content)
+			members = append(members, name+strings.TrimPrefix(funcSignature(elem, content), "func"))
+		case "comment":
+			continue
+		default:
+			members = append(members, normalizeType(elem.Content(content)))
+		}
+	}
+	return members
+}
+
+// funcSignature renders "func[T any](int, ...string) (int, error)" from a function,
+// method or interface method node, dropping parameter names.
+func funcSignature(node *sitter.Node, content []byte) string {
+	var b strings.Builder
+	b.WriteString("func")
+	if tp := node.ChildByFieldName("type_parameters"); tp != nil {
+		b.WriteString(normalizeType(tp.Content(content)))
+	}
+	b.WriteString("(" + strings.Join(parameterTypes(node.ChildByFieldName("parameters"), content), ", ") + ")")
+
+	if result := node.ChildByFieldName("result"); result != nil {
+		if result.Type() == "parameter_list" {
+			types := parameterTypes(result, content)
+			if len(types) == 1 {
+				b.WriteString(" " + types[0])
+			} else if len(types) > 1 {
+				b.WriteString(" (" + strings.Join(types, ", ") + ")")
+			}
+		} else {
+			b.WriteString(" " + normalizeType(result.Content(content)))
+		}
+	}
+	return b.String()
+}
+
+// parameterTypes lists one type per parameter, expanding "a, b int" to two entries.
+func parameterTypes(list *sitter.Node, content []byte) []string {
+	if list == nil {
+		return nil
+	}
+	var types []string
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		param := list.NamedChild(i)
+		typeNode := param.ChildByFieldName("type")
+		if typeNode == nil {
+			continue
+		}
+		typeText := normalizeType(typeNode.Content(content))
+		if param.Type() == "variadic_parameter_declaration" {
+			typeText = "..." + typeText
+		}
+
+		names := 0
+		for j := 0; j < int(param.NamedChildCount()); j++ {
+			if param.NamedChild(j).Type() == "identifier" {
+				names++
+			}
+		}
+		if names == 0 {
+			names = 1
+		}
+		for n := 0; n < names; n++ {
+			types = append(types, typeText)
+		}
+	}
+	return types
+}
+
+// normalizeType collapses whitespace so formatting changes are not reported.
+func normalizeType(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
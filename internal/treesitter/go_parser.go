@@ -38,10 +38,13 @@ type FunctionDependencies struct {
 type FunctionInfo struct {
 	Name         string               `json:"name"`
 	File         string               `json:"file"`
+	Receiver     string               `json:"receiver,omitempty"`  // Receiver type for methods
+	Signature    string               `json:"signature,omitempty"` // Declaration up to the opening brace
 	StartLine    int                  `json:"start_line"`
 	EndLine      int                  `json:"end_line"`
 	Returns      []string             `json:"returns"`
 	Dependencies FunctionDependencies `json:"dependencies"`
+	Metrics      FunctionMetrics      `json:"metrics"`
 }
 
 // ProjectMap represents the complete project function mapping.
@@ -75,6 +78,15 @@ type goParserState struct {
 	parser         *sitter.Parser
 	functionQuery  *sitter.Query
 	functionCursor *sitter.QueryCursor
+	callQuery      *sitter.Query
+}
+
+// close releases the Tree-Sitter resources held by the state.
+func (s *goParserState) close() {
+	s.functionCursor.Close()
+	s.functionQuery.Close()
+	s.callQuery.Close()
+	s.parser.Close()
 }
 
 // BuildProjectMap constructs a project map with function dependencies.
@@ -88,8 +100,7 @@ func (p *GoParser) BuildProjectMap(rootDir string) (*ProjectMap, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer state.parser.Close()
-	defer state.functionCursor.Close()
+	defer state.close()
 
 	var functions []FunctionInfo
 	for _, file := range metadata.SourceFiles {
@@ -109,31 +120,44 @@ func (p *GoParser) setupParserState() (*goParserState, error) {
 	parser.SetLanguage(golang.GetLanguage())
 
 	funcQuery, err := sitter.NewQuery([]byte(`
-(function_declaration
-  name: (identifier) @name
-  parameters: (parameter_list
-    (parameter_declaration
-      name: (identifier)? @param_name
-      type: (_) @param_type
-    )*
-  ) @parameters
-  result: (parameter_list
-    (parameter_declaration
-      name: (identifier)? @return_name
-      type: (_) @return_type
-    )*
-  )? @results
-  body: (block) @body
-) @func
+[
+  (function_declaration
+    name: (identifier) @name
+    body: (block) @body
+  ) @func
+  (method_declaration
+    receiver: (parameter_list) @receiver
+    name: (field_identifier) @name
+    body: (block) @body
+  ) @func
+]
   `), golang.GetLanguage())
 	if err != nil {
+		parser.Close()
 		return nil, fmt.Errorf("failed to create function query: %w", err)
 	}
 
+	// Plain calls (foo()) and selector calls (pkg.Foo(), recv.Method()) both
+	// record the called name, which is what the call graph is keyed on.
+	callQuery, err := sitter.NewQuery([]byte(`
+(call_expression
+  function: [
+    (identifier) @invocation
+    (selector_expression field: (field_identifier) @invocation)
+  ]
+)
+  `), golang.GetLanguage())
+	if err != nil {
+		funcQuery.Close()
+		parser.Close()
+		return nil, fmt.Errorf("failed to create call query: %w", err)
+	}
+
 	return &goParserState{
 		parser:         parser,
 		functionQuery:  funcQuery,
 		functionCursor: sitter.NewQueryCursor(),
+		callQuery:      callQuery,
 	}, nil
 }
 
@@ -154,6 +178,7 @@ func (p *GoParser) processGoFile(state *goParserState, rootDir string, file stri
 	if err != nil || tree == nil {
 		return nil, fmt.Errorf("failed to parse %s: %w", absPath, err)
 	}
+	defer tree.Close()
 
 	// === Dump the syntax tree for inspection ===
 	if err := saveSyntaxTree(file, tree, content); err != nil {
@@ -166,7 +191,60 @@ func (p *GoParser) processGoFile(state *goParserState, rootDir string, file stri
 	return functions, nil
 }
 
-// parseFunctions extracts function declarations and dependencies from a parsed tree.
+// ParseGoSource extracts function metadata from in-memory Go source. Unlike
+// BuildProjectMap it does not dump syntax trees, so it is safe to use on
+// historical blob contents (e.g. from `git show <rev>:<path>`).
+func ParseGoSource(file string, content []byte) ([]FunctionInfo, error) {
+	p := &GoParser{}
+	state, err := p.setupParserState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.close()
+
+	return p.parseSource(state, file, content)
+}
+
+// ParseGoFiles parses the given repo-relative Go files under rootDir without
+// dumping syntax trees. Unreadable or unparsable files are skipped.
+func ParseGoFiles(rootDir string, files []string) ([]FunctionInfo, error) {
+	p := &GoParser{}
+	state, err := p.setupParserState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.close()
+
+	var functions []FunctionInfo
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(rootDir, file))
+		if err != nil {
+			continue
+		}
+		fileFuncs, err := p.parseSource(state, file, content)
+		if err != nil {
+			continue
+		}
+		functions = append(functions, fileFuncs...)
+	}
+	return functions, nil
+}
+
+// parseSource parses content and extracts its functions using an existing parser state.
+func (p *GoParser) parseSource(state *goParserState, file string, content []byte) ([]FunctionInfo, error) {
+	tree, err := state.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil || tree == nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	defer tree.Close()
+
+	return p.parseFunctions(state, tree, content, file), nil
+}
+
+// parseFunctions extracts function and method declarations and dependencies from a parsed tree.
 func (p *GoParser) parseFunctions(state *goParserState, tree *sitter.Tree, content []byte, file string) []FunctionInfo {
 	var functions []FunctionInfo
 	state.functionCursor.Exec(state.functionQuery, tree.RootNode())
@@ -178,8 +256,7 @@ func (p *GoParser) parseFunctions(state *goParserState, tree *sitter.Tree, conte
 		}
 
 		var funcInfo FunctionInfo
-		var returns []string
-		var bodyNode *sitter.Node
+		var funcNode, bodyNode *sitter.Node
 
 		// Process captures from the function query.
 		for _, capture := range match.Captures {
@@ -187,62 +264,66 @@ func (p *GoParser) parseFunctions(state *goParserState, tree *sitter.Tree, conte
 			switch state.functionQuery.CaptureNameForId(capture.Index) {
 			case "name":
 				funcInfo.Name = string(node.Content(content))
-			case "return_type":
-				returns = append(returns, string(node.Content(content)))
+			case "receiver":
+				funcInfo.Receiver = receiverTypeName(node, content)
 			case "body":
 				bodyNode = node
-				funcInfo.StartLine = int(node.Parent().StartPoint().Row) + 1
-				funcInfo.EndLine = int(node.Parent().EndPoint().Row) + 1
+			case "func":
+				funcNode = node
 			}
 		}
-		funcInfo.Returns = returns
+		if funcNode != nil {
+			funcInfo.StartLine = int(funcNode.StartPoint().Row) + 1
+			funcInfo.EndLine = int(funcNode.EndPoint().Row) + 1
+			funcInfo.Returns = resultTypes(funcNode.ChildByFieldName("result"), content)
+			end := funcNode.EndByte()
+			if bodyNode != nil {
+				end = bodyNode.StartByte()
+			}
+			funcInfo.Signature = strings.TrimSpace(string(content[funcNode.StartByte():end]))
+		}
 		funcInfo.File = file
 
 		// Initialize dependencies.
 		funcInfo.Dependencies = FunctionDependencies{}
+		funcInfo.Metrics = measureFunction(bodyNode)
 
 		// Extract function dependencies.
 		if bodyNode != nil {
-			depQuery, err := sitter.NewQuery([]byte(`
-				(call_expression
-					function: (identifier) @invocation
-				)
-			`), golang.GetLanguage())
-
-			if err == nil {
-				depCursor := sitter.NewQueryCursor()
-				depCursor.Exec(depQuery, bodyNode)
-
-				for {
-					depMatch, ok := depCursor.NextMatch()
-					if !ok {
-						break
+			depCursor := sitter.NewQueryCursor()
+			depCursor.Exec(state.callQuery, bodyNode)
+
+			for {
+				depMatch, ok := depCursor.NextMatch()
+				if !ok {
+					break
+				}
+				for _, depCapture := range depMatch.Captures {
+					if state.callQuery.CaptureNameForId(depCapture.Index) != "invocation" {
+						continue
 					}
-					for _, depCapture := range depMatch.Captures {
-						if depQuery.CaptureNameForId(depCapture.Index) == "invocation" {
-							invocationName := string(depCapture.Node.Content(content))
-
-							// Check if the function is locally defined in the same file (utility function).
-							isUtility := false
-							for _, f := range functions {
-								if f.Name == invocationName {
-									isUtility = true
-									break
-								}
-							}
-
-							// Categorize dependencies
-							if strings.HasPrefix(invocationName, "Handle") {
-								funcInfo.Dependencies.Handlers = append(funcInfo.Dependencies.Handlers, invocationName)
-							} else if isUtility {
-								funcInfo.Dependencies.Utilities = append(funcInfo.Dependencies.Utilities, invocationName)
-							} else {
-								funcInfo.Dependencies.Invocations = append(funcInfo.Dependencies.Invocations, invocationName)
-							}
+					invocationName := string(depCapture.Node.Content(content))
+
+					// Check if the function is locally defined in the same file (utility function).
+					isUtility := false
+					for _, f := range functions {
+						if f.Name == invocationName {
+							isUtility = true
+							break
 						}
 					}
+
+					// Categorize dependencies
+					if strings.HasPrefix(invocationName, "Handle") {
+						funcInfo.Dependencies.Handlers = append(funcInfo.Dependencies.Handlers, invocationName)
+					} else if isUtility {
+						funcInfo.Dependencies.Utilities = append(funcInfo.Dependencies.Utilities, invocationName)
+					} else {
+						funcInfo.Dependencies.Invocations = append(funcInfo.Dependencies.Invocations, invocationName)
+					}
 				}
 			}
+			depCursor.Close()
 		}
 
 		if funcInfo.Name != "" {
@@ -253,6 +334,65 @@ func (p *GoParser) parseFunctions(state *goParserState, tree *sitter.Tree, conte
 	return functions
 }
 
+// FunctionSource returns the full source text of a function, read from the
+// repo-relative file under rootDir using the recorded line range.
+func FunctionSource(rootDir string, fn FunctionInfo) (string, error) {
+	content, err := os.ReadFile(filepath.Join(rootDir, RepoRelativePath(fn.File)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", fn.File, err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if fn.StartLine < 1 || fn.EndLine > len(lines) || fn.StartLine > fn.EndLine {
+		return "", fmt.Errorf("line range %d-%d out of bounds for %s", fn.StartLine, fn.EndLine, fn.File)
+	}
+	return strings.Join(lines[fn.StartLine-1:fn.EndLine], "\n"), nil
+}
+
+// receiverTypeName returns the bare receiver type of a method, e.g. "LittleGuy" for "(lg *LittleGuy)".
+func receiverTypeName(receiver *sitter.Node, content []byte) string {
+	text := strings.Trim(string(receiver.Content(content)), "()")
+	if idx := strings.Index(text, "["); idx != -1 {
+		text = text[:idx] // Drop type parameters, which may contain spaces
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimLeft(fields[len(fields)-1], "*")
+}
+
+// resultTypes lists the result types of a function, one entry per returned value.
+func resultTypes(result *sitter.Node, content []byte) []string {
+	if result == nil {
+		return nil
+	}
+	if result.Type() != "parameter_list" {
+		return []string{string(result.Content(content))}
+	}
+
+	var types []string
+	for i := 0; i < int(result.NamedChildCount()); i++ {
+		param := result.NamedChild(i)
+		typeNode := param.ChildByFieldName("type")
+		if typeNode == nil {
+			continue
+		}
+		names := 0
+		for j := 0; j < int(param.NamedChildCount()); j++ {
+			if param.NamedChild(j).Type() == "identifier" {
+				names++
+			}
+		}
+		if names == 0 {
+			names = 1
+		}
+		for n := 0; n < names; n++ {
+			types = append(types, string(typeNode.Content(content)))
+		}
+	}
+	return types
+}
+
 // resolveAbsPath converts a relative path to an absolute path.
 func (p *GoParser) resolveAbsPath(rootDir, file string) (string, error) {
 	parts := strings.SplitN(file, "/", 3)
@@ -262,6 +402,16 @@ func (p *GoParser) resolveAbsPath(rootDir, file string) (string, error) {
 	return filepath.Join(rootDir, parts[2]), nil
 }
 
+// RepoRelativePath converts a project map file path (e.g. "/prbuddy-go/cmd/root.go")
+// into a path relative to the repository root (e.g. "cmd/root.go").
+func RepoRelativePath(file string) string {
+	parts := strings.SplitN(file, "/", 3)
+	if len(parts) < 3 || parts[0] != "" {
+		return file
+	}
+	return parts[2]
+}
+
 // DetectLanguages scans the project for .go files that are not ignored,
 // and returns "go" if any are found.
 func (p *GoParser) DetectLanguages(rootDir string) ([]Language, error) {
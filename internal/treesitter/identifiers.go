@@ -0,0 +1,41 @@
+// internal/treesitter/identifiers.go
+
+package treesitter
+
+import (
+	"context"
+	"fmt"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// ParseIdentifiers counts the identifiers of a Go file by name, declarations included.
+// Field and method names count too, so a method name appearing only in an interface or
+// a selector is still referenced.
+func ParseIdentifiers(file string, content []byte) (map[string]int, error) {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(golang.GetLanguage())
+
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil || tree == nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	defer tree.Close()
+
+	counts := make(map[string]int)
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "identifier", "field_identifier":
+			counts[node.Content(content)]++
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(tree.RootNode())
+	return counts, nil
+}
@@ -0,0 +1,47 @@
+// internal/treesitter/imports.go
+
+package treesitter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// ParseImports returns the import paths of a Go file, in source order.
+func ParseImports(file string, content []byte) ([]string, error) {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(golang.GetLanguage())
+
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil || tree == nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	defer tree.Close()
+
+	var imports []string
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if node.Type() == "import_spec" {
+			if path := node.ChildByFieldName("path"); path != nil {
+				imports = append(imports, strings.Trim(path.Content(content), "\"`"))
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+
+	root := tree.RootNode()
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		if node := root.NamedChild(i); node.Type() == "import_declaration" {
+			walk(node)
+		}
+	}
+	return imports, nil
+}
@@ -0,0 +1,88 @@
+// internal/treesitter/lookup.go
+
+package treesitter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// FindFunction resolves target against the Go files of the repository at rootDir, which
+// must be the current repository. target is a function name, a method as Type.Method,
+// or either prefixed with the file path, e.g. "internal/utils/diff.go:ParseUnifiedDiff".
+// It also returns every function parsed, so callers can look up related functions.
+func FindFunction(rootDir, target string) (FunctionInfo, []FunctionInfo, error) {
+	// Include untracked files so freshly written functions are found before the first commit.
+	files, err := utils.ExecGit("ls-files", "--cached", "--others", "--exclude-standard", "--full-name", ":/")
+	if err != nil {
+		return FunctionInfo{}, nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+	functions, err := ParseGoFiles(rootDir, utils.SplitLines(files))
+	if err != nil {
+		return FunctionInfo{}, nil, err
+	}
+
+	file, name := "", target
+	if idx := strings.LastIndex(target, ":"); idx != -1 {
+		file, name = target[:idx], target[idx+1:]
+	}
+	receiver := ""
+	if idx := strings.Index(name, "."); idx != -1 {
+		receiver, name = name[:idx], name[idx+1:]
+	}
+
+	var candidates []FunctionInfo
+	for _, fn := range functions {
+		if fn.Name != name || strings.HasSuffix(fn.File, "_test.go") {
+			continue
+		}
+		if receiver != "" && fn.Receiver != receiver {
+			continue
+		}
+		if file != "" && fn.File != filepath.ToSlash(filepath.Clean(file)) {
+			continue
+		}
+		candidates = append(candidates, fn)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return FunctionInfo{}, nil, fmt.Errorf("function %q not found", target)
+	case 1:
+		return candidates[0], functions, nil
+	default:
+		var names []string
+		for _, c := range candidates {
+			qualified := c.Name
+			if c.Receiver != "" {
+				qualified = c.Receiver + "." + c.Name
+			}
+			names = append(names, c.File+":"+qualified)
+		}
+		return FunctionInfo{}, nil, fmt.Errorf("function %q is ambiguous; use one of: %s", target, strings.Join(names, ", "))
+	}
+}
+
+// FunctionAt returns the innermost function of the repo-relative Go file under rootDir
+// whose line range contains line. The second result is false when line is outside
+// every function or the file cannot be parsed.
+func FunctionAt(rootDir, file string, line int) (FunctionInfo, bool) {
+	functions, err := ParseGoFiles(rootDir, []string{file})
+	if err != nil {
+		return FunctionInfo{}, false
+	}
+	var best FunctionInfo
+	found := false
+	for _, fn := range functions {
+		if line < fn.StartLine || line > fn.EndLine {
+			continue
+		}
+		if !found || fn.EndLine-fn.StartLine < best.EndLine-best.StartLine {
+			best, found = fn, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,85 @@
+// internal/treesitter/metrics.go
+
+package treesitter
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FunctionMetrics measures the shape of a function body.
+type FunctionMetrics struct {
+	Complexity  int `json:"complexity"`   // Cyclomatic: 1 + branches, cases and && / ||
+	MaxNesting  int `json:"max_nesting"`  // Deepest if/for/switch/select nesting
+	ReturnPaths int `json:"return_paths"` // Return statements, not counting closures
+}
+
+// branchNodes add a path through the function.
+var branchNodes = map[string]bool{
+	"if_statement":       true,
+	"for_statement":      true,
+	"expression_case":    true,
+	"type_case":          true,
+	"communication_case": true,
+}
+
+// nestingNodes open a level of nesting.
+var nestingNodes = map[string]bool{
+	"if_statement":                true,
+	"for_statement":               true,
+	"expression_switch_statement": true,
+	"type_switch_statement":       true,
+	"select_statement":            true,
+}
+
+// measureFunction computes the metrics of a function body. Closures count towards the
+// complexity and nesting of the function that contains them, but their returns do not.
+func measureFunction(body *sitter.Node) FunctionMetrics {
+	m := FunctionMetrics{Complexity: 1}
+	if body == nil {
+		return m
+	}
+
+	var walk func(node *sitter.Node, depth int, inClosure bool)
+	walk = func(node *sitter.Node, depth int, inClosure bool) {
+		switch t := node.Type(); {
+		case branchNodes[t]:
+			m.Complexity++
+		case t == "binary_expression":
+			if op := node.ChildByFieldName("operator"); op != nil && (op.Type() == "&&" || op.Type() == "||") {
+				m.Complexity++
+			}
+		case t == "return_statement":
+			if !inClosure {
+				m.ReturnPaths++
+			}
+		case t == "func_literal":
+			inClosure = true
+		}
+
+		if nestingNodes[node.Type()] && !isElseIf(node) {
+			depth++
+			if depth > m.MaxNesting {
+				m.MaxNesting = depth
+			}
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i), depth, inClosure)
+		}
+	}
+	walk(body, 0, false)
+	return m
+}
+
+// isElseIf reports whether an if statement is the else branch of another, which reads
+// as a sibling rather than a nested level.
+func isElseIf(node *sitter.Node) bool {
+	if node.Type() != "if_statement" {
+		return false
+	}
+	parent := node.Parent()
+	if parent == nil || parent.Type() != "if_statement" {
+		return false
+	}
+	alt := parent.ChildByFieldName("alternative")
+	return alt != nil && alt.StartByte() == node.StartByte()
+}
@@ -1,7 +1,12 @@
 package treesitter
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/soyuz43/prbuddy-go/internal/utils"
@@ -11,15 +16,25 @@ import (
 // Output Path Helpers
 // -----------------------------------------------------------------------------
 
+// scaffoldDir returns .git/pr_buddy_db/scaffold under the repository root, so maps are
+// found the same way from any subdirectory. Outside a repository it is cwd-relative.
+func scaffoldDir() string {
+	dir := filepath.Join(".git", "pr_buddy_db", "scaffold")
+	if repoPath, err := utils.GetRepoPath(); err == nil {
+		return filepath.Join(repoPath, dir)
+	}
+	return dir
+}
+
 // getMetadataOutputPath returns the output path for the metadata file.
 // If branchName is provided, it includes the branch name in the filename.
 // Otherwise, it falls back to the format: project_metadata-<month>-<day>.json
 func getMetadataOutputPath(branchName string) string {
 	now := time.Now()
 	if branchName != "" {
-		return fmt.Sprintf(".git/pr_buddy_db/scaffold/project_metadata-%s-%02d-%02d.json", branchName, now.Month(), now.Day())
+		return filepath.Join(scaffoldDir(), fmt.Sprintf("project_metadata-%s-%02d-%02d.json", branchName, now.Month(), now.Day()))
 	}
-	return fmt.Sprintf(".git/pr_buddy_db/scaffold/project_metadata-%02d-%02d.json", now.Month(), now.Day())
+	return filepath.Join(scaffoldDir(), fmt.Sprintf("project_metadata-%02d-%02d.json", now.Month(), now.Day()))
 }
 
 // getProjectMapOutputPath returns the output path for the project map file.
@@ -27,9 +42,9 @@ func getMetadataOutputPath(branchName string) string {
 func getProjectMapOutputPath(branchName string) string {
 	now := time.Now()
 	if branchName != "" {
-		return fmt.Sprintf(".git/pr_buddy_db/scaffold/project_map-%s-%02d-%02d.json", branchName, now.Month(), now.Day())
+		return filepath.Join(scaffoldDir(), fmt.Sprintf("project_map-%s-%02d-%02d.json", branchName, now.Month(), now.Day()))
 	}
-	return fmt.Sprintf(".git/pr_buddy_db/scaffold/project_map-%02d-%02d.json", now.Month(), now.Day())
+	return filepath.Join(scaffoldDir(), fmt.Sprintf("project_map-%02d-%02d.json", now.Month(), now.Day()))
 }
 
 // -----------------------------------------------------------------------------
@@ -58,3 +73,63 @@ func SaveProjectMap(projectMap *ProjectMap, branchName string) error {
 	outputPath := getProjectMapOutputPath(branchName)
 	return utils.WriteFile(outputPath, data)
 }
+
+// -----------------------------------------------------------------------------
+// Loading Functions
+// -----------------------------------------------------------------------------
+
+// ErrNoProjectMap is returned when no project map has been saved for a branch.
+var ErrNoProjectMap = errors.New("no saved project map")
+
+// LatestProjectMapPath returns the most recently written project map of a branch. Each
+// `map` run saves a date-stamped file, so the newest one is the current map.
+func LatestProjectMapPath(branchName string) (string, error) {
+	// Match the saved names with any date in place of today's.
+	today := getProjectMapOutputPath(branchName)
+	dir, base := filepath.Split(today)
+	stem := base[:len(base)-len("01-02.json")]
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(stem) + `\d{2}-\d{2}\.json$`)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w for branch %q", ErrNoProjectMap, branchName)
+		}
+		return "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	latest, latestTime := "", time.Time{}
+	for _, entry := range entries {
+		if entry.IsDir() || !pattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%w for branch %q", ErrNoProjectMap, branchName)
+	}
+	return latest, nil
+}
+
+// LoadProjectMap reads the current project map of a branch and returns it with the path
+// it was read from.
+func LoadProjectMap(branchName string) (*ProjectMap, string, error) {
+	path, err := LatestProjectMapPath(branchName)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var projectMap ProjectMap
+	if err := json.Unmarshal(data, &projectMap); err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &projectMap, path, nil
+}
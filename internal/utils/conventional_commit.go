@@ -0,0 +1,135 @@
+// internal/utils/conventional_commit.go
+
+package utils
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConventionalCommitTypes are the commit types accepted by the Conventional Commits spec
+// as commonly configured (Angular convention).
+var ConventionalCommitTypes = []string{
+	"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert",
+}
+
+// MaxCommitHeaderLength is the conventional 72 column limit for the header, in characters.
+const MaxCommitHeaderLength = 72
+
+// ConventionalCommit is a structured commit message: type(scope)!: subject, then body.
+type ConventionalCommit struct {
+	Type     string `json:"type"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body,omitempty"`
+	Breaking bool   `json:"breaking,omitempty"`
+}
+
+// Header renders the first line, e.g. "feat(llm)!: add review command".
+func (c ConventionalCommit) Header() string {
+	var b strings.Builder
+	b.WriteString(c.Type)
+	if c.Scope != "" {
+		b.WriteString("(" + c.Scope + ")")
+	}
+	if c.Breaking {
+		b.WriteString("!")
+	}
+	b.WriteString(": " + c.Subject)
+	return b.String()
+}
+
+// String renders the full commit message.
+func (c ConventionalCommit) String() string {
+	if strings.TrimSpace(c.Body) == "" {
+		return c.Header() + "\n"
+	}
+	return c.Header() + "\n\n" + strings.TrimSpace(c.Body) + "\n"
+}
+
+// Normalize lowercases the type (falling back to "chore" for unknown types), and trims
+// the subject to a single line without a trailing period or leading capital.
+func (c *ConventionalCommit) Normalize() {
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	if !StringSliceContains(ConventionalCommitTypes, c.Type) {
+		c.Type = "chore"
+	}
+	c.Scope = strings.ToLower(strings.TrimSpace(c.Scope))
+
+	subject := strings.TrimSpace(strings.SplitN(c.Subject, "\n", 2)[0])
+	subject = strings.TrimRight(subject, ". ")
+	if len(subject) > 1 && subject[0] >= 'A' && subject[0] <= 'Z' && !(subject[1] >= 'A' && subject[1] <= 'Z') {
+		subject = strings.ToLower(subject[:1]) + subject[1:]
+	}
+	c.Subject = ""
+	// Count characters rather than bytes, and cut on rune boundaries.
+	runes := []rune(subject)
+	if limit := MaxCommitHeaderLength - utf8.RuneCountInString(c.Header()); limit > 0 && len(runes) > limit {
+		subject = strings.TrimSpace(string(runes[:limit]))
+	}
+	c.Subject = subject
+	c.Body = strings.TrimSpace(c.Body)
+}
+
+// InferCommitScope derives a scope from the package directories of the changed files:
+// the directory name when they all live in one package (or below a common package
+// directory), and "" when changes span unrelated areas or the repository root.
+func InferCommitScope(files []string) string {
+	common := ""
+	for i, f := range files {
+		dir := path.Dir(strings.TrimSpace(f))
+		if dir == "." {
+			return ""
+		}
+		if i == 0 {
+			common = dir
+			continue
+		}
+		for common != "." && dir != common && !strings.HasPrefix(dir, common+"/") {
+			common = path.Dir(common)
+		}
+		if common == "." {
+			return ""
+		}
+	}
+
+	scope := path.Base(common)
+	// Layout directories say nothing about what changed.
+	switch scope {
+	case ".", "internal", "pkg", "src", "lib":
+		return ""
+	}
+	return scope
+}
+
+// conventionalHeaderPattern matches "type(scope)!: subject".
+var conventionalHeaderPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+
+// ParseConventionalCommit parses a commit message written in Conventional Commits form.
+// A "BREAKING CHANGE:" (or "BREAKING-CHANGE:") footer marks the commit as breaking.
+// The second result is false when the header does not follow the convention.
+func ParseConventionalCommit(message string) (ConventionalCommit, bool) {
+	message = strings.TrimSpace(message)
+	header, body, _ := strings.Cut(message, "\n")
+
+	m := conventionalHeaderPattern.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil || !StringSliceContains(ConventionalCommitTypes, strings.ToLower(m[1])) {
+		return ConventionalCommit{}, false
+	}
+
+	c := ConventionalCommit{
+		Type:     strings.ToLower(m[1]),
+		Scope:    strings.TrimSpace(m[2]),
+		Breaking: m[3] == "!",
+		Subject:  strings.TrimSpace(m[4]),
+		Body:     strings.TrimSpace(body),
+	}
+	for _, line := range strings.Split(c.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			c.Breaking = true
+		}
+	}
+	return c, true
+}
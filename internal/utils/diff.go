@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -49,3 +51,211 @@ func GetDiffs(mode DiffMode) (string, error) {
 		return "", fmt.Errorf("unknown diff mode: %d", mode)
 	}
 }
+
+// FileDiff is one file section of a unified diff.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Header  []string // "diff --git", "index", "---", "+++" and similar lines, verbatim
+	Hunks   []Hunk
+}
+
+// Hunk is a single "@@ -a,b +c,d @@" section of a file diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Header   string   // the raw "@@ ... @@" line
+	Lines    []string // hunk body lines including their " ", "+", "-" or "\" prefix
+}
+
+// Path returns the post-change path, or the old path for deletions.
+func (f FileDiff) Path() string {
+	if f.NewPath != "" && f.NewPath != "/dev/null" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// IsNew reports whether the file was created by this diff.
+func (f FileDiff) IsNew() bool {
+	return f.OldPath == "/dev/null"
+}
+
+// IsDeleted reports whether the file was removed by this diff.
+func (f FileDiff) IsDeleted() bool {
+	return f.NewPath == "/dev/null"
+}
+
+// AddedLines returns the new-file line numbers of every added line in the hunk.
+func (h Hunk) AddedLines() []int {
+	var lines []int
+	current := h.NewStart
+	for _, l := range h.Lines {
+		switch {
+		case strings.HasPrefix(l, "+"):
+			lines = append(lines, current)
+			current++
+		case strings.HasPrefix(l, " "):
+			current++
+		}
+	}
+	return lines
+}
+
+// NewSideLines returns the new-file line numbers of every added or context line in the hunk.
+func (h Hunk) NewSideLines() []int {
+	var lines []int
+	current := h.NewStart
+	for _, l := range h.Lines {
+		if strings.HasPrefix(l, "+") || strings.HasPrefix(l, " ") {
+			lines = append(lines, current)
+			current++
+		}
+	}
+	return lines
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseUnifiedDiff splits `git diff` output into files and hunks.
+// Lines it does not recognise are kept in the enclosing file header or hunk body.
+func ParseUnifiedDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var current *FileDiff
+	var hunk *Hunk
+
+	flushHunk := func() {
+		if current != nil && hunk != nil {
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if current != nil {
+			files = append(files, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			current = &FileDiff{Header: []string{line}}
+			current.OldPath, current.NewPath = parseDiffGitPaths(strings.TrimPrefix(line, "diff --git "))
+		case current == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, "--- "):
+			current.Header = append(current.Header, line)
+			current.OldPath = strings.TrimPrefix(headerPath(strings.TrimPrefix(line, "--- ")), "a/")
+		case hunk == nil && strings.HasPrefix(line, "+++ "):
+			current.Header = append(current.Header, line)
+			current.NewPath = strings.TrimPrefix(headerPath(strings.TrimPrefix(line, "+++ ")), "b/")
+		case hunk == nil && strings.HasPrefix(line, "rename from "):
+			current.Header = append(current.Header, line)
+			current.OldPath = unquoteGitPath(strings.TrimPrefix(line, "rename from "))
+		case hunk == nil && strings.HasPrefix(line, "rename to "):
+			current.Header = append(current.Header, line)
+			current.NewPath = unquoteGitPath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			hunk = &Hunk{
+				OldStart: atoiDefault(m[1], 0),
+				OldLines: atoiDefault(m[2], 1),
+				NewStart: atoiDefault(m[3], 0),
+				NewLines: atoiDefault(m[4], 1),
+				Header:   line,
+			}
+		case hunk != nil:
+			if line == "" {
+				// Trailing newline of the diff output; real context lines carry a leading space.
+				continue
+			}
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			current.Header = append(current.Header, line)
+		}
+	}
+	flushFile()
+
+	return files
+}
+
+// parseDiffGitPaths splits the "a/<old> b/<new>" part of a `diff --git` line. Paths
+// with unusual characters are C-quoted; plain paths may contain spaces, so the line is
+// split in the middle when both halves name the same file. The ---, +++ and rename
+// headers that follow take precedence where git prints them.
+func parseDiffGitPaths(rest string) (string, string) {
+	if strings.HasPrefix(rest, `"`) {
+		if end := closingQuote(rest); end > 0 {
+			oldPath := unquoteGitPath(rest[:end+1])
+			newPath := unquoteGitPath(strings.TrimSpace(rest[end+1:]))
+			return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/")
+		}
+	}
+	if n := len(rest); n%2 == 1 && rest[n/2] == ' ' {
+		oldPath, newPath := rest[:n/2], rest[n/2+1:]
+		if strings.HasPrefix(oldPath, "a/") && strings.HasPrefix(newPath, "b/") && oldPath[2:] == newPath[2:] {
+			return oldPath[2:], newPath[2:]
+		}
+	}
+	if strings.HasPrefix(rest, "a/") {
+		if i := strings.Index(rest, ` "b/`); i > 0 {
+			return rest[2:i], strings.TrimPrefix(unquoteGitPath(rest[i+1:]), "b/")
+		}
+		if i := strings.Index(rest, " b/"); i > 0 {
+			return rest[2:i], rest[i+3:]
+		}
+	}
+	return "", ""
+}
+
+// headerPath extracts the path from a --- or +++ line, which git ends with a tab when
+// the path contains spaces.
+func headerPath(s string) string {
+	return unquoteGitPath(strings.TrimSuffix(s, "\t"))
+}
+
+// unquoteGitPath decodes a path git quoted because of special characters, e.g.
+// "a/t\303\251st.go". Unquoted paths are returned as they are.
+func unquoteGitPath(s string) string {
+	if !strings.HasPrefix(s, `"`) {
+		return s
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// closingQuote returns the index of the quote ending the quoted string at the start of s.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// atoiDefault parses s as an integer, returning def when s is empty or invalid.
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
@@ -3,7 +3,9 @@ package utils
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -20,20 +22,91 @@ func ExecGit(args ...string) (string, error) {
 
 	err := cmd.Run()
 	if err != nil {
-		return "", fmt.Errorf("git %s failed: %w (stderr: %q)",
-			strings.Join(args, " "),
-			err,
-			stderr.String())
+		return "", gitError(args, err, stderr.String())
 	}
 
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// ErrNotGitRepo is wrapped by git errors raised outside a repository.
+var ErrNotGitRepo = errors.New("not a git repository")
+
+func gitError(args []string, err error, stderr string) error {
+	if strings.Contains(stderr, "not a git repository") {
+		err = fmt.Errorf("%w: %w", ErrNotGitRepo, err)
+	}
+	return fmt.Errorf("git %s failed: %w (stderr: %q)", strings.Join(args, " "), err, stderr)
+}
+
+// ExecGitRaw is like ExecGit but returns stdout untrimmed (so patches stay byte-exact)
+// and runs git with extra environment variables such as GIT_INDEX_FILE.
+func ExecGitRaw(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", gitError(args, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// ReadBlobs returns the contents of files at rev, read through a single
+// `git cat-file --batch`. Files missing at rev are left out of the map.
+func ReadBlobs(rev string, files []string) (map[string][]byte, error) {
+	var stdin bytes.Buffer
+	for _, file := range files {
+		fmt.Fprintf(&stdin, "%s:%s\n", rev, file)
+	}
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Stdin = &stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, gitError([]string{"cat-file", "--batch"}, err, stderr.String())
+	}
+
+	blobs := make(map[string][]byte, len(files))
+	out := bufio.NewReader(&stdout)
+	for _, file := range files {
+		header, err := out.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("truncated git cat-file output at %s: %w", file, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue // "<object> missing" or "ambiguous"
+		}
+		var size int
+		if _, err := fmt.Sscanf(fields[2], "%d", &size); err != nil {
+			return nil, fmt.Errorf("unexpected git cat-file header %q", header)
+		}
+		content := make([]byte, size+1) // Contents are followed by a newline
+		if _, err := io.ReadFull(out, content); err != nil {
+			return nil, fmt.Errorf("truncated git cat-file output at %s: %w", file, err)
+		}
+		if fields[1] == "blob" {
+			blobs[file] = content[:size]
+		}
+	}
+	return blobs, nil
+}
+
 // GetRepoPath returns the top-level path of the current Git repository.
 func GetRepoPath() (string, error) {
 	return ExecGit("rev-parse", "--show-toplevel")
 }
 
+// GetGitPath resolves a path inside the repository's git directory, such as MERGE_HEAD,
+// the way git does, so it is right in linked worktrees and with $GIT_DIR set. Relative
+// results are relative to the current directory.
+func GetGitPath(name string) (string, error) {
+	return ExecGit("rev-parse", "--git-path", name)
+}
+
 // ReadGitignore reads the .gitignore file at the given root directory and returns
 // a slice of compiled regular expressions representing ignore patterns.
 func ReadGitignore(rootDir string) ([]*regexp.Regexp, error) {
@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 // MarshalJSON converts the given data to a pretty-printed JSON string.
@@ -13,3 +15,30 @@ func MarshalJSON(data interface{}) (string, error) {
 	}
 	return string(jsonBytes), nil
 }
+
+// thinkBlockPattern matches reasoning blocks some models emit before their answer.
+var thinkBlockPattern = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// ExtractJSON returns the first JSON object or array embedded in an LLM response,
+// ignoring reasoning blocks, markdown code fences, and surrounding prose. Each { or [
+// is tried in turn and decoded up to its matching close, so braces in the prose before
+// or after the JSON do not matter.
+func ExtractJSON(response string) (string, error) {
+	cleaned := thinkBlockPattern.ReplaceAllString(response, "")
+
+	start := strings.IndexAny(cleaned, "{[")
+	if start == -1 {
+		return "", fmt.Errorf("no JSON found in response")
+	}
+	for {
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(cleaned[start:])).Decode(&raw); err == nil {
+			return string(raw), nil
+		}
+		next := strings.IndexAny(cleaned[start+1:], "{[")
+		if next == -1 {
+			return "", fmt.Errorf("invalid JSON in response")
+		}
+		start += next + 1
+	}
+}
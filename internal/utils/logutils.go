@@ -64,7 +64,7 @@ func SaveContextToFile(conversationID string, messages []contextpkg.Message) err
 		return fmt.Errorf("failed to write context to file: %w", err)
 	}
 
-	fmt.Printf("[Context Logger] Structured context successfully saved to %s\n", filePath)
+	fmt.Fprintf(os.Stderr, "[Context Logger] Structured context successfully saved to %s\n", filePath)
 	return nil
 }
 
@@ -96,6 +96,6 @@ func SaveConcatenatedContextToFile(conversationID string, messages []contextpkg.
 		return fmt.Errorf("failed to write concatenated context to file: %w", err)
 	}
 
-	fmt.Printf("[Context Logger] Concatenated context successfully saved to %s\n", filePath)
+	fmt.Fprintf(os.Stderr, "[Context Logger] Concatenated context successfully saved to %s\n", filePath)
 	return nil
 }
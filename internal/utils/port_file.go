@@ -13,8 +13,10 @@ import (
 const (
 	appName      = "prbuddy-go"
 	portFileName = "port"
-	filePerm     = 0600 // rw-------
-	dirPerm      = 0700 // rwx------
+	// tokenFileName holds the serve API bearer token for the current session.
+	tokenFileName = "token"
+	filePerm      = 0600 // rw-------
+	dirPerm       = 0700 // rwx------
 )
 
 // EnsureAppCacheDir creates (if necessary) and verifies the application cache directory.
@@ -31,6 +33,14 @@ func EnsureAppCacheDir() error {
 	return verifyDirectoryPermissions(cacheDir)
 }
 
+// AppCacheDir ensures the application cache directory exists and returns its path.
+func AppCacheDir() (string, error) {
+	if err := EnsureAppCacheDir(); err != nil {
+		return "", err
+	}
+	return getAppCacheDirPath()
+}
+
 func getAppCacheDirPath() (string, error) {
 	cacheDir, err := os.UserCacheDir()
 	if err != nil {
@@ -61,7 +71,20 @@ func WritePortFile(port int) error {
 	if port < 1 || port > 65535 {
 		return fmt.Errorf("invalid port number: %d", port)
 	}
+	return writeCacheFile(portFileName, strconv.Itoa(port))
+}
+
+// WriteTokenFile writes the serve API bearer token next to the port file, readable
+// only by the current user.
+func WriteTokenFile(token string) error {
+	if token == "" {
+		return fmt.Errorf("empty token")
+	}
+	return writeCacheFile(tokenFileName, token)
+}
 
+// writeCacheFile atomically replaces name in the cache directory with content.
+func writeCacheFile(name, content string) error {
 	if err := EnsureAppCacheDir(); err != nil {
 		return fmt.Errorf("cache directory validation failed: %w", err)
 	}
@@ -71,26 +94,30 @@ func WritePortFile(port int) error {
 		return err
 	}
 
-	tmpFile, err := os.CreateTemp(cacheDir, "port-*.tmp")
+	tmpFile, err := os.CreateTemp(cacheDir, name+"-*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer cleanupTempFile(tmpFile)
 
-	if err := performAtomicWrite(tmpFile, port); err != nil {
+	if err := performAtomicWrite(tmpFile, content); err != nil {
 		return err
 	}
 
-	return finalizePortFile(tmpFile, cacheDir)
+	return finalizeCacheFile(tmpFile, cacheDir, name)
 }
 
-func performAtomicWrite(tmpFile *os.File, port int) error {
+func performAtomicWrite(tmpFile *os.File, content string) error {
 	if err := syscall.Flock(int(tmpFile.Fd()), syscall.LOCK_EX); err != nil {
 		return fmt.Errorf("file lock failed: %w", err)
 	}
 
-	if _, err := fmt.Fprintf(tmpFile, "%d", port); err != nil {
-		return fmt.Errorf("port write failed: %w", err)
+	if err := tmpFile.Chmod(filePerm); err != nil {
+		return fmt.Errorf("file chmod failed: %w", err)
+	}
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		return fmt.Errorf("write failed: %w", err)
 	}
 
 	if err := tmpFile.Sync(); err != nil {
@@ -100,8 +127,8 @@ func performAtomicWrite(tmpFile *os.File, port int) error {
 	return nil
 }
 
-func finalizePortFile(tmpFile *os.File, cacheDir string) error {
-	finalPath := filepath.Join(cacheDir, portFileName)
+func finalizeCacheFile(tmpFile *os.File, cacheDir, name string) error {
+	finalPath := filepath.Join(cacheDir, name)
 	if err := os.Rename(tmpFile.Name(), finalPath); err != nil {
 		return fmt.Errorf("atomic rename failed: %w", err)
 	}
@@ -116,31 +143,51 @@ func cleanupTempFile(tmpFile *os.File) {
 
 // ReadPortFile reads and validates the port number from the port file.
 func ReadPortFile() (int, error) {
+	data, err := readCacheFile(portFileName)
+	if err != nil {
+		return 0, err
+	}
+	return validatePortData(data)
+}
+
+// ReadTokenFile returns the bearer token of the running serve session.
+func ReadTokenFile() (string, error) {
+	data, err := readCacheFile(tokenFileName)
+	if err != nil {
+		return "", err
+	}
+	token := string(bytes.TrimSpace(data))
+	if token == "" {
+		return "", fmt.Errorf("empty token file")
+	}
+	return token, nil
+}
+
+func readCacheFile(name string) ([]byte, error) {
 	if err := EnsureAppCacheDir(); err != nil {
-		return 0, fmt.Errorf("cache directory validation failed: %w", err)
+		return nil, fmt.Errorf("cache directory validation failed: %w", err)
 	}
 
 	cacheDir, err := getAppCacheDirPath()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	file, err := os.Open(filepath.Join(cacheDir, portFileName))
+	file, err := os.Open(filepath.Join(cacheDir, name))
 	if err != nil {
-		return 0, fmt.Errorf("failed to open port file: %w", err)
+		return nil, fmt.Errorf("failed to open %s file: %w", name, err)
 	}
 	defer file.Close()
 
 	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_SH); err != nil {
-		return 0, fmt.Errorf("file lock failed: %w", err)
+		return nil, fmt.Errorf("file lock failed: %w", err)
 	}
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return 0, fmt.Errorf("read failed: %w", err)
+		return nil, fmt.Errorf("read failed: %w", err)
 	}
-
-	return validatePortData(data)
+	return data, nil
 }
 
 func validatePortData(data []byte) (int, error) {
@@ -163,13 +210,22 @@ func validatePortData(data []byte) (int, error) {
 
 // DeletePortFile removes the port file.
 func DeletePortFile() error {
+	return deleteCacheFile(portFileName)
+}
+
+// DeleteTokenFile removes the token file.
+func DeleteTokenFile() error {
+	return deleteCacheFile(tokenFileName)
+}
+
+func deleteCacheFile(name string) error {
 	cacheDir, err := getAppCacheDirPath()
 	if err != nil {
 		return err
 	}
 
-	portPath := filepath.Join(cacheDir, portFileName)
-	if err := os.Remove(portPath); err != nil {
+	path := filepath.Join(cacheDir, name)
+	if err := os.Remove(path); err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
@@ -0,0 +1,67 @@
+// internal/utils/repo_path.go
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideRepo is returned for paths that resolve outside the repository.
+var ErrOutsideRepo = errors.New("path is outside the repository")
+
+// ResolveRepoPath confines a path supplied by a user or a model to the repository at
+// root, which must already be free of symlinks (see filepath.EvalSymlinks). It returns
+// the cleaned repo-relative slash path, empty for the root itself, and the absolute
+// path. Absolute paths, ".." escapes, symlinks leading out of the repository and
+// anything under .git are refused. The path does not need to exist.
+func ResolveRepoPath(root, path string) (rel string, abs string, err error) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "." || path == "./" {
+		return "", root, nil
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "~") {
+		return "", "", fmt.Errorf("%w: %s (use a repo-relative path)", ErrOutsideRepo, path)
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if escapes(clean) {
+		return "", "", fmt.Errorf("%w: %s", ErrOutsideRepo, path)
+	}
+	abs = filepath.Join(root, clean)
+
+	// Follow symlinks on the deepest existing ancestor, so neither an existing link
+	// nor a new file under a linked directory can lead outside the repository.
+	existing := abs
+	for {
+		if _, err := os.Lstat(existing); err == nil || existing == root {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+		within, relErr := filepath.Rel(root, resolved)
+		if relErr != nil || escapes(within) {
+			return "", "", fmt.Errorf("%w: %s", ErrOutsideRepo, path)
+		}
+	}
+
+	rel = filepath.ToSlash(clean)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return "", "", fmt.Errorf("access to .git is not allowed: %s", path)
+	}
+	return rel, abs, nil
+}
+
+func escapes(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// IsGitIgnored reports whether git ignores the repo-relative path in the repository at root.
+func IsGitIgnored(root, rel string) bool {
+	_, err := ExecGit("-C", root, "check-ignore", "-q", "--", rel)
+	return err == nil
+}
@@ -1,6 +1,12 @@
 package utils
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
+
+// codeFencePattern matches markdown code fences, capturing the language tag and body.
+var codeFencePattern = regexp.MustCompile("(?s)```([\\w+-]*)[^\\n]*\\n(.*?)```")
 
 // SplitLines splits a string into lines, removing any trailing newline.
 func SplitLines(s string) []string {
@@ -26,3 +32,24 @@ func StringSliceContains(slice []string, val string) bool {
 	}
 	return false
 }
+
+// ExtractCodeBlock returns the longest fenced code block in an LLM response whose
+// language tag is lang (or untagged). If the response has no matching fence, the
+// response itself is returned with reasoning blocks removed.
+func ExtractCodeBlock(response, lang string) string {
+	cleaned := thinkBlockPattern.ReplaceAllString(response, "")
+
+	best := ""
+	for _, m := range codeFencePattern.FindAllStringSubmatch(cleaned, -1) {
+		if m[1] != "" && !strings.EqualFold(m[1], lang) {
+			continue
+		}
+		if len(m[2]) > len(best) {
+			best = m[2]
+		}
+	}
+	if best == "" {
+		return strings.TrimSpace(cleaned)
+	}
+	return strings.TrimSpace(best) + "\n"
+}
@@ -0,0 +1,282 @@
+// test/agent/agent_test.go
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/agent"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func call(name, args string) contextpkg.ToolCall {
+	return contextpkg.ToolCall{Function: contextpkg.ToolCallFunction{Name: name, Arguments: json.RawMessage(args)}}
+}
+
+func TestToolboxIsSandboxedToTheRepository(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	if err := os.WriteFile(".gitignore", []byte("secret.env\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitignore: %v", err)
+	}
+	if err := os.WriteFile("secret.env", []byte("TOKEN=abc\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink("/etc", "etc-link"); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	toolbox, err := agent.NewToolbox(repoPath)
+	if err != nil {
+		t.Fatalf("NewToolbox failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call contextpkg.ToolCall
+		want string
+	}{
+		{"read file", call("read_file", `{"path": "cmd/context.go", "start_line": 11, "end_line": 13}`),
+			"File: cmd/context.go\nLines 11-13 of 13\n   11  func ExampleFunction() {\n   12  \t// Example implementation\n   13  }\n"},
+		{"parent escape", call("read_file", `{"path": "../outside.txt"}`), "Error: path is outside the repository"},
+		{"absolute path", call("read_file", `{"path": "/etc/passwd"}`), "Error: path is outside the repository"},
+		{"symlink escape", call("read_file", `{"path": "etc-link/passwd"}`), "Error: path is outside the repository"},
+		{"git directory", call("read_file", `{"path": ".git/config"}`), "Error: access to .git is not allowed"},
+		{"ignored file", call("read_file", `{"path": "secret.env"}`), "Error: secret.env is ignored by git"},
+		{"grep", call("grep", `{"pattern": "func (New|Handle)", "path": "internal"}`),
+			"File: internal/dce/command_menu.go\n3:func HandleDCECommandMenu(input string, littleguy *LittleGuy) bool {\nFile: internal/dce/dce.go\n11:func NewDCE() DCE {"},
+		{"grep without matches", call("grep", `{"pattern": "nothing-matches-this"}`), "No matches."},
+		{"list functions", call("list_functions", `{"path": "cmd"}`),
+			"cmd/context.go:7-9  func init()\ncmd/context.go:11-13  func ExampleFunction()\n"},
+		{"git log", call("git_log", `{"path": "README.md"}`), "Initial commit"},
+		{"git blame", call("git_blame", `{"path": "cmd/context.go", "start_line": 11, "end_line": 11}`), "func ExampleFunction() {"},
+		{"clean diff", call("show_diff", `{}`), "No uncommitted changes."},
+		{"option injection", call("show_diff", `{"commit": "--output=/tmp/x"}`), `Error: invalid commit "--output=/tmp/x"`},
+		{"bad arguments", call("read_file", `{"path": 3}`), "Error: invalid arguments"},
+		{"unknown tool", call("rm", `{}`), `Error: unknown tool "rm"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolbox.Execute(tt.call); !strings.Contains(got, tt.want) {
+				t.Errorf("Expected output containing %q, got:\n%s", tt.want, got)
+			}
+		})
+	}
+
+	toolbox.MaxOutputBytes = 60
+	if got := toolbox.Execute(call("read_file", `{"path": "cmd/context.go"}`)); !strings.Contains(got, "[truncated") || len(got) > 120 {
+		t.Errorf("Expected capped output, got:\n%s", got)
+	}
+}
+
+func TestToolboxWithholdsSecrets(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	files := map[string]string{
+		".git/pr_buddy_db/redaction.json": `{"deny_paths": ["secrets/**"]}`,
+		"secrets/db.yaml":                 "password: hunter2-db\n",
+		"config/.env":                     "DB_PASSWORD=hunter2-env\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	if _, err := utils.ExecGit("add", "secrets", "config"); err != nil {
+		t.Fatalf("Failed to add files: %v", err)
+	}
+	toolbox, err := agent.NewToolbox(repoPath)
+	if err != nil {
+		t.Fatalf("NewToolbox failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call contextpkg.ToolCall
+		want string
+	}{
+		{"denied read", call("read_file", `{"path": "secrets/db.yaml"}`), "Error: secrets/db.yaml is on the redaction denylist"},
+		{"denied blame", call("git_blame", `{"path": "secrets/db.yaml", "start_line": 1, "end_line": 1}`), "Error: secrets/db.yaml is on the redaction denylist"},
+		{"denied grep", call("grep", `{"pattern": "hunter2-db"}`), "No matches."},
+		{"env read", call("read_file", `{"path": "config/.env"}`), "DB_PASSWORD=[REDACTED:env-value-"},
+		{"env grep", call("grep", `{"pattern": "hunter2"}`), "File: config/.env\n1:DB_PASSWORD=[REDACTED:env-value-"},
+		{"uncommitted diff", call("show_diff", `{}`), "+DB_PASSWORD=[REDACTED:env-value-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toolbox.Execute(tt.call)
+			if !strings.Contains(got, tt.want) || strings.Contains(got, "hunter2") {
+				t.Errorf("Expected output containing %q and no secret, got:\n%s", tt.want, got)
+			}
+		})
+	}
+
+	// Denied files are left out of diffs entirely, as they are from grep.
+	if got := toolbox.Execute(call("show_diff", `{}`)); strings.Contains(got, "secrets/db.yaml") {
+		t.Errorf("show_diff included a denied file:\n%s", got)
+	}
+	if _, err := utils.ExecGit("commit", "-m", "Add config"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	got := toolbox.Execute(call("show_diff", `{"commit": "HEAD"}`))
+	if strings.Contains(got, "secrets/db.yaml") || !strings.Contains(got, "Add config") || !strings.Contains(got, "config/.env") {
+		t.Errorf("Expected the commit without its denied file, got:\n%s", got)
+	}
+}
+
+// fakeModel answers chat requests with scripted replies and records the requests.
+type fakeModel struct {
+	mu       sync.Mutex
+	replies  []string
+	requests []map[string]interface{}
+}
+
+func (f *fakeModel) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Bad request body: %v", err)
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, req)
+		reply := f.replies[0]
+		if len(f.replies) > 1 {
+			f.replies = f.replies[1:]
+		}
+		fmt.Fprint(w, reply)
+	}
+}
+
+// lastMessage returns the final message of the i-th request.
+func (f *fakeModel) lastMessage(i int) map[string]interface{} {
+	messages := f.requests[i]["messages"].([]interface{})
+	return messages[len(messages)-1].(map[string]interface{})
+}
+
+func TestQuickAssistUsesToolsOverOllama(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	model := &fakeModel{replies: []string{
+		`{"message": {"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "read_file", "arguments": {"path": "cmd/context.go"}}}]}, "done": true}`,
+		`{"message": {"role": "assistant", "content": "ExampleFunction is empty."}, "done": true}`,
+	}}
+	server := httptest.NewServer(model.handler(t))
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	contextpkg.SetActiveModel("fake-model")
+	defer contextpkg.SetActiveModel("")
+
+	reply, err := llm.HandleQuickAssist("", "What does ExampleFunction do?")
+	if err != nil || reply != "ExampleFunction is empty." {
+		t.Fatalf("HandleQuickAssist = %q, %v", reply, err)
+	}
+	if len(model.requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(model.requests))
+	}
+	if tools, _ := model.requests[0]["tools"].([]interface{}); len(tools) != 6 {
+		t.Errorf("Expected 6 tools in the request, got %v", model.requests[0]["tools"])
+	}
+	result := model.lastMessage(1)
+	if result["role"] != "tool" || result["tool_name"] != "read_file" || !strings.Contains(result["content"].(string), "func ExampleFunction() {") {
+		t.Errorf("Expected the read_file result to be sent back, got %v", result)
+	}
+}
+
+func TestQuickAssistFallsBackWithoutToolSupport(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			http.Error(w, `{"error": "fake-model does not support tools"}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, `{"message": {"role": "assistant", "content": "Plain answer"}, "done": false}`)
+		fmt.Fprintln(w, `{"message": {"role": "assistant", "content": ""}, "done": true}`)
+	}))
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	contextpkg.SetActiveModel("fake-model")
+	defer contextpkg.SetActiveModel("")
+
+	reply, err := llm.HandleQuickAssist("", "hi")
+	if err != nil || reply != "Plain answer" {
+		t.Fatalf("HandleQuickAssist = %q, %v", reply, err)
+	}
+}
+
+func TestRunAgentOverOpenAIFormatStopsAtStepLimit(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	toolCall := `{"choices": [{"message": {"role": "assistant", "content": null, "tool_calls": [
+		{"id": "call_%d", "type": "function", "function": {"name": "grep", "arguments": "{\"pattern\": \"ExampleFunction\"}"}}]}}],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 5}}`
+	model := &fakeModel{replies: []string{
+		fmt.Sprintf(toolCall, 1),
+		fmt.Sprintf(toolCall, 2),
+		`{"choices": [{"message": {"role": "assistant", "content": "It is defined in cmd/context.go."}}]}`,
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("Unexpected request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		model.handler(t)(w, r)
+	}))
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL+"/v1")
+	t.Setenv(llm.LLMAPIEnv, "openai")
+	t.Setenv(llm.LLMAPIKeyEnv, "sk-test")
+	contextpkg.SetActiveModel("fake-model")
+	defer contextpkg.SetActiveModel("")
+
+	toolbox, err := agent.NewToolbox(repoPath)
+	if err != nil {
+		t.Fatalf("NewToolbox failed: %v", err)
+	}
+	messages := []contextpkg.Message{{Role: "user", Content: "Where is ExampleFunction?"}}
+	reply, err := llm.RunAgent(&llm.DefaultLLMClient{}, messages, toolbox, llm.AgentOptions{MaxSteps: 2})
+	if err != nil || reply != "It is defined in cmd/context.go." {
+		t.Fatalf("RunAgent = %q, %v", reply, err)
+	}
+
+	if len(model.requests) != 3 {
+		t.Fatalf("Expected 2 tool rounds and a final answer, got %d requests", len(model.requests))
+	}
+	second := model.requests[1]["messages"].([]interface{})
+	assistant := second[1].(map[string]interface{})
+	calls := assistant["tool_calls"].([]interface{})
+	if fn := calls[0].(map[string]interface{})["function"].(map[string]interface{}); fn["arguments"] != `{"pattern": "ExampleFunction"}` {
+		t.Errorf("Expected arguments re-encoded as a JSON string, got %#v", fn["arguments"])
+	}
+	result := second[2].(map[string]interface{})
+	if result["role"] != "tool" || result["tool_call_id"] != "call_1" || !strings.Contains(result["content"].(string), "File: cmd/context.go\n11:func ExampleFunction()") {
+		t.Errorf("Unexpected tool result message: %v", result)
+	}
+	if _, ok := model.requests[2]["tools"]; ok {
+		t.Error("Expected the final request to withdraw the tools")
+	}
+	if last := model.lastMessage(2); !strings.Contains(last["content"].(string), "used all available tool calls") {
+		t.Errorf("Expected the budget notice last, got %v", last)
+	}
+}
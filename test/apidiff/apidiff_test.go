@@ -0,0 +1,132 @@
+// test/apidiff/apidiff_test.go
+package apidiff
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/apidiff"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func writeAndCommit(t *testing.T, files map[string]string, message string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	if _, err := utils.ExecGit("add", "-A"); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	if _, err := utils.ExecGit("commit", "-q", "-m", message); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func findChange(changes []apidiff.Change, symbol string) (apidiff.Change, bool) {
+	for _, c := range changes {
+		if c.Symbol == symbol {
+			return c, true
+		}
+	}
+	return apidiff.Change{}, false
+}
+
+func TestDiffReportsBreakingChanges(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	base, err := utils.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("Failed to get branch: %v", err)
+	}
+	if _, err := utils.ExecGit("checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	writeAndCommit(t, map[string]string{
+		"cmd/context.go": `package cmd
+
+func ExampleFunction(verbose bool) error {
+	return nil
+}
+`,
+		"internal/contextpkg/context.go": `package contextpkg
+
+type Message struct {
+	Role     string
+	Content  string
+	ToolName string
+}
+
+type Task struct {
+	Description string
+	Functions   []string
+}
+`,
+		"internal/dce/dce.go": `package dce
+
+type DCE interface {
+	Activate(task string) error
+	Deactivate(conversationID string) error
+	BuildTaskList(input string) ([]contextpkg.Task, []string, error)
+	Status() string
+}
+
+type DefaultDCE struct{}
+
+func NewEngine() DCE {
+	return &DefaultDCE{}
+}
+`,
+	}, "Reshape the API")
+
+	report, err := apidiff.Diff(base, apidiff.Options{IncludeInternal: true})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if c, ok := findChange(report.Changes, "ExampleFunction"); !ok || c.Kind != apidiff.ChangeSignature ||
+		c.Old != "func()" || c.New != "func(bool) error" {
+		t.Errorf("Expected ExampleFunction signature change, got %+v", c)
+	}
+	if c, ok := findChange(report.Changes, "NewDCE"); !ok || c.Kind != apidiff.ChangeRenamed || c.New != "NewEngine" {
+		t.Errorf("Expected NewDCE to be reported as renamed to NewEngine, got %+v", c)
+	}
+	if c, ok := findChange(report.Changes, "Task"); !ok || len(c.Details) != 1 || !strings.Contains(c.Details[0], "removed field Files") {
+		t.Errorf("Expected removed field Files on Task, got %+v", c)
+	}
+	if c, ok := findChange(report.Changes, "DCE"); !ok || len(c.Details) != 1 || !strings.Contains(c.Details[0], "added method Status() string") {
+		t.Errorf("Expected added method on DCE, got %+v", c)
+	}
+	// Adding a struct field is compatible.
+	if c, ok := findChange(report.Changes, "Message"); ok {
+		t.Errorf("Did not expect Message to be reported, got %+v", c)
+	}
+
+	markdown := report.Markdown()
+	if !strings.HasPrefix(markdown, "## Breaking Changes") || !strings.Contains(markdown, "`dce.NewDCE` was renamed to `NewEngine`") {
+		t.Errorf("Unexpected markdown:\n%s", markdown)
+	}
+
+	// Internal packages are skipped unless requested.
+	report, err = apidiff.Diff(base, apidiff.Options{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Symbol != "ExampleFunction" {
+		t.Errorf("Expected only the cmd change without internal packages, got %+v", report.Changes)
+	}
+}
+
+func TestInsertSectionKeepsFence(t *testing.T) {
+	draft := "```markdown\n# Title\n\nBody\n```\n"
+	got := apidiff.InsertSection(draft, "## Breaking Changes\n\n- x\n")
+	want := "```markdown\n# Title\n\nBody\n\n## Breaking Changes\n\n- x\n```\n"
+	if got != want {
+		t.Errorf("InsertSection() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+// test/cache/cache_test.go
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/cache"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func TestStatelessResponsesAreCached(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		fmt.Fprintf(w, `{"message": {"role": "assistant", "content": "Draft %d"}, "done": true}`, n)
+	}))
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	contextpkg.SetActiveModel("fake-model")
+	defer contextpkg.SetActiveModel("")
+
+	diff := "diff --git a/a.go b/a.go\n+x\n"
+	first, err := llm.GenerateDraftPR("Add feature", diff)
+	if err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	second, err := llm.GenerateDraftPR("Add feature", diff)
+	if err != nil || second != first || hits != 1 {
+		t.Fatalf("Expected the repeat call to be served from the cache, got %q after %d requests (%v)", second, hits, err)
+	}
+
+	// A different prompt or model is a different key.
+	if _, err := llm.GenerateDraftPR("Fix bug", diff); err != nil || hits != 2 {
+		t.Fatalf("Expected a new prompt to reach the model, got %d requests (%v)", hits, err)
+	}
+	contextpkg.SetActiveModel("other-model")
+	if _, err := llm.GenerateDraftPR("Add feature", diff); err != nil || hits != 3 {
+		t.Fatalf("Expected a new model to reach the model, got %d requests (%v)", hits, err)
+	}
+	contextpkg.SetActiveModel("fake-model")
+
+	t.Setenv(cache.DisableEnv, "1")
+	if response, err := llm.GenerateDraftPR("Add feature", diff); err != nil || response == first || hits != 4 {
+		t.Fatalf("Expected the cache to be bypassed, got %q after %d requests (%v)", response, hits, err)
+	}
+	t.Setenv(cache.DisableEnv, "")
+
+	removed, err := cache.Clear()
+	if err != nil || removed != 3 {
+		t.Fatalf("Expected 3 entries to be cleared, got %d (%v)", removed, err)
+	}
+	if _, err := llm.GenerateDraftPR("Add feature", diff); err != nil || hits != 5 {
+		t.Fatalf("Expected a cleared cache to miss, got %d requests (%v)", hits, err)
+	}
+}
+
+func TestCacheRespectsSizeLimit(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(cache.MaxBytesEnv, "200")
+
+	for i := 0; i < 5; i++ {
+		if err := cache.Put(fmt.Sprintf("key%d", i), "m", fmt.Sprintf("response %d", i)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	dir, err := cache.Dir()
+	if err != nil {
+		t.Fatalf("Dir failed: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var total int64
+	for _, f := range files {
+		info, _ := os.Stat(f)
+		total += info.Size()
+	}
+	if total > 200 || len(files) == 0 {
+		t.Fatalf("Expected the cache to be pruned under 200 bytes, got %d bytes in %d files", total, len(files))
+	}
+	if _, ok := cache.Get("key4"); !ok {
+		t.Errorf("Expected the newest entry to survive pruning")
+	}
+	if _, ok := cache.Get("key0"); ok {
+		t.Errorf("Expected the oldest entry to be evicted")
+	}
+}
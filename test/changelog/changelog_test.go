@@ -0,0 +1,140 @@
+// test/changelog/changelog_test.go
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/changelog"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and records the prompts it received.
+type fakeLLMClient struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+func commit(t *testing.T, file, message string) string {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(message), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+	if _, err := utils.ExecGit("add", file); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	if _, err := utils.ExecGit("commit", "-q", "-m", message); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	sha, err := utils.GetLatestCommit()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	return sha
+}
+
+func TestClassifyAndRenderRange(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	if _, err := utils.ExecGit("tag", "v1.0.0"); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+	feat := commit(t, "a.txt", "feat(dce): add task planner")
+	commit(t, "b.txt", "fix: handle nil task list")
+	commit(t, "c.txt", "refactor!: drop legacy summary API")
+	commit(t, "d.txt", "chore: bump dependencies")
+	tweak := commit(t, "e.txt", "Tweak the server startup")
+	if _, err := utils.ExecGit("tag", "v1.1.0"); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+
+	branch, _ := utils.GetCurrentBranch()
+	draft := []contextpkg.Message{{Role: "assistant", Content: "# Faster startup\nLazily loads the project map."}}
+	if err := llm.SaveDraftContext(branch, tweak, draft); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+
+	fake := &fakeLLMClient{response: `{"entries": [{"id": "c1", "category": "feature", "summary": "Start the server faster."}]}`}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	commits, err := changelog.CollectCommits("v1.0.0..v1.1.0")
+	if err != nil {
+		t.Fatalf("CollectCommits failed: %v", err)
+	}
+	if len(commits) != 5 || commits[0].SHA != feat {
+		t.Fatalf("Expected 5 commits oldest first, got %+v", commits)
+	}
+
+	entries := llm.ClassifyCommits(commits)
+	prompts := strings.Join(fake.prompts, "\n")
+	if !strings.Contains(prompts, "Lazily loads the project map.") {
+		t.Error("Expected the stored PR draft in the LLM fallback prompt")
+	}
+	if strings.Contains(prompts, "add task planner") {
+		t.Error("Conventional commits should not be sent to the LLM")
+	}
+
+	section := changelog.Render(entries, changelog.Options{Version: "v1.1.0", Date: "2026-01-02"})
+	want := `## [v1.1.0] - 2026-01-02
+
+### Added
+
+- **dce:** add task planner (` + feat[:7] + `)
+- Start the server faster (` + tweak[:7] + `)
+
+### Changed
+
+- **BREAKING:** drop legacy summary API (`
+	if !strings.HasPrefix(section, want) {
+		t.Errorf("Unexpected changelog section:\n%s", section)
+	}
+	if !strings.Contains(section, "### Fixed\n\n- handle nil task list") {
+		t.Errorf("Expected fix entry under Fixed:\n%s", section)
+	}
+	if strings.Contains(section, "bump dependencies") {
+		t.Error("Chores should be omitted by default")
+	}
+}
+
+func TestPrependInsertsAboveNewestSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+
+	if err := changelog.Prepend(path, "## [v1.0.0] - 2026-01-01\n\n### Added\n\n- first\n"); err != nil {
+		t.Fatalf("Prepend to new file failed: %v", err)
+	}
+	if err := changelog.Prepend(path, "## [v1.1.0] - 2026-02-01\n\n### Fixed\n\n- second\n"); err != nil {
+		t.Fatalf("Prepend failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.HasPrefix(content, "# Changelog\n") {
+		t.Errorf("Expected standard header, got:\n%s", content)
+	}
+	if strings.Index(content, "## [v1.1.0]") > strings.Index(content, "## [v1.0.0]") {
+		t.Errorf("Expected newest section first, got:\n%s", content)
+	}
+
+	if err := changelog.Prepend(path, "## [v1.1.0]\n\n- again\n"); err == nil {
+		t.Error("Expected an error when the version already has a section")
+	}
+}
@@ -96,6 +96,7 @@ func TestAddCommand_WithEmptyDescription(t *testing.T) {
 
 func TestAddCommand_WhenDCEInactive(t *testing.T) {
 	// Setup - create DCE but don't activate it
+	test.IsolateTaskStore(t)
 	conversationID := contextpkg.GenerateConversationID("test")
 	littleguy := dce.NewLittleGuy(conversationID, []contextpkg.Task{})
 
@@ -0,0 +1,129 @@
+// test/dce/commit_tracker/reconcile_test.go
+package commit_tracker
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// commitExampleFunctionChange edits ExampleFunction in cmd/context.go and commits it.
+func commitExampleFunctionChange(t *testing.T) string {
+	t.Helper()
+
+	content, err := os.ReadFile("cmd/context.go")
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	updated := strings.Replace(string(content), "// Example implementation", "fmt.Println(\"retrying\")", 1)
+	if err := os.WriteFile("cmd/context.go", []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := utils.ExecGit("commit", "-am", "Add retry output"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	sha, err := utils.GetLatestCommit()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	return sha
+}
+
+func TestCollectCommitChangesFindsTouchedFunctions(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	sha := commitExampleFunctionChange(t)
+
+	changes, err := dce.CollectCommitChanges(sha)
+	if err != nil {
+		t.Fatalf("CollectCommitChanges failed: %v", err)
+	}
+
+	if len(changes.Files) != 1 || changes.Files[0] != "cmd/context.go" {
+		t.Errorf("Expected only cmd/context.go to change, got %v", changes.Files)
+	}
+	if len(changes.Functions) != 1 || changes.Functions[0] != "ExampleFunction" {
+		t.Errorf("Expected only ExampleFunction to change, got %v", changes.Functions)
+	}
+}
+
+func TestReconcileTasksClosesAndAdvancesTasks(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	sha := commitExampleFunctionChange(t)
+	changes, err := dce.CollectCommitChanges(sha)
+	if err != nil {
+		t.Fatalf("CollectCommitChanges failed: %v", err)
+	}
+
+	tasks := []contextpkg.Task{
+		{Description: "Add retries", Files: []string{"cmd/context.go"}, Functions: []string{"ExampleFunction"}},
+		{Description: "Touch both files", Files: []string{"cmd/context.go", "internal/dce/dce.go"}},
+		{Description: "Unrelated work", Files: []string{"README.md"}},
+		{Description: "Free-form task"},
+	}
+
+	open, result := dce.ReconcileTasks(tasks, changes, nil)
+
+	if len(result.Closed) != 1 || result.Closed[0].Description != "Add retries" {
+		t.Fatalf("Expected 'Add retries' to be closed, got %+v", result.Closed)
+	}
+	if result.Closed[0].Status != contextpkg.TaskStatusDone || result.Closed[0].Commits[0] != sha {
+		t.Errorf("Expected closed task to carry status and commit, got %+v", result.Closed[0])
+	}
+	if len(result.Progressed) != 1 || result.Progressed[0].Description != "Touch both files" {
+		t.Errorf("Expected 'Touch both files' to be in progress, got %+v", result.Progressed)
+	}
+	if len(open) != 3 {
+		t.Errorf("Expected 3 open tasks, got %d", len(open))
+	}
+	if want := "commit " + sha[:7] + " closed 1 task, advanced 1"; result.Summary() != want {
+		t.Errorf("Expected summary %q, got %q", want, result.Summary())
+	}
+
+	// Reconciling the same commit again must not double-count.
+	_, again := dce.ReconcileTasks(open, changes, nil)
+	if len(again.Closed) != 0 || len(again.Progressed) != 0 {
+		t.Errorf("Expected re-reconciliation to be a no-op, got %+v", again)
+	}
+}
+
+func TestReconcileStoredTasksPersistsState(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	if err := os.MkdirAll(".git/pr_buddy_db", 0750); err != nil {
+		t.Fatalf("Failed to initialize pr_buddy_db: %v", err)
+	}
+	err := dce.SaveTaskState(&dce.TaskState{
+		Tasks: []contextpkg.Task{{Description: "Add retries", Functions: []string{"ExampleFunction"}}},
+	})
+	if err != nil {
+		t.Fatalf("SaveTaskState failed: %v", err)
+	}
+
+	sha := commitExampleFunctionChange(t)
+	result, err := dce.ReconcileStoredTasks(sha, false)
+	if err != nil {
+		t.Fatalf("ReconcileStoredTasks failed: %v", err)
+	}
+	if result == nil || len(result.Closed) != 1 {
+		t.Fatalf("Expected one closed task, got %+v", result)
+	}
+
+	state, err := dce.LoadTaskState()
+	if err != nil {
+		t.Fatalf("LoadTaskState failed: %v", err)
+	}
+	if len(state.Tasks) != 0 || len(state.Completed) != 1 {
+		t.Errorf("Expected task to move to completed, got %+v", state)
+	}
+}
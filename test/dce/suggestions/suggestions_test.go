@@ -0,0 +1,156 @@
+// test/dce/suggestions/suggestions_test.go
+package suggestions
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+const retrySource = `package retry
+
+import "os"
+
+// Covered is exercised by TestCovered.
+func Covered() int {
+	return helper()
+}
+
+func helper() int {
+	return 1
+}
+
+func Uncovered(path string) {
+	_ = os.Remove(path)
+	cleanup(path)
+}
+
+func cleanup(path string) error {
+	return os.RemoveAll(path)
+}
+`
+
+const retryTestSource = `package retry
+
+import "testing"
+
+func TestCovered(t *testing.T) {
+	if Covered() != 1 {
+		t.Fatal("unexpected")
+	}
+}
+`
+
+func writeRetryPackage(t *testing.T) {
+	t.Helper()
+	if err := os.MkdirAll("internal/retry", 0755); err != nil {
+		t.Fatalf("Failed to create package dir: %v", err)
+	}
+	if err := os.WriteFile("internal/retry/retry.go", []byte(retrySource), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	if err := os.WriteFile("internal/retry/retry_test.go", []byte(retryTestSource), 0644); err != nil {
+		t.Fatalf("Failed to write test: %v", err)
+	}
+}
+
+func findSuggestion(suggestions []dce.Suggestion, kind, function string) *dce.Suggestion {
+	for i := range suggestions {
+		if suggestions[i].Kind == kind && suggestions[i].Function == function {
+			return &suggestions[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeWorkingTreeFindsGaps(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	writeRetryPackage(t)
+
+	suggestions, err := dce.AnalyzeWorkingTree()
+	if err != nil {
+		t.Fatalf("AnalyzeWorkingTree failed: %v", err)
+	}
+
+	if findSuggestion(suggestions, dce.SuggestionMissingTest, "Covered") != nil {
+		t.Error("Covered is called from TestCovered and should not need tests")
+	}
+	if findSuggestion(suggestions, dce.SuggestionMissingTest, "helper") != nil {
+		t.Error("helper is reachable from TestCovered via the call graph")
+	}
+	if findSuggestion(suggestions, dce.SuggestionMissingTest, "Uncovered") == nil {
+		t.Error("Expected a missing test suggestion for Uncovered")
+	}
+	if findSuggestion(suggestions, dce.SuggestionMissingDoc, "Uncovered") == nil {
+		t.Error("Expected a missing doc suggestion for Uncovered")
+	}
+	if findSuggestion(suggestions, dce.SuggestionMissingDoc, "Covered") != nil {
+		t.Error("Covered has a doc comment")
+	}
+
+	var ignored []string
+	for _, s := range suggestions {
+		if s.Kind == dce.SuggestionIgnoredError {
+			ignored = append(ignored, s.Message)
+		}
+	}
+	joined := strings.Join(ignored, "\n")
+	if !strings.Contains(joined, "error return of Remove") || !strings.Contains(joined, "error return of cleanup") {
+		t.Errorf("Expected ignored errors for Remove and cleanup, got: %s", joined)
+	}
+
+	// Editing an untracked file invalidates the cached analysis.
+	documented := strings.Replace(retrySource, "func Uncovered", "// Uncovered removes path.\nfunc Uncovered", 1)
+	if err := os.WriteFile("internal/retry/retry.go", []byte(documented), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	suggestions, err = dce.AnalyzeWorkingTree()
+	if err != nil {
+		t.Fatalf("AnalyzeWorkingTree failed: %v", err)
+	}
+	if findSuggestion(suggestions, dce.SuggestionMissingDoc, "Uncovered") != nil {
+		t.Error("Expected the doc suggestion for Uncovered to go once it is documented")
+	}
+}
+
+func TestCheckForQueriesDedupesAndSnoozes(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	writeRetryPackage(t)
+
+	littleguy := dce.NewLittleGuy(contextpkg.GenerateConversationID("test"), nil)
+	var messages []string
+	littleguy.SetQueryCallback(func(msg string) { messages = append(messages, msg) })
+
+	first := littleguy.CheckForQueries()
+	if len(first) == 0 || len(messages) != len(first) {
+		t.Fatalf("Expected suggestions to be surfaced via callback, got %d new and %d messages", len(first), len(messages))
+	}
+
+	if again := littleguy.CheckForQueries(); len(again) != 0 {
+		t.Errorf("Expected no new suggestions on second check, got %d", len(again))
+	}
+
+	target := first[0]
+	if !littleguy.SnoozeSuggestion(target.ID, 50*time.Millisecond) {
+		t.Fatalf("Failed to snooze %q", target.ID)
+	}
+	littleguy.CheckForQueries()
+	for _, s := range littleguy.Suggestions() {
+		if s.ID == target.ID {
+			t.Errorf("Snoozed suggestion %q is still active", target.ID)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	resurfaced := littleguy.CheckForQueries()
+	if len(resurfaced) != 1 || resurfaced[0].ID != target.ID {
+		t.Errorf("Expected only the snoozed suggestion to resurface, got %+v", resurfaced)
+	}
+}
@@ -0,0 +1,131 @@
+// test/dce/task_planner/plan_test.go
+package task_planner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and records the prompts it received.
+type fakeLLMClient struct {
+	response string
+	err      error
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, f.err
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+// useFakeClient installs fake as the planner client and turns planning on.
+func useFakeClient(t *testing.T, fake *fakeLLMClient) {
+	t.Helper()
+	t.Setenv(dce.PlannerEnv, "1")
+	llm.SetLLMClient(fake)
+	t.Cleanup(func() { llm.SetLLMClient(&llm.DefaultLLMClient{}) })
+}
+
+func TestBuildTaskListUsesLLMPlan(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	fake := &fakeLLMClient{response: "```json\n" + `{"tasks": [
+		{"description": "Add retry wrapper", "files": ["cmd/context.go", "cmd/imaginary.go"], "functions": ["ExampleFunction", "MadeUpFunc"], "notes": ["wrap the call"]},
+		{"description": "Expose config", "files": ["internal/dce/dce.go"], "functions": ["NewDCE"]}
+	]}` + "\n```\n\nBoth tasks keep the {retry} settings in one place."}
+	useFakeClient(t, fake)
+
+	tasks, logs, err := dce.BuildTaskList("add retries to the context command")
+	if err != nil {
+		t.Fatalf("BuildTaskList failed: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 planned tasks, got %d", len(tasks))
+	}
+	test.AssertTaskContains(t, tasks[0], "Add retry wrapper", []string{"cmd/context.go"}, []string{"ExampleFunction"})
+	test.AssertTaskContains(t, tasks[1], "Expose config", []string{"internal/dce/dce.go"}, []string{"NewDCE"})
+
+	joined := strings.Join(logs, "\n")
+	if !strings.Contains(joined, `Dropped unknown file "cmd/imaginary.go"`) {
+		t.Errorf("Expected hallucinated file to be dropped, logs: %s", joined)
+	}
+	if !strings.Contains(joined, `Dropped unknown function "MadeUpFunc"`) {
+		t.Errorf("Expected hallucinated function to be dropped, logs: %s", joined)
+	}
+
+	prompt := strings.Join(fake.prompts, "\n")
+	if !strings.Contains(prompt, "cmd/context.go: init, ExampleFunction") {
+		t.Errorf("Expected project map functions in prompt, got: %s", prompt)
+	}
+}
+
+func TestBuildTaskListFallsBackWhenLLMFails(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	useFakeClient(t, &fakeLLMClient{err: fmt.Errorf("connection refused")})
+
+	tasks, logs, err := dce.BuildTaskList("context package")
+	if err != nil {
+		t.Fatalf("BuildTaskList failed: %v", err)
+	}
+
+	if len(tasks) != 1 || tasks[0].Description != "context package" {
+		t.Fatalf("Expected heuristic task, got %+v", tasks)
+	}
+
+	joined := strings.Join(logs, "\n")
+	if !strings.Contains(joined, "falling back to keyword heuristic") {
+		t.Errorf("Expected fallback log, got: %s", joined)
+	}
+}
+
+func TestBuildTaskListFallsBackOnEmptyPlan(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	useFakeClient(t, &fakeLLMClient{response: `{"tasks": [{"description": "", "files": []}]}`})
+
+	tasks, _, err := dce.BuildTaskList("nonexistent feature")
+	if err != nil {
+		t.Fatalf("BuildTaskList failed: %v", err)
+	}
+
+	if len(tasks) != 1 || len(tasks[0].Notes) == 0 || !strings.Contains(tasks[0].Notes[0], "No direct file matches found") {
+		t.Errorf("Expected catch-all heuristic task, got %+v", tasks)
+	}
+}
+
+func TestBuildTaskListSkipsPlannerUnlessEnabled(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	fake := &fakeLLMClient{response: `{"tasks": [{"description": "Planned", "files": ["cmd/context.go"]}]}`}
+	useFakeClient(t, fake)
+	t.Setenv(dce.PlannerEnv, "")
+
+	tasks, _, err := dce.BuildTaskList("context package")
+	if err != nil {
+		t.Fatalf("BuildTaskList failed: %v", err)
+	}
+	if len(fake.prompts) != 0 {
+		t.Errorf("Expected no planner call, got %d prompt(s)", len(fake.prompts))
+	}
+	if len(tasks) != 1 || tasks[0].Description != "context package" {
+		t.Errorf("Expected the heuristic task, got %+v", tasks)
+	}
+}
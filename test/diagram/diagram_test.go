@@ -0,0 +1,191 @@
+// test/diagram/diagram_test.go
+package diagram
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/diagram"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// buildGraph writes a module where cmd.Run calls store.Save, which calls store.encode
+// and store.write, and returns its graph.
+func buildGraph(t *testing.T, repoPath string) *codegraph.Graph {
+	t.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.23\n",
+		"cmd/run.go": `package cmd
+
+import "example.com/demo/internal/store"
+
+func Run() {
+	store.Save("x")
+}
+`,
+		"internal/store/store.go": `package store
+
+func Save(v string) error {
+	return write(encode(v))
+}
+
+func encode(v string) []byte {
+	return []byte(v)
+}
+
+func write(data []byte) error {
+	return flush()
+}
+
+func flush() error {
+	return nil
+}
+`,
+	}
+	var paths []string
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		paths = append(paths, path)
+	}
+	functions, err := treesitter.ParseGoFiles(repoPath, paths)
+	if err != nil {
+		t.Fatalf("ParseGoFiles failed: %v", err)
+	}
+	return codegraph.Build(repoPath, paths, functions)
+}
+
+func TestPackageDiagram(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	g := buildGraph(t, repoPath)
+
+	d := diagram.Packages(g)
+	mermaid := d.Mermaid()
+	for _, want := range []string{"title: Package dependencies", "graph LR", `    pkg_cmd["cmd"]`, "    pkg_cmd -->|1 calls| pkg_internal_store"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected Mermaid output to contain %q, got:\n%s", want, mermaid)
+		}
+	}
+	if strings.Contains(mermaid, "classDef") {
+		t.Errorf("Expected no highlighted scope in a package graph, got:\n%s", mermaid)
+	}
+
+	dot := d.DOT()
+	for _, want := range []string{`digraph "Package dependencies" {`, `pkg_internal_store [label="internal/store"];`, `pkg_cmd -> pkg_internal_store [label="1 calls"];`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+}
+
+func TestCallDiagram(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	g := buildGraph(t, repoPath)
+
+	labels := func(d *diagram.Diagram) string {
+		var out []string
+		for _, n := range d.Nodes {
+			out = append(out, n.Label)
+		}
+		return strings.Join(out, " ")
+	}
+
+	// Around a function: callers and callees within the depth.
+	d, err := diagram.Calls(g, diagram.Scope{Function: "Save", Depth: 1})
+	if err != nil {
+		t.Fatalf("Calls failed: %v", err)
+	}
+	if got := labels(d); got != "Run Save encode write" {
+		t.Errorf("Unexpected nodes at depth 1: %s", got)
+	}
+	d, _ = diagram.Calls(g, diagram.Scope{Function: "Save", Depth: 2})
+	if got := labels(d); got != "Run Save encode write flush" {
+		t.Errorf("Unexpected nodes at depth 2: %s", got)
+	}
+	mermaid := d.Mermaid()
+	for _, want := range []string{"title: Calls around Save", `    subgraph pkg_internal_store["internal/store"]`, "    classDef scope stroke-width:3px"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected Mermaid output to contain %q, got:\n%s", want, mermaid)
+		}
+	}
+	dot := d.DOT()
+	if !strings.Contains(dot, `subgraph "cluster_cmd" {`) || strings.Count(dot, "penwidth=2") != 1 {
+		t.Errorf("Expected package clusters and only Save highlighted, got:\n%s", dot)
+	}
+
+	// A package at depth 0 keeps to its own functions.
+	d, err = diagram.Calls(g, diagram.Scope{Package: "cmd", Depth: 0})
+	if err != nil || labels(d) != "Run" || len(d.Edges) != 0 {
+		t.Errorf("Expected only cmd's functions, got %s, %v", labels(d), err)
+	}
+	d, _ = diagram.Calls(g, diagram.Scope{Package: "./cmd", Depth: 1})
+	if labels(d) != "Run Save" || len(d.Edges) != 1 {
+		t.Errorf("Expected cmd's direct callees, got %s", labels(d))
+	}
+
+	if _, err := diagram.Calls(g, diagram.Scope{Function: "Missing", Depth: 1}); err == nil {
+		t.Error("Expected an unknown function to fail")
+	}
+	if _, err := diagram.Calls(g, diagram.Scope{Package: "internal/missing", Depth: 1}); err == nil {
+		t.Error("Expected an unknown package to fail")
+	}
+}
+
+func TestHTMLViewer(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	g := buildGraph(t, repoPath)
+
+	page, err := diagram.HTML(g, "demo <map>", repoPath)
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	html := string(page)
+	if !strings.Contains(html, "<title>demo &lt;map&gt;</title>") {
+		t.Errorf("Expected an escaped title")
+	}
+	if strings.Contains(html, "<script src") || strings.Contains(html, "<link") || strings.Contains(html, "/*DATA*/") {
+		t.Errorf("Expected a self-contained page with the data filled in")
+	}
+
+	start := strings.Index(html, "const DATA = ")
+	end := strings.Index(html[start:], ";\n")
+	var data struct {
+		Title     string
+		Root      string
+		Functions []struct {
+			Display string
+			File    string
+			Line    int
+			Callers []int
+			Callees []int
+		}
+	}
+	if err := json.Unmarshal([]byte(html[start+len("const DATA = "):start+end]), &data); err != nil {
+		t.Fatalf("Failed to decode the embedded data: %v", err)
+	}
+	if data.Title != "demo <map>" || data.Root != filepath.ToSlash(repoPath) {
+		t.Errorf("Unexpected title or root: %q, %q", data.Title, data.Root)
+	}
+	for _, fn := range data.Functions {
+		if fn.Display != "Save" {
+			continue
+		}
+		if fn.File != "internal/store/store.go" || fn.Line != 3 || len(fn.Callers) != 1 || len(fn.Callees) != 2 {
+			t.Errorf("Unexpected viewer entry for Save: %+v", fn)
+		}
+		return
+	}
+	t.Errorf("Expected Save in the viewer data")
+}
@@ -0,0 +1,238 @@
+// test/guide/guide_test.go
+package guide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/codegraph"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/guide"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient answers with a summary naming the package from the prompt and records
+// the prompts it received.
+type fakeLLMClient struct {
+	prompts []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	prompt := messages[len(messages)-1].Content
+	f.prompts = append(f.prompts, prompt)
+	if fields := strings.Fields(strings.TrimSpace(prompt)); fields[0] == "Summarize" {
+		return "Summary of " + fields[4] + ".", nil
+	}
+	return "The demo overview.", nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+// writeDemoModule adds a small module on top of the test repository: a main package
+// calling a cobra command that wires up an HTTP handler.
+func writeDemoModule(t *testing.T) {
+	t.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.23\n",
+		"main.go": `package main
+
+import "example.com/demo/cmd"
+
+func main() {
+	cmd.Execute()
+}
+`,
+		"cmd/root.go": `package cmd
+
+import (
+	"example.com/demo/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Run the demo server",
+	Run: func(cmd *cobra.Command, args []string) {
+		api.Serve()
+	},
+}
+
+func Execute() {
+	rootCmd.Execute()
+	ExampleFunction()
+}
+`,
+		"internal/api/api.go": `package api
+
+import (
+	"net/http"
+
+	"example.com/demo/internal/dce"
+)
+
+// Server holds the routes.
+type Server struct{}
+
+func (s *Server) Start() {}
+
+func (s *Server) Stop() {}
+
+func Serve() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", pingHandler)
+	dce.NewDCE()
+}
+
+func pingHandler(w http.ResponseWriter, r *http.Request) {
+	dce.NewDCE()
+	respond(w)
+}
+
+func respond(w http.ResponseWriter) {}
+`,
+		"internal/api/api_test.go": `package api
+
+func helper() { Serve() }
+`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	writeDemoModule(t)
+
+	gd, err := guide.Analyze(repoPath)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	g := gd.Graph
+
+	var dirs []string
+	for _, pkg := range g.Packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+	if got := strings.Join(dirs, " "); got != ". cmd internal/api internal/contextpkg internal/dce" {
+		t.Fatalf("Unexpected packages: %s", got)
+	}
+	api, _ := g.Package("internal/api")
+	if strings.Join(api.Imports, " ") != "internal/dce" || strings.Join(api.External, " ") != "net/http" {
+		t.Errorf("Unexpected imports of internal/api: %v, %v", api.Imports, api.External)
+	}
+	for _, file := range api.Files {
+		if strings.HasSuffix(file, "_test.go") {
+			t.Errorf("Expected test files to be left out, got %v", api.Files)
+		}
+	}
+	if got := strings.Join(g.DependencyOrder(), " "); got != "internal/dce internal/api cmd . internal/contextpkg" {
+		t.Errorf("Expected packages after their imports, got %s", got)
+	}
+
+	var entries []string
+	for _, ep := range gd.EntryPoints {
+		entries = append(entries, fmt.Sprintf("%s %s %s %s:%d", ep.Kind, ep.Name, ep.Target, ep.File, ep.Line))
+	}
+	want := []string{
+		"main main main main.go:5",
+		"command demo rootCmd cmd/root.go:8",
+		"http /ping pingHandler internal/api/api.go:22",
+	}
+	if strings.Join(entries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected entry points:\n%s", strings.Join(entries, "\n"))
+	}
+	if gd.EntryPoints[1].Detail != "Run the demo server" {
+		t.Errorf("Expected the command's Short text, got %q", gd.EntryPoints[1].Detail)
+	}
+
+	ranked := g.MostCalled(1)
+	if len(ranked) != 1 || codegraph.DisplayName(g.Functions[ranked[0].Index]) != "NewDCE" || ranked[0].Callers != 2 {
+		t.Errorf("Expected NewDCE to be the most called function, got %+v", ranked)
+	}
+	if types := gd.KeyTypes("internal/api", 5); len(types) != 1 || types[0].Name != "Server" || types[0].Methods != 2 {
+		t.Errorf("Unexpected key types: %+v", types)
+	}
+
+	flows := g.Flows()
+	if len(flows) == 0 || flows[0].From != "internal/api" || flows[0].To != "internal/dce" || flows[0].Calls != 2 {
+		t.Fatalf("Expected internal/api -> internal/dce to be the busiest flow, got %+v", flows)
+	}
+
+	doc := gd.Markdown(guide.DefaultTop)
+	for _, want := range []string{
+		"# " + filepath.Base(repoPath) + " Guide",
+		"```mermaid\ngraph TD\n",
+		"    pkg_internal_api --> pkg_internal_dce\n",
+		"| command | `demo` | `rootCmd` | cmd/root.go:8 | Run the demo server |",
+		"### `demo` (package main)",
+		"| `Server` | struct | 2 | internal/api/api.go:10 |",
+		"| 1 | `NewDCE` | internal/dce | 2 | internal/dce/dce.go:11 |",
+		`    pkg_internal_api["internal/api"] -->|2| pkg_internal_dce["internal/dce"]`,
+		"| internal/api | internal/dce | 2 | `NewDCE` |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Expected guide to contain %q, got:\n%s", want, doc)
+		}
+	}
+}
+
+func TestSummarizeGuide(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	writeDemoModule(t)
+
+	gd, err := guide.Analyze(repoPath)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	fake := &fakeLLMClient{}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	var order []string
+	if err := llm.SummarizeGuide(gd, func(dir string) { order = append(order, dir) }); err != nil {
+		t.Fatalf("SummarizeGuide failed: %v", err)
+	}
+	if len(order) != len(gd.Graph.Packages) || len(fake.prompts) != len(order)+1 {
+		t.Fatalf("Expected one request per package and one overview, got %v and %d prompts", order, len(fake.prompts))
+	}
+	if gd.Summaries["internal/api"] != "Summary of internal/api." || gd.Overview != "The demo overview." {
+		t.Errorf("Unexpected summaries: %v, overview %q", gd.Summaries, gd.Overview)
+	}
+
+	// internal/api is summarized after internal/dce, with its summary as context.
+	var apiPrompt string
+	for i, dir := range order {
+		if dir == "internal/api" {
+			apiPrompt = fake.prompts[i]
+		}
+	}
+	for _, want := range []string{"- internal/dce: Summary of internal/dce.", "func pingHandler(w http.ResponseWriter, r *http.Request)", "http /ping -> pingHandler"} {
+		if !strings.Contains(apiPrompt, want) {
+			t.Errorf("Expected the internal/api prompt to contain %q, got:\n%s", want, apiPrompt)
+		}
+	}
+	overview := fake.prompts[len(fake.prompts)-1]
+	if !strings.Contains(overview, "### internal/api (package api)\nSummary of internal/api.") || !strings.Contains(overview, "internal/api -> internal/dce: 2 call(s) via NewDCE") {
+		t.Errorf("Expected the overview prompt to build on the package summaries, got:\n%s", overview)
+	}
+
+	doc := gd.Markdown(guide.DefaultTop)
+	if !strings.Contains(doc, "## Overview\n\nThe demo overview.") || !strings.Contains(doc, "### `internal/api` (package api)\n\nSummary of internal/api.") {
+		t.Errorf("Expected the summaries in the guide, got:\n%s", doc)
+	}
+}
@@ -0,0 +1,176 @@
+// test/health/health_test.go
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/health"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// branchy has complexity 7 (if, &&, for, two cases, else if), nests three levels deep
+// and returns from three places; the closure's return is not counted.
+const branchy = `package store
+
+func branchy(x int) error {
+	if x > 1 && x < 5 {
+		for i := 0; i < x; i++ {
+			switch i {
+			case 1:
+				return nil
+			case 2:
+			default:
+			}
+		}
+	} else if x == 0 {
+		return nil
+	} else {
+		f := func() error { return nil }
+		_ = f
+	}
+	return nil
+}
+`
+
+func writeAndCommit(t *testing.T, files map[string]string, message string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	if _, err := utils.ExecGit("add", "-A"); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	if _, err := utils.ExecGit("commit", "-q", "-m", message); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestFunctionMetrics(t *testing.T) {
+	functions, err := treesitter.ParseGoSource("store.go", []byte(branchy))
+	if err != nil || len(functions) != 1 {
+		t.Fatalf("ParseGoSource failed: %v, %d functions", err, len(functions))
+	}
+	want := treesitter.FunctionMetrics{Complexity: 7, MaxNesting: 3, ReturnPaths: 3}
+	if got := functions[0].Metrics; got != want {
+		t.Errorf("Metrics = %+v, want %+v", got, want)
+	}
+
+	flat, _ := treesitter.ParseGoSource("flat.go", []byte("package x\n\nfunc flat() {}\n"))
+	if got := flat[0].Metrics; got != (treesitter.FunctionMetrics{Complexity: 1}) {
+		t.Errorf("Expected a flat function to have complexity 1 and nothing else, got %+v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	writeAndCommit(t, map[string]string{
+		"internal/store/store.go": branchy + `
+func used() {}
+
+func handler() {}
+
+var handlers = []func(){handler}
+
+func Run() {
+	used()
+}
+`,
+		"internal/store/store_test.go": `package store
+
+func helper() {}
+`,
+	}, "Add store")
+
+	src, err := health.WorkingTree(repoPath)
+	if err != nil {
+		t.Fatalf("WorkingTree failed: %v", err)
+	}
+	report := health.Check(src, health.Thresholds{Lines: 10, Complexity: 5, Nesting: 2, ReturnPaths: 2})
+
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, f.Kind+" "+f.Function)
+	}
+	// branchy is unreferenced and over every limit; used and handler are referenced,
+	// and neither exported functions nor test files are reported as unused.
+	want := "unused branchy long branchy complex branchy nested branchy returns branchy"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Findings = %q, want %q", strings.Join(got, " "), want)
+	}
+	if f := report.Findings[1]; f.File != "internal/store/store.go" || f.Line != 3 || f.Value != 18 || f.Limit != 10 {
+		t.Errorf("Unexpected long finding: %+v", f)
+	}
+
+	// Defaults leave branchy only as unused.
+	if report := health.Check(src, health.DefaultThresholds); len(report.Findings) != 1 {
+		t.Errorf("Expected one finding with the default thresholds, got %+v", report.Findings)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	base, err := utils.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("Failed to get branch: %v", err)
+	}
+	writeAndCommit(t, map[string]string{
+		"internal/store/old.go": `package store
+
+func Old(a, b, c bool) bool {
+	if a {
+		return b
+	}
+	if b {
+		return c
+	}
+	return a && b || c && !a
+}
+`,
+	}, "Add old")
+	if _, err := utils.ExecGit("checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	delta, err := health.Diff(base, health.DefaultThresholds)
+	if err != nil || delta.HasChanges() {
+		t.Fatalf("Expected no changes without commits, got %+v, %v", delta, err)
+	}
+
+	writeAndCommit(t, map[string]string{
+		"internal/store/old.go":   "package store\n\nfunc Old() bool {\n\treturn true\n}\n",
+		"internal/store/store.go": strings.Replace(branchy, "func branchy", "func Branchy", 1),
+	}, "Add Branchy")
+
+	delta, err = health.Diff(base, health.Thresholds{Lines: 80, Complexity: 5, Nesting: 4, ReturnPaths: 6})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(delta.Added) != 1 || delta.Added[0].Function != "Branchy" || len(delta.Resolved) != 1 || delta.Resolved[0].Function != "Old" {
+		t.Fatalf("Unexpected delta: %+v", delta)
+	}
+	md := delta.Markdown()
+	for _, want := range []string{
+		"## Code Health",
+		"- This PR adds 1 function over complexity 5",
+		"- This PR removes 1 function over complexity 5",
+		"- `Branchy` (internal/store/store.go:3) has cyclomatic complexity 7 (limit 5)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+}
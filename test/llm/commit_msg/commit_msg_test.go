@@ -0,0 +1,212 @@
+// test/llm/commit_msg/commit_msg_test.go
+package commit_msg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and counts calls.
+type fakeLLMClient struct {
+	response string
+	calls    int
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	f.calls++
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+func useFakeClient(t *testing.T, fake *fakeLLMClient) {
+	t.Helper()
+	llm.SetLLMClient(fake)
+	t.Cleanup(func() { llm.SetLLMClient(&llm.DefaultLLMClient{}) })
+}
+
+func stageDCEChange(t *testing.T) {
+	t.Helper()
+	if err := os.WriteFile("internal/dce/extra.go", []byte("package dce\n\nfunc Extra() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := utils.ExecGit("add", "internal/dce/extra.go"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+}
+
+func TestInferCommitScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"single package", []string{"internal/llm/review.go", "internal/llm/llm_client.go"}, "llm"},
+		{"nested under common package", []string{"internal/dce/a.go", "internal/dce/sub/b.go"}, "dce"},
+		{"layout directory only", []string{"internal/llm/a.go", "internal/dce/b.go"}, ""},
+		{"unrelated areas", []string{"cmd/root.go", "internal/llm/a.go"}, ""},
+		{"repository root", []string{"README.md"}, ""},
+		{"command package", []string{"cmd/review.go"}, "cmd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utils.InferCommitScope(tt.files); got != tt.want {
+				t.Errorf("InferCommitScope(%v) = %q, want %q", tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTruncatesOnRuneBoundaries(t *testing.T) {
+	msg := utils.ConventionalCommit{Type: "fix", Scope: "llm", Subject: strings.Repeat("é", 100)}
+	msg.Normalize()
+
+	header := msg.Header()
+	if !utf8.ValidString(header) {
+		t.Fatalf("Header was cut inside a rune: %q", header)
+	}
+	if n := utf8.RuneCountInString(header); n != utils.MaxCommitHeaderLength {
+		t.Errorf("Expected a %d character header, got %d: %q", utils.MaxCommitHeaderLength, n, header)
+	}
+}
+
+func TestPrepareCommitMessageFileWritesConventionalMessage(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	stageDCEChange(t)
+
+	fake := &fakeLLMClient{response: `<think>hmm</think>{"type": "Feat", "subject": "Add extra hook point.", "body": "Adds Extra so callers can extend the engine.", "breaking": false}`}
+	useFakeClient(t, fake)
+
+	msgFile := repoPath + "/.git/COMMIT_EDITMSG"
+	comments := "# Please enter the commit message for your changes.\n"
+	if err := os.WriteFile(msgFile, []byte("\n"+comments), 0644); err != nil {
+		t.Fatalf("Failed to write message file: %v", err)
+	}
+
+	written, err := llm.PrepareCommitMessageFile(msgFile, "")
+	if err != nil || !written {
+		t.Fatalf("Expected message to be written, got written=%v err=%v", written, err)
+	}
+
+	data, _ := os.ReadFile(msgFile)
+	want := "feat(dce): add extra hook point\n\nAdds Extra so callers can extend the engine.\n\n" + comments
+	if string(data) != want {
+		t.Errorf("Unexpected message file:\n%q\nwant:\n%q", data, want)
+	}
+	prompt := strings.Join(fake.prompts, "\n")
+	if !strings.Contains(prompt, `The scope is "dce"`) {
+		t.Error("Expected the inferred scope in the prompt")
+	}
+	if !strings.Contains(prompt, "fits in 72 characters") {
+		t.Error("Expected the prompt to use the header length limit")
+	}
+}
+
+func TestPrepareCommitMessageFileSkipsSuppliedMessages(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	stageDCEChange(t)
+
+	fake := &fakeLLMClient{response: `{"type": "fix", "subject": "x"}`}
+	useFakeClient(t, fake)
+
+	msgFile := repoPath + "/.git/COMMIT_EDITMSG"
+	cases := []struct {
+		source   string
+		existing string
+	}{
+		{"message", "wip\n"},
+		{"merge", "Merge branch 'x'\n"},
+		{"commit", "# amend\n"},
+		{"template", "chore: my template\n# comment\n"},
+	}
+	for _, c := range cases {
+		if err := os.WriteFile(msgFile, []byte(c.existing), 0644); err != nil {
+			t.Fatalf("Failed to write message file: %v", err)
+		}
+		written, err := llm.PrepareCommitMessageFile(msgFile, c.source)
+		if err != nil || written {
+			t.Errorf("source %q: expected skip, got written=%v err=%v", c.source, written, err)
+		}
+		if data, _ := os.ReadFile(msgFile); string(data) != c.existing {
+			t.Errorf("source %q: message file was modified", c.source)
+		}
+	}
+	if fake.calls != 0 {
+		t.Errorf("Expected no LLM calls, got %d", fake.calls)
+	}
+}
+
+func TestShouldPrepareCommitMessageSeesMergesInWorktrees(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	worktree := t.TempDir() + "/wt"
+	if _, err := utils.ExecGit("worktree", "add", "-q", "-b", "side", worktree); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+	if err := os.Chdir(worktree); err != nil {
+		t.Fatalf("Failed to enter worktree: %v", err)
+	}
+	defer os.Chdir(repoPath)
+
+	if !llm.ShouldPrepareCommitMessage("", "") {
+		t.Fatal("Expected a message to be drafted outside a merge")
+	}
+	head, _ := utils.GetLatestCommit()
+	mergeHead, err := utils.GetGitPath("MERGE_HEAD")
+	if err != nil {
+		t.Fatalf("GetGitPath failed: %v", err)
+	}
+	if err := os.WriteFile(mergeHead, []byte(head+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write MERGE_HEAD: %v", err)
+	}
+	if llm.ShouldPrepareCommitMessage("", "") {
+		t.Error("Expected the merge in the worktree to be left alone")
+	}
+}
+
+func TestShouldPrepareCommitMessageForVerboseCommits(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	// `git commit -v` appends the diff below the scissors line.
+	verbose := strings.Join([]string{
+		"",
+		"# Please enter the commit message for your changes.",
+		"# ------------------------ >8 ------------------------",
+		"# Do not modify or remove the line above.",
+		"# Everything below it will be ignored.",
+		"diff --git a/cmd/context.go b/cmd/context.go",
+		"+// Extra is a new hook point.",
+		"",
+	}, "\n")
+	if !llm.ShouldPrepareCommitMessage("", verbose) {
+		t.Error("Expected a message to be drafted above the scissors line")
+	}
+	if llm.ShouldPrepareCommitMessage("", "wip\n"+verbose) {
+		t.Error("Expected a message typed above the scissors line to be kept")
+	}
+
+	if _, err := utils.ExecGit("config", "core.commentChar", ";"); err != nil {
+		t.Fatalf("Failed to set core.commentChar: %v", err)
+	}
+	if !llm.ShouldPrepareCommitMessage("", strings.ReplaceAll(verbose, "\n# ", "\n; ")) {
+		t.Error("Expected core.commentChar to mark comment lines")
+	}
+}
@@ -0,0 +1,166 @@
+// test/llm/test_gen/test_gen_test.go
+package test_gen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+const mathSource = `package mathx
+
+// Clamp limits v to the range [lo, hi].
+func Clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+`
+
+const brokenTest = "```go\npackage mathx\n\nimport \"testing\"\n\nfunc TestClamp(t *testing.T) {\n\tClamp(1)\n}\n```"
+
+const fixedTest = "Here you go:\n```go\npackage mathx\n\nimport \"testing\"\n\nfunc TestClamp(t *testing.T) {\n\tif Clamp(5, 0, 3) != 3 {\n\t\tt.Fatal(\"expected 3\")\n\t}\n}\n```"
+
+// scriptedLLMClient replays responses in order and records every conversation it saw.
+type scriptedLLMClient struct {
+	responses     []string
+	conversations [][]contextpkg.Message
+}
+
+func (s *scriptedLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	s.conversations = append(s.conversations, messages)
+	if len(s.responses) == 0 {
+		return "", fmt.Errorf("no scripted response left")
+	}
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+
+func (s *scriptedLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by scripted client")
+}
+
+func setup(t *testing.T, client *scriptedLLMClient) {
+	t.Helper()
+	repoPath := test.SetupTestRepository(t)
+	t.Cleanup(func() { test.CleanupTestRepository(t, repoPath) })
+
+	if err := os.MkdirAll("internal/mathx", 0755); err != nil {
+		t.Fatalf("Failed to create package dir: %v", err)
+	}
+	if err := os.WriteFile("internal/mathx/mathx.go", []byte(mathSource), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	llm.SetLLMClient(client)
+	t.Cleanup(func() { llm.SetLLMClient(&llm.DefaultLLMClient{}) })
+}
+
+// compileCheckRunner fails when the written test calls Clamp with the wrong arity.
+func compileCheckRunner(runs *int) llm.TestRunner {
+	return func(pkgDir, pattern string) (string, error) {
+		*runs++
+		data, err := os.ReadFile(pkgDir + "/mathx_test.go")
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(string(data), "Clamp(1)") {
+			return "./mathx_test.go:6:8: not enough arguments in call to Clamp", fmt.Errorf("exit status 1")
+		}
+		return "ok  \tmathx\t0.001s", nil
+	}
+}
+
+func TestGenerateTestsRepairsFailingDraft(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{brokenTest, fixedTest}}
+	setup(t, client)
+
+	runs := 0
+	result, err := llm.GenerateTests("Clamp", llm.TestGenOptions{
+		MaxIterations: llm.DefaultTestGenIterations,
+		Runner:        compileCheckRunner(&runs),
+	})
+	if err != nil {
+		t.Fatalf("GenerateTests failed: %v", err)
+	}
+
+	if result.File != "internal/mathx/mathx_test.go" {
+		t.Errorf("Unexpected test file %q", result.File)
+	}
+	if result.Iterations != 2 || runs != 2 {
+		t.Errorf("Expected 2 attempts and 2 runs, got %d and %d", result.Iterations, runs)
+	}
+	if len(result.Tests) != 1 || result.Tests[0] != "TestClamp" {
+		t.Errorf("Unexpected tests %v", result.Tests)
+	}
+
+	data, err := os.ReadFile("internal/mathx/mathx_test.go")
+	if err != nil || !strings.Contains(string(data), "Clamp(5, 0, 3)") {
+		t.Errorf("Expected repaired test on disk, got %q (err %v)", data, err)
+	}
+
+	first := client.conversations[0][1].Content
+	if !strings.Contains(first, "func Clamp(v, lo, hi int) int") || !strings.Contains(first, "package mathx") {
+		t.Errorf("Prompt is missing the signature or package:\n%s", first)
+	}
+	repair := client.conversations[1]
+	if !strings.Contains(repair[len(repair)-1].Content, "not enough arguments in call to Clamp") {
+		t.Error("Expected the compiler output to be fed back to the model")
+	}
+}
+
+func TestGenerateTestsRemovesFileAfterBoundedAttempts(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{brokenTest, brokenTest}}
+	setup(t, client)
+
+	runs := 0
+	_, err := llm.GenerateTests("internal/mathx/mathx.go:Clamp", llm.TestGenOptions{
+		MaxIterations: 1,
+		Runner:        compileCheckRunner(&runs),
+	})
+	if err == nil || !strings.Contains(err.Error(), "still failing after 2 attempts") {
+		t.Fatalf("Expected bounded failure, got %v", err)
+	}
+	if _, statErr := os.Stat("internal/mathx/mathx_test.go"); !os.IsNotExist(statErr) {
+		t.Error("Expected the failing test file to be removed")
+	}
+}
+
+func TestGenerateTestsWithoutRepairs(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{brokenTest, fixedTest}}
+	setup(t, client)
+
+	runs := 0
+	_, err := llm.GenerateTests("Clamp", llm.TestGenOptions{Runner: compileCheckRunner(&runs)})
+	if err == nil || runs != 1 || len(client.conversations) != 1 {
+		t.Errorf("Expected a single attempt with zero iterations, got %d run(s), %v", runs, err)
+	}
+}
+
+func TestGenerateTestsConfinesOutputPath(t *testing.T) {
+	setup(t, &scriptedLLMClient{responses: []string{fixedTest}})
+
+	for _, out := range []string{"../mathx_test.go", "/tmp/mathx_test.go", ".git/hooks/mathx_test.go", "internal/mathx/notes.txt"} {
+		if _, err := llm.GenerateTests("Clamp", llm.TestGenOptions{OutputPath: out}); err == nil {
+			t.Errorf("Expected --out %s to be refused", out)
+		}
+	}
+}
+
+func TestGenerateTestsRejectsUnknownFunction(t *testing.T) {
+	setup(t, &scriptedLLMClient{})
+
+	if _, err := llm.GenerateTests("Missing", llm.TestGenOptions{}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+// test/lsp/lsp_test.go
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/lsp"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and records the prompts it received.
+type fakeLLMClient struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+type rpcMessage struct {
+	ID     *int            `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// client talks to an in-process server over pipes, as an editor would over stdio.
+type client struct {
+	t             *testing.T
+	in            io.WriteCloser
+	messages      chan rpcMessage
+	notifications []rpcMessage
+	requests      []rpcMessage // Requests from the server, answered as applied
+	nextID        int
+	done          chan error
+}
+
+func startClient(t *testing.T) *client {
+	t.Helper()
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	c := &client{t: t, in: clientOut, messages: make(chan rpcMessage, 16), done: make(chan error, 1)}
+	go func() {
+		c.done <- lsp.NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+	go func() {
+		r := bufio.NewReader(clientIn)
+		for {
+			msg, err := readFrame(r)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	return c
+}
+
+func readFrame(r *bufio.Reader) (rpcMessage, error) {
+	var msg rpcMessage
+	length := 0
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return msg, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			length, _ = strconv.Atoi(value)
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return msg, err
+	}
+	return msg, json.Unmarshal(body, &msg)
+}
+
+func (c *client) send(id *int, method string, params interface{}) {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = *id
+	}
+	body, _ := json.Marshal(msg)
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("Failed to send %s: %v", method, err)
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(nil, method, params)
+}
+
+// call sends a request and waits for its response, keeping notifications and
+// answering server requests that arrive meanwhile.
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.t.Helper()
+	c.nextID++
+	id := c.nextID
+	c.send(&id, method, params)
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				c.t.Fatalf("Server closed the stream while waiting for %s", method)
+			}
+			if msg.ID != nil && msg.Method != "" {
+				c.requests = append(c.requests, msg)
+				c.respond(*msg.ID, map[string]bool{"applied": true})
+				continue
+			}
+			if msg.ID == nil || *msg.ID != id {
+				c.notifications = append(c.notifications, msg)
+				continue
+			}
+			if msg.Error != nil {
+				c.t.Fatalf("%s failed: %d %s", method, msg.Error.Code, msg.Error.Message)
+			}
+			if result != nil {
+				if err := json.Unmarshal(msg.Result, result); err != nil {
+					c.t.Fatalf("Failed to decode %s result: %v", method, err)
+				}
+			}
+			return
+		case <-time.After(10 * time.Second):
+			c.t.Fatalf("Timed out waiting for %s", method)
+		}
+	}
+}
+
+func (c *client) respond(id int, result interface{}) {
+	c.t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("Failed to answer request %d: %v", id, err)
+	}
+}
+
+func (c *client) close() {
+	c.t.Helper()
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("Server exited with error: %v", err)
+	}
+}
+
+func initialize(t *testing.T, repoPath string) *client {
+	t.Helper()
+	c := startClient(t)
+	var result struct {
+		Capabilities struct {
+			HoverProvider          bool `json:"hoverProvider"`
+			ExecuteCommandProvider struct {
+				Commands []string `json:"commands"`
+			} `json:"executeCommandProvider"`
+		} `json:"capabilities"`
+	}
+	c.call("initialize", map[string]interface{}{
+		"rootUri": "file://" + repoPath,
+		"capabilities": map[string]interface{}{"workspace": map[string]interface{}{
+			"applyEdit":     true,
+			"workspaceEdit": map[string]interface{}{"documentChanges": true, "resourceOperations": []string{"create"}},
+		}},
+	}, &result)
+	if !result.Capabilities.HoverProvider || len(result.Capabilities.ExecuteCommandProvider.Commands) != 4 {
+		t.Fatalf("Unexpected capabilities: %+v", result.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+	return c
+}
+
+func TestHoverAndCodeActions(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	if err := os.WriteFile("cmd/run.go", []byte("package cmd\n\nfunc Run() {\n\tExampleFunction()\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	c := initialize(t, repoPath)
+	defer c.close()
+	uri := "file://" + repoPath + "/cmd/context.go"
+
+	var hover struct {
+		Contents struct {
+			Value string `json:"value"`
+		} `json:"contents"`
+	}
+	c.call("textDocument/hover", map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": 10, "character": 8},
+	}, &hover)
+	for _, want := range []string{"func ExampleFunction()", "`cmd/context.go:11`", "**Called by:** Run (cmd/run.go:3)"} {
+		if !strings.Contains(hover.Contents.Value, want) {
+			t.Errorf("Expected hover to contain %q, got:\n%s", want, hover.Contents.Value)
+		}
+	}
+
+	// Unsaved edits are reflected in the project map.
+	c.notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{
+		"uri": "file://" + repoPath + "/cmd/run.go", "languageId": "go", "version": 1,
+		"text": "package cmd\n\nfunc Run() {\n}\n",
+	}})
+	c.call("textDocument/hover", map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": 10, "character": 8},
+	}, &hover)
+	if strings.Contains(hover.Contents.Value, "Called by") {
+		t.Errorf("Expected the edited buffer to drop the caller, got:\n%s", hover.Contents.Value)
+	}
+
+	// Characters are UTF-16 code units: the emoji takes two, the accented letter one.
+	notesURI := "file://" + repoPath + "/cmd/notes.go"
+	c.notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{
+		"uri": notesURI, "languageId": "go", "version": 1,
+		"text": "package cmd\n\n// 😀é ExampleFunction\n",
+	}})
+	var wide struct {
+		Contents struct {
+			Value string `json:"value"`
+		} `json:"contents"`
+		Range lsp.Range `json:"range"`
+	}
+	c.call("textDocument/hover", map[string]interface{}{
+		"textDocument": map[string]string{"uri": notesURI},
+		"position":     map[string]int{"line": 2, "character": 7},
+	}, &wide)
+	if !strings.Contains(wide.Contents.Value, "func ExampleFunction()") || wide.Range.Start.Character != 7 || wide.Range.End.Character != 22 {
+		t.Errorf("Expected ExampleFunction at characters 7-22, got %+v", wide)
+	}
+
+	tests := []struct {
+		name string
+		file string
+		rng  [4]int
+		want []string
+	}{
+		{"cursor in function", "cmd/context.go", [4]int{10, 0, 10, 0}, []string{
+			"PRBuddy: Explain ExampleFunction", "PRBuddy: Generate test for ExampleFunction", "PRBuddy: Draft PR from last commit"}},
+		{"selection in function", "cmd/context.go", [4]int{10, 0, 12, 1}, []string{
+			"PRBuddy: Explain ExampleFunction", "PRBuddy: Generate test for ExampleFunction", "PRBuddy: Review selection", "PRBuddy: Draft PR from last commit"}},
+		{"outside functions", "cmd/context.go", [4]int{0, 0, 0, 0}, []string{"PRBuddy: Draft PR from last commit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions []struct {
+				Title string `json:"title"`
+			}
+			c.call("textDocument/codeAction", map[string]interface{}{
+				"textDocument": map[string]string{"uri": "file://" + repoPath + "/" + tt.file},
+				"range": map[string]interface{}{
+					"start": map[string]int{"line": tt.rng[0], "character": tt.rng[1]},
+					"end":   map[string]int{"line": tt.rng[2], "character": tt.rng[3]},
+				},
+			}, &actions)
+			var titles []string
+			for _, a := range actions {
+				titles = append(titles, a.Title)
+			}
+			if strings.Join(titles, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Expected actions %q, got %q", tt.want, titles)
+			}
+		})
+	}
+}
+
+func TestCommandsUseTheLLM(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	fake := &fakeLLMClient{response: "ExampleFunction is a placeholder."}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	c := initialize(t, repoPath)
+	defer c.close()
+	uri := "file://" + repoPath + "/cmd/context.go"
+
+	var explanation string
+	c.call("workspace/executeCommand", map[string]interface{}{
+		"command":   lsp.CommandExplainFunction,
+		"arguments": []interface{}{uri, 10},
+	}, &explanation)
+	if explanation != fake.response {
+		t.Errorf("Expected the LLM explanation, got %q", explanation)
+	}
+	if !strings.Contains(strings.Join(fake.prompts, "\n"), "func ExampleFunction() {\n\t// Example implementation\n}") {
+		t.Error("Expected the prompt to contain the function source")
+	}
+
+	fake.response = `{"findings": [
+		{"file": "cmd/context.go", "start_line": 12, "end_line": 12, "severity": "warning", "category": "Maintainability", "message": "Empty function", "suggestion": "implement it"},
+		{"file": "cmd/context.go", "start_line": 2, "end_line": 2, "severity": "error", "category": "bug", "message": "outside the selection"}
+	]}`
+	c.call("workspace/executeCommand", map[string]interface{}{
+		"command": lsp.CommandReviewSelection,
+		"arguments": []interface{}{uri, map[string]interface{}{
+			"start": map[string]int{"line": 10, "character": 0},
+			"end":   map[string]int{"line": 13, "character": 0},
+		}},
+	}, nil)
+
+	var published *lsp.PublishDiagnosticsParams
+	for _, n := range c.notifications {
+		if n.Method == "textDocument/publishDiagnostics" {
+			published = &lsp.PublishDiagnosticsParams{}
+			if err := json.Unmarshal(n.Params, published); err != nil {
+				t.Fatalf("Failed to decode diagnostics: %v", err)
+			}
+		}
+	}
+	if published == nil {
+		t.Fatal("Expected diagnostics to be published")
+	}
+	if published.URI != uri || len(published.Diagnostics) != 1 {
+		t.Fatalf("Expected one diagnostic for %s, got %+v", uri, published)
+	}
+	d := published.Diagnostics[0]
+	if d.Range.Start.Line != 11 || d.Severity != lsp.SeverityWarning || d.Code != "maintainability" || d.Source != "prbuddy" {
+		t.Errorf("Unexpected diagnostic: %+v", d)
+	}
+	if !strings.Contains(d.Message, "Empty function") || !strings.Contains(d.Message, "implement it") {
+		t.Errorf("Expected message and suggestion, got %q", d.Message)
+	}
+}
+
+func TestGenerateTestIsAppliedByTheEditor(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	if err := os.WriteFile("go.mod", []byte("module example.com/fixture\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	fake := &fakeLLMClient{response: "```go\npackage cmd\n\nimport \"testing\"\n\nfunc TestExampleFunction(t *testing.T) {\n\tExampleFunction()\n}\n```"}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	c := initialize(t, repoPath)
+	defer c.close()
+
+	var summary string
+	c.call("workspace/executeCommand", map[string]interface{}{
+		"command":   lsp.CommandGenerateTest,
+		"arguments": []interface{}{"file://" + repoPath + "/cmd/context.go", 10},
+	}, &summary)
+	if !strings.Contains(summary, "cmd/context_test.go (TestExampleFunction)") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+
+	if len(c.requests) != 1 || c.requests[0].Method != "workspace/applyEdit" {
+		t.Fatalf("Expected one workspace/applyEdit request, got %+v", c.requests)
+	}
+	var params struct {
+		Edit struct {
+			DocumentChanges []struct {
+				Kind  string `json:"kind"`
+				URI   string `json:"uri"`
+				Edits []struct {
+					NewText string `json:"newText"`
+				} `json:"edits"`
+			} `json:"documentChanges"`
+		} `json:"edit"`
+	}
+	if err := json.Unmarshal(c.requests[0].Params, &params); err != nil {
+		t.Fatalf("Failed to decode applyEdit params: %v", err)
+	}
+	changes := params.Edit.DocumentChanges
+	if len(changes) != 2 || changes[0].Kind != "create" || !strings.HasSuffix(changes[0].URI, "/cmd/context_test.go") {
+		t.Fatalf("Expected the test file to be created, got %+v", changes)
+	}
+	if len(changes[1].Edits) != 1 || !strings.Contains(changes[1].Edits[0].NewText, "func TestExampleFunction") {
+		t.Errorf("Expected the generated test as the file's text, got %+v", changes[1])
+	}
+	if _, err := os.Stat("cmd/context_test.go"); !os.IsNotExist(err) {
+		t.Error("Expected the server to leave creating the file to the editor")
+	}
+}
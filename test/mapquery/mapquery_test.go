@@ -0,0 +1,223 @@
+// test/mapquery/mapquery_test.go
+package mapquery
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/mapquery"
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// demoSources is a module where Run calls store.Save, which calls encode and write;
+// Load is never called and only Save, Load and write return an error. Run also calls
+// strings.Split, which must not count as a call to text.Split.
+var demoSources = map[string]string{
+	"go.mod": "module example.com/demo\n",
+	"main.go": `package main
+
+import "example.com/demo/cmd"
+
+func main() {
+	cmd.Run()
+}
+`,
+	"cmd/run.go": `package cmd
+
+import (
+	"strings"
+
+	"example.com/demo/internal/store"
+)
+
+func Run() {
+	var s store.Store
+	s.Save()
+	_ = strings.Split("a,b", ",")
+}
+`,
+	"cmd/run_test.go": `package cmd
+
+import "testing"
+
+func TestRun(t *testing.T) {
+	Run()
+}
+`,
+	"internal/store/store.go": `package store
+
+type Store struct{}
+
+func (s *Store) Save() error {
+	b := encode()
+	return write(b)
+}
+
+func encode() []byte {
+	return nil
+}
+
+func write(b []byte) error {
+	return nil
+}
+`,
+	"internal/store/load.go": `package store
+
+func (s *Store) Load() ([]byte, error) {
+	return encode(), nil
+}
+`,
+	"internal/text/text.go": `package text
+
+func Split(s string) []string {
+	return []string{s}
+}
+`,
+}
+
+// demoFunctions writes demoSources to a directory and parses its functions.
+func demoFunctions(t *testing.T) (string, []treesitter.FunctionInfo) {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for file, content := range demoSources {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		files = append(files, file)
+	}
+	functions, err := treesitter.ParseGoFiles(dir, files)
+	if err != nil {
+		t.Fatalf("ParseGoFiles failed: %v", err)
+	}
+	return dir, functions
+}
+
+func names(results []mapquery.Result) string {
+	var out []string
+	for _, r := range results {
+		if r.Receiver != "" {
+			out = append(out, r.Receiver+"."+r.Name)
+		} else {
+			out = append(out, r.Name)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestQuery(t *testing.T) {
+	repoPath, functions := demoFunctions(t)
+	ix := mapquery.New(repoPath, functions)
+
+	cases := []struct {
+		filter mapquery.Filter
+		want   string
+	}{
+		{mapquery.Filter{}, "Run TestRun Store.Load Store.Save encode write Split main"},
+		{mapquery.Filter{Func: "Save"}, "Store.Save"},
+		{mapquery.Filter{Func: "Store.*"}, "Store.Load Store.Save"},
+		{mapquery.Filter{File: "internal/store"}, "Store.Load Store.Save encode write"},
+		{mapquery.Filter{File: "./internal/store/store.go"}, "Store.Save encode write"},
+		{mapquery.Filter{File: "cmd/*_test.go"}, "TestRun"},
+		{mapquery.Filter{Calls: "encode"}, "Store.Load Store.Save"},
+		{mapquery.Filter{Calls: "Store.Save"}, "Run"},
+		{mapquery.Filter{Calls: "Run"}, "TestRun main"},
+		{mapquery.Filter{Calls: "Split"}, ""},
+		{mapquery.Filter{CalledBy: "Save"}, "encode write"},
+		{mapquery.Filter{Returns: "error"}, "Store.Load Store.Save write"},
+		{mapquery.Filter{Returns: "error", File: "internal/store/store.go"}, "Store.Save write"},
+		{mapquery.Filter{Unused: true}, "Store.Load Split"},
+		{mapquery.Filter{Calls: "Missing"}, ""},
+	}
+	for _, c := range cases {
+		if got := names(ix.Query(c.filter)); got != c.want {
+			t.Errorf("Query(%+v) = %q, want %q", c.filter, got, c.want)
+		}
+	}
+
+	save := ix.Query(mapquery.Filter{Func: "Save"})[0]
+	if save.File != "internal/store/store.go" {
+		t.Errorf("Expected repo-relative paths, got %s", save.File)
+	}
+	if strings.Join(save.Callers, " ") != "Run" || strings.Join(save.Callees, " ") != "encode write" {
+		t.Errorf("Unexpected callers %v or callees %v", save.Callers, save.Callees)
+	}
+	if callers := ix.Callers(save.FunctionInfo); len(callers) != 1 || callers[0].Name != "Run" {
+		t.Errorf("Unexpected Callers result: %+v", callers)
+	}
+	mapped := save.FunctionInfo
+	mapped.File = "/demo/" + mapped.File
+	if callees := ix.Callees(mapped); len(callees) != 2 {
+		t.Errorf("Expected Callees to accept map paths, got %+v", callees)
+	}
+}
+
+func TestLoadProjectMap(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	if _, _, err := treesitter.LoadProjectMap("main"); !errors.Is(err, treesitter.ErrNoProjectMap) {
+		t.Fatalf("Expected ErrNoProjectMap before any map is saved, got %v", err)
+	}
+
+	// An older map of the branch and a map of another branch are both ignored.
+	dir := filepath.Join(".git", "pr_buddy_db", "scaffold")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create scaffold directory: %v", err)
+	}
+	old := filepath.Join(dir, "project_map-main-01-01.json")
+	if err := os.WriteFile(old, []byte(`{"functions":[{"name":"Old"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write old map: %v", err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatalf("Failed to age old map: %v", err)
+	}
+	other := &treesitter.ProjectMap{Functions: []treesitter.FunctionInfo{{Name: "Other"}}}
+	if err := treesitter.SaveProjectMap(other, "main-feature"); err != nil {
+		t.Fatalf("SaveProjectMap failed: %v", err)
+	}
+
+	current := &treesitter.ProjectMap{Functions: []treesitter.FunctionInfo{{Name: "Current"}}}
+	if err := treesitter.SaveProjectMap(current, "main"); err != nil {
+		t.Fatalf("SaveProjectMap failed: %v", err)
+	}
+	loaded, path, err := treesitter.LoadProjectMap("main")
+	if err != nil {
+		t.Fatalf("LoadProjectMap failed: %v", err)
+	}
+	if len(loaded.Functions) != 1 || loaded.Functions[0].Name != "Current" {
+		t.Errorf("Expected the latest map, got %+v from %s", loaded.Functions, path)
+	}
+	if !strings.HasPrefix(filepath.Base(path), "project_map-main-") || path == old {
+		t.Errorf("Unexpected map path %s", path)
+	}
+
+	// The map is found from a subdirectory too.
+	if err := os.Chdir("cmd"); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	if loaded, _, err := treesitter.LoadProjectMap("main"); err != nil || loaded.Functions[0].Name != "Current" {
+		t.Errorf("Expected the latest map from a subdirectory, got %+v, %v", loaded, err)
+	}
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	// Branch names with slashes are saved in subdirectories.
+	if err := treesitter.SaveProjectMap(other, "feature/x"); err != nil {
+		t.Fatalf("SaveProjectMap failed: %v", err)
+	}
+	if loaded, _, err := treesitter.LoadProjectMap("feature/x"); err != nil || loaded.Functions[0].Name != "Other" {
+		t.Errorf("Expected the feature/x map, got %+v, %v", loaded, err)
+	}
+}
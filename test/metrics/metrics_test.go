@@ -0,0 +1,102 @@
+// test/metrics/metrics_test.go
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/audit"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/metrics"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeOllama answers /api/chat like Ollama, including the final statistics.
+func fakeOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	stats := `"prompt_eval_count": 100, "prompt_eval_duration": 500000000, "eval_count": 20, "eval_duration": 1000000000, "load_duration": 2000000`
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Bad request body: %v", err)
+		}
+		if !req.Stream {
+			fmt.Fprintf(w, `{"message": {"role": "assistant", "content": "Draft"}, "done": true, %s}`, stats)
+			return
+		}
+		fmt.Fprintln(w, `{"message": {"role": "assistant", "content": "Hel"}, "done": false}`)
+		fmt.Fprintln(w, `{"message": {"role": "assistant", "content": "lo"}, "done": false}`)
+		fmt.Fprintf(w, "{\"message\": {\"role\": \"assistant\", \"content\": \"\"}, \"done\": true, %s}\n", stats)
+	}))
+}
+
+func TestCallsAreMeasuredPerCommandAndModel(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	server := fakeOllama(t)
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	t.Setenv("PRBUDDY_NO_CACHE", "1") // Every call must reach the fake server
+	t.Setenv(llm.NoToolsEnv, "1")     // Quickassist streams instead of calling tools
+	contextpkg.SetActiveModel("fake-model")
+	defer contextpkg.SetActiveModel("")
+
+	audit.SetCommand("post-commit")
+	if _, err := llm.GenerateDraftPR("Add feature", "diff --git a/a.go b/a.go\n+x\n"); err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	audit.SetCommand("quickassist")
+	reply, err := llm.HandleQuickAssist("", "hi")
+	if err != nil || reply != "Hello" {
+		t.Fatalf("HandleQuickAssist = %q, %v", reply, err)
+	}
+	audit.SetCommand("prbuddy-go")
+
+	snapshot := metrics.Default.Snapshot()
+	model := snapshot.ByModel["fake-model"]
+	if model.Calls != 2 || model.PromptTokens != 200 || model.CompletionTokens != 40 {
+		t.Errorf("Unexpected per-model aggregate: %+v", model)
+	}
+	if model.EvalDuration != 2*time.Second || model.TokensPerSecond() != 20 {
+		t.Errorf("Expected 20 tok/s over 2s of generation, got %.1f over %v", model.TokensPerSecond(), model.EvalDuration)
+	}
+	if snapshot.ByCommand["post-commit"].Calls != 1 || snapshot.ByCommand["quickassist"].Calls != 1 {
+		t.Errorf("Unexpected per-command aggregates: %+v", snapshot.ByCommand)
+	}
+
+	var out bytes.Buffer
+	metrics.WritePrometheus(&out, snapshot)
+	for _, line := range []string{
+		"# TYPE prbuddy_llm_calls_total counter",
+		`prbuddy_llm_completion_tokens_total{command="quickassist",model="fake-model"} 20`,
+		`prbuddy_llm_eval_seconds_total{command="post-commit",model="fake-model"} 1`,
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Prometheus output missing %q:\n%s", line, out.String())
+		}
+	}
+
+	// The audit log keeps the timings so `stats` can aggregate across processes.
+	entries, err := audit.List()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d (%v)", len(entries), err)
+	}
+	for _, e := range entries {
+		if e.Model != "fake-model" || e.EvalMS != 1000 || e.PromptEvalMS != 500 || e.CompletionTokens != 20 {
+			t.Errorf("Unexpected audit entry: %+v", e)
+		}
+	}
+	if !entries[1].Stream {
+		t.Errorf("Expected the quickassist call to be recorded as streaming")
+	}
+}
@@ -0,0 +1,167 @@
+// test/models/models_test.go
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/models"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeOllama lists two installed models, the newest second, and records which model
+// each chat request asked for. While offline it has no models and pulls fail.
+type fakeOllama struct {
+	mu        sync.Mutex
+	requested []string
+	loads     int
+	offline   bool
+}
+
+func (f *fakeOllama) lastModel() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requested[len(f.requested)-1]
+}
+
+func (f *fakeOllama) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	offline := f.offline
+	f.mu.Unlock()
+	switch {
+	case offline && r.URL.Path == "/api/tags":
+		fmt.Fprint(w, `{"models": []}`)
+		return
+	case offline && r.URL.Path == "/api/pull":
+		http.Error(w, "registry unreachable", http.StatusBadGateway)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/tags":
+		fmt.Fprint(w, `{"models": [
+			{"name": "older:latest", "size": 1000, "modified_at": "2024-01-01T00:00:00Z"},
+			{"name": "newer:latest", "size": 2000, "modified_at": "2024-06-01T00:00:00Z"}]}`)
+	case "/api/chat":
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.requested = append(f.requested, req.Model)
+		f.mu.Unlock()
+		fmt.Fprint(w, `{"message": {"role": "assistant", "content": "{\"type\": \"feat\", \"subject\": \"add x\", \"body\": \"\", \"breaking\": false}"}, "done": true}`)
+	case "/api/pull":
+		fmt.Fprintln(w, `{"status": "pulling manifest"}`)
+		fmt.Fprintln(w, `{"status": "downloading", "digest": "sha256:1", "total": 10, "completed": 10}`)
+		fmt.Fprintln(w, `{"status": "success"}`)
+	case "/api/generate":
+		f.mu.Lock()
+		f.loads++
+		loads := f.loads
+		f.mu.Unlock()
+		if loads < 3 {
+			http.Error(w, "loading", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"response": "", "done": true}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestTasksAreRoutedToTheirModels(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	fake := &fakeOllama{offline: true}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	t.Setenv("PRBUDDY_LLM_MODEL", "")
+	t.Setenv("PRBUDDY_NO_CACHE", "1")
+
+	if err := os.WriteFile("cmd/context.go", []byte("package cmd\n\nfunc X() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if _, err := utils.ExecGit("add", "cmd/context.go"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	// A failed fallback pull is not remembered.
+	if model, _ := llm.ModelFor(models.TaskDefault); model != llm.FallbackModel {
+		t.Errorf("Expected the fallback model while nothing is installed, got %q", model)
+	}
+	fake.mu.Lock()
+	fake.offline = false
+	fake.mu.Unlock()
+
+	// Without a selection, the most recently pulled model wins regardless of list order.
+	if _, err := llm.GenerateDraftPR("Add X", "diff"); err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	if got := fake.lastModel(); got != "newer:latest" {
+		t.Errorf("Expected the newest installed model, got %q", got)
+	}
+
+	if err := models.SaveConfig(models.Config{
+		Default: "big",
+		Tasks:   map[models.Task]string{models.TaskCommitMsg: "small"},
+	}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	if _, err := llm.GenerateCommitMessage(); err != nil {
+		t.Fatalf("GenerateCommitMessage failed: %v", err)
+	}
+	if got := fake.lastModel(); got != "small" {
+		t.Errorf("Expected commit messages to use the routed model, got %q", got)
+	}
+	if _, err := llm.GenerateDraftPR("Add X", "diff"); err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	if got := fake.lastModel(); got != "big" {
+		t.Errorf("Expected drafts to use the default model, got %q", got)
+	}
+
+	t.Setenv("PRBUDDY_LLM_MODEL", "override")
+	if model, _ := llm.ModelFor(models.TaskCommitMsg); model != "override" {
+		t.Errorf("Expected PRBUDDY_LLM_MODEL to override the saved selection, got %q", model)
+	}
+}
+
+func TestPullWaitsUntilModelIsReady(t *testing.T) {
+	fake := &fakeOllama{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	var statuses []string
+	if err := models.Pull(server.URL, "tiny", func(p models.PullProgress) {
+		statuses = append(statuses, p.Status)
+	}); err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if len(statuses) != 3 || statuses[2] != "success" {
+		t.Errorf("Unexpected pull progress: %v", statuses)
+	}
+
+	if err := models.WaitReady(server.URL, "tiny", 10*time.Second); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	if fake.loads != 3 {
+		t.Errorf("Expected readiness to be polled until the third attempt, got %d", fake.loads)
+	}
+
+	fake.loads = -100
+	if err := models.WaitReady(server.URL, "tiny", 300*time.Millisecond); err == nil {
+		t.Errorf("Expected WaitReady to time out")
+	}
+}
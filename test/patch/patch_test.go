@@ -0,0 +1,157 @@
+// test/patch/patch_test.go
+package patch
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/patch"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+const originalContext = "package cmd\n\nimport (\n\t\"fmt\"\n)\n\nfunc init() {\n\tfmt.Println(\"Initializing command package\")\n}\n\nfunc ExampleFunction() {\n\t// Example implementation\n}\n"
+
+func TestSearchReplaceEditsApplyAndUndo(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	response := "Here is the change:\n\n" +
+		"cmd/context.go\n```go\n<<<<<<< SEARCH\nfunc ExampleFunction() {\n\t// Example implementation\n}\n=======\nfunc ExampleFunction() string {\n\treturn \"example\"\n}\n>>>>>>> REPLACE\n```\n\n" +
+		"**`cmd/hello.go`**\n```go\n<<<<<<< SEARCH\n=======\npackage cmd\n\nconst Hello = \"hello\"\n>>>>>>> REPLACE\n```\n"
+
+	p, err := patch.FromResponse(repoPath, response)
+	if err != nil {
+		t.Fatalf("FromResponse failed: %v", err)
+	}
+	if strings.Join(p.Files, ",") != "cmd/context.go,cmd/hello.go" {
+		t.Errorf("Unexpected files: %v", p.Files)
+	}
+	wantHunk := "diff --git a/cmd/context.go b/cmd/context.go\n--- a/cmd/context.go\n+++ b/cmd/context.go\n@@ -8,6 +8,6 @@\n" +
+		" \tfmt.Println(\"Initializing command package\")\n }\n \n-func ExampleFunction() {\n-\t// Example implementation\n+func ExampleFunction() string {\n+\treturn \"example\"\n }\n"
+	if !strings.Contains(p.Diff, wantHunk) {
+		t.Errorf("Expected hunk:\n%s\ngot:\n%s", wantHunk, p.Diff)
+	}
+	if !strings.Contains(p.Diff, "diff --git a/cmd/hello.go b/cmd/hello.go\nnew file mode 100644\n--- /dev/null\n+++ b/cmd/hello.go\n@@ -0,0 +1,3 @@\n") {
+		t.Errorf("Expected a new-file diff, got:\n%s", p.Diff)
+	}
+
+	if _, err := p.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := readFile(t, "cmd/context.go"); !strings.Contains(got, "return \"example\"") {
+		t.Errorf("Edit not applied:\n%s", got)
+	}
+	if got := readFile(t, "cmd/hello.go"); got != "package cmd\n\nconst Hello = \"hello\"\n" {
+		t.Errorf("Unexpected new file:\n%s", got)
+	}
+
+	if _, err := patch.Undo(repoPath, false); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if got := readFile(t, "cmd/context.go"); got != originalContext {
+		t.Errorf("Expected the original file back, got:\n%s", got)
+	}
+	if _, err := os.Stat("cmd/hello.go"); !os.IsNotExist(err) {
+		t.Errorf("Expected the new file to be removed, got %v", err)
+	}
+	if _, err := patch.Undo(repoPath, false); !errors.Is(err, patch.ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUnifiedDiffIsNormalizedAndChecked(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	// No a/ b/ prefixes, a wrong line count and a blank context line without its space.
+	response := "Apply this:\n\n```diff\ndiff --git cmd/context.go cmd/context.go\nindex 123..456 100644\n--- cmd/context.go\n+++ cmd/context.go\n" +
+		"@@ -10,4 +10,9 @@\n\n func ExampleFunction() {\n-\t// Example implementation\n+\tfmt.Println(\"example\")\n }\n```\n\nThat's it."
+	p, err := patch.FromResponse(repoPath, response)
+	if err != nil {
+		t.Fatalf("FromResponse failed: %v", err)
+	}
+	if !strings.HasPrefix(p.Diff, "diff --git a/cmd/context.go b/cmd/context.go\n--- a/cmd/context.go\n+++ b/cmd/context.go\n@@ -10,4 +10,9 @@\n \n") {
+		t.Errorf("Diff not normalized:\n%s", p.Diff)
+	}
+	if err := p.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if _, err := p.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := readFile(t, "cmd/context.go"); !strings.Contains(got, "\tfmt.Println(\"example\")\n}\n") {
+		t.Errorf("Diff not applied:\n%s", got)
+	}
+
+	// A diff whose context does not match fails the check and leaves the tree alone.
+	stale, err := patch.FromResponse(repoPath, "```diff\n--- a/cmd/context.go\n+++ b/cmd/context.go\n@@ -1,1 +1,1 @@\n-package nope\n+package cmd2\n```")
+	if err != nil {
+		t.Fatalf("FromResponse failed: %v", err)
+	}
+	if _, err := stale.Apply(); err == nil || !strings.Contains(err.Error(), "do not apply cleanly") {
+		t.Errorf("Expected a failed check, got %v", err)
+	}
+
+	// Editing the file after applying blocks undo until forced.
+	if err := os.WriteFile("cmd/context.go", []byte("package cmd\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := patch.Undo(repoPath, false); !errors.Is(err, patch.ErrChangedSinceApply) {
+		t.Fatalf("Expected ErrChangedSinceApply, got %v", err)
+	}
+	if _, err := patch.Undo(repoPath, true); err != nil {
+		t.Fatalf("Forced undo failed: %v", err)
+	}
+	if got := readFile(t, "cmd/context.go"); got != originalContext {
+		t.Errorf("Expected the original file back, got:\n%s", got)
+	}
+}
+
+func TestRejectedEdits(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	if err := os.WriteFile(".gitignore", []byte("secret.env\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitignore: %v", err)
+	}
+
+	block := func(path, search, replace string) string {
+		return path + "\n<<<<<<< SEARCH\n" + search + "=======\n" + replace + ">>>>>>> REPLACE\n"
+	}
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"parent escape", block("../outside.go", "", "package x\n"), "path is outside the repository"},
+		{"absolute path", "```diff\n--- /etc/hosts\n+++ /etc/hosts\n@@ -1 +1 @@\n-a\n+b\n```", "path is outside the repository"},
+		{"git directory", block(".git/config", "", "x\n"), "access to .git is not allowed"},
+		{"ignored file", block("secret.env", "", "TOKEN=1\n"), "secret.env is ignored by git"},
+		{"search not found", block("cmd/context.go", "func Missing() {\n", "func Found() {\n"), `SEARCH block not found: "func Missing() {"`},
+		{"ambiguous search", block("cmd/context.go", "}\n", "};\n"), "matches 2 places"},
+		{"existing file", block("README.md", "", "x\n"), "empty SEARCH block for a file that already exists"},
+		{"unterminated", "cmd/context.go\n<<<<<<< SEARCH\nfunc init() {\n=======\n", "unterminated SEARCH block"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := patch.FromResponse(repoPath, tt.response)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := patch.FromResponse(repoPath, "Use `go test ./...` to run the tests.\n```go\nfmt.Println(1)\n```"); !errors.Is(err, patch.ErrNoPatch) {
+		t.Errorf("Expected ErrNoPatch for a plain answer, got %v", err)
+	}
+}
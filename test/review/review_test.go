@@ -0,0 +1,155 @@
+// test/review/review_test.go
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/review"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and records the prompts it received.
+type fakeLLMClient struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+// stageChange appends a function to cmd/context.go and stages it.
+func stageChange(t *testing.T) {
+	t.Helper()
+	data, err := os.ReadFile("cmd/context.go")
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	data = append(data, []byte("\nfunc Load(path string) []byte {\n\tdata, _ := os.ReadFile(path)\n\treturn data\n}\n")...)
+	if err := os.WriteFile("cmd/context.go", data, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := utils.ExecGit("add", "cmd/context.go"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+}
+
+func TestReviewDiffValidatesLineAnchors(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	stageChange(t)
+
+	fake := &fakeLLMClient{response: "```json\n" + `{"findings": [
+		{"file": "cmd/context.go", "start_line": 16, "end_line": 40, "severity": "high", "category": "Error-Handling", "message": "ReadFile error is discarded", "suggestion": "return the error"},
+		{"file": "cmd/context.go", "start_line": 2, "end_line": 2, "severity": "warning", "category": "style", "message": "outside the diff"},
+		{"file": "cmd/other.go", "start_line": 15, "end_line": 15, "severity": "info", "category": "style", "message": "not in diff"}
+	]}` + "\n```"}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	diff, err := review.LoadDiff(review.Scope{})
+	if err != nil {
+		t.Fatalf("LoadDiff failed: %v", err)
+	}
+	report, err := llm.ReviewDiff(diff)
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+
+	if !strings.Contains(strings.Join(fake.prompts, "\n"), "   16 +\tdata, _ := os.ReadFile(path)") {
+		t.Error("Expected the prompt to annotate diff lines with new-side line numbers")
+	}
+
+	if len(report.Findings) != 1 || len(report.Dropped) != 2 {
+		t.Fatalf("Expected 1 finding and 2 dropped, got %+v", report)
+	}
+	f := report.Findings[0]
+	if f.Severity != review.SeverityError || f.Category != "error-handling" {
+		t.Errorf("Expected normalized severity and category, got %q %q", f.Severity, f.Category)
+	}
+	if f.EndLine != 18 {
+		t.Errorf("Expected end line trimmed to the last diff line 18, got %d", f.EndLine)
+	}
+	if report.MaxSeverity() != review.SeverityError {
+		t.Errorf("Unexpected max severity %q", report.MaxSeverity())
+	}
+}
+
+func TestRenderMachineFormats(t *testing.T) {
+	report := &review.Report{Findings: []review.Finding{{
+		File: "cmd/context.go", StartLine: 15, EndLine: 16,
+		Severity: review.SeverityWarning, Category: "bug", Message: "nil map write", Suggestion: "initialize the map",
+	}}}
+
+	sarif, err := review.Render(report, review.FormatSARIF)
+	if err != nil {
+		t.Fatalf("SARIF render failed: %v", err)
+	}
+	var sarifLog struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(sarif), &sarifLog); err != nil {
+		t.Fatalf("Invalid SARIF JSON: %v", err)
+	}
+	result := sarifLog.Runs[0].Results[0]
+	if sarifLog.Version != "2.1.0" || result.RuleID != "bug" || result.Level != "warning" ||
+		result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "cmd/context.go" ||
+		result.Locations[0].PhysicalLocation.Region.StartLine != 15 {
+		t.Errorf("Unexpected SARIF output:\n%s", sarif)
+	}
+
+	rdjson, err := review.Render(report, review.FormatRDJSON)
+	if err != nil {
+		t.Fatalf("rdjson render failed: %v", err)
+	}
+	var rd struct {
+		Diagnostics []struct {
+			Message  string `json:"message"`
+			Severity string `json:"severity"`
+			Location struct {
+				Path string `json:"path"`
+			} `json:"location"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(rdjson), &rd); err != nil {
+		t.Fatalf("Invalid rdjson: %v", err)
+	}
+	if len(rd.Diagnostics) != 1 || rd.Diagnostics[0].Severity != "WARNING" ||
+		rd.Diagnostics[0].Location.Path != "cmd/context.go" || !strings.Contains(rd.Diagnostics[0].Message, "initialize the map") {
+		t.Errorf("Unexpected rdjson output:\n%s", rdjson)
+	}
+
+	if _, err := review.Render(report, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
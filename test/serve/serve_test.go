@@ -0,0 +1,115 @@
+// test/serve/serve_test.go
+package serve
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const token = "test-token"
+
+func startServer(t *testing.T) (*httptest.Server, int) {
+	t.Helper()
+	server := httptest.NewUnstartedServer(nil)
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	server.Config.Handler = llm.NewAPIHandler(token, port)
+	server.Start()
+	return server, port
+}
+
+func TestRequestsAreAuthenticated(t *testing.T) {
+	server, port := startServer(t)
+	defer server.Close()
+	t.Setenv(llm.AllowedOriginsEnv, "http://localhost:5173")
+
+	body := `{"conversationId": "c1"}`
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		origin string
+		auth   string
+		body   string
+		want   int
+	}{
+		{"valid token", "POST", "", "", "Bearer " + token, body, http.StatusOK},
+		{"missing token", "POST", "", "", "", body, http.StatusUnauthorized},
+		{"wrong token", "POST", "", "", "Bearer nope", body, http.StatusUnauthorized},
+		{"rebound host", "POST", fmt.Sprintf("evil.example:%d", port), "", "Bearer " + token, body, http.StatusForbidden},
+		{"wrong port", "POST", "localhost:1", "", "Bearer " + token, body, http.StatusForbidden},
+		{"foreign origin", "POST", "", "https://evil.example", "Bearer " + token, body, http.StatusForbidden},
+		{"webview origin", "POST", "", "vscode-webview://abc123", "Bearer " + token, body, http.StatusOK},
+		{"configured origin", "POST", "", "http://localhost:5173", "Bearer " + token, body, http.StatusOK},
+		{"preflight", "OPTIONS", "", "vscode-webview://abc123", "", "", http.StatusNoContent},
+		{"foreign preflight", "OPTIONS", "", "https://evil.example", "", "", http.StatusForbidden},
+		{"oversized body", "POST", "", "", "Bearer " + token,
+			`{"conversationId": "` + strings.Repeat("x", llm.MaxRequestBodyBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/quickassist/clear", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Failed to build request: %v", err)
+			}
+			req.Host = fmt.Sprintf("localhost:%d", port)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, resp.StatusCode)
+			}
+			if tt.want != http.StatusForbidden && tt.origin != "" && resp.Header.Get("Access-Control-Allow-Origin") != tt.origin {
+				t.Errorf("Expected the origin to be echoed, got %q", resp.Header.Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestTokenFileIsPrivate(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	if err := utils.WriteTokenFile(token); err != nil {
+		t.Fatalf("WriteTokenFile failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(cacheHome, "prbuddy-go", "token"))
+	if err != nil {
+		t.Fatalf("Token file missing: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected token file mode 0600, got %#o", perm)
+	}
+
+	got, err := utils.ReadTokenFile()
+	if err != nil || got != token {
+		t.Errorf("ReadTokenFile = %q, %v", got, err)
+	}
+	if err := utils.DeleteTokenFile(); err != nil {
+		t.Fatalf("DeleteTokenFile failed: %v", err)
+	}
+	if _, err := utils.ReadTokenFile(); err == nil {
+		t.Errorf("Expected the token file to be gone")
+	}
+}
@@ -0,0 +1,187 @@
+// test/serve/v1_test.go
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+type apiResponse struct {
+	status    int
+	requestID string
+	allow     string
+	body      map[string]interface{}
+}
+
+func (a apiResponse) errorCode() string {
+	errBody, _ := a.body["error"].(map[string]interface{})
+	code, _ := errBody["code"].(string)
+	return code
+}
+
+func call(t *testing.T, server *httptest.Server, port int, method, path, body string) apiResponse {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Host = fmt.Sprintf("localhost:%d", port)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(llm.RequestIDHeader, "req-42")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	out := apiResponse{status: resp.StatusCode, requestID: resp.Header.Get(llm.RequestIDHeader), allow: resp.Header.Get("Allow")}
+	json.NewDecoder(resp.Body).Decode(&out.body)
+	return out
+}
+
+func TestV1ErrorsCarryCodesAndRequestIDs(t *testing.T) {
+	server, port := startServer(t)
+	defer server.Close()
+
+	resp := call(t, server, port, "GET", "/v1/conversations/c1/messages", "")
+	if resp.status != http.StatusMethodNotAllowed || resp.errorCode() != llm.CodeMethodNotAllowed || resp.allow != "POST" {
+		t.Errorf("Expected 405 method_not_allowed with Allow: POST, got %d %q %q", resp.status, resp.errorCode(), resp.allow)
+	}
+	if resp.requestID != "req-42" || resp.body["error"].(map[string]interface{})["request_id"] != "req-42" {
+		t.Errorf("Expected the request ID to be echoed, got %q in %v", resp.requestID, resp.body)
+	}
+
+	if resp := call(t, server, port, "GET", "/v1/nope", ""); resp.status != http.StatusNotFound || resp.errorCode() != llm.CodeNotFound {
+		t.Errorf("Expected 404 not_found, got %d %q", resp.status, resp.errorCode())
+	}
+	if resp := call(t, server, port, "POST", "/v1/conversations/c1/messages", "{"); resp.status != http.StatusBadRequest || resp.errorCode() != llm.CodeInvalidRequest {
+		t.Errorf("Expected 400 invalid_request, got %d %q", resp.status, resp.errorCode())
+	}
+
+	// Outside a repository the server reports it instead of a generic failure.
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(t.TempDir())
+	if resp := call(t, server, port, "GET", "/v1/drafts/main/abcdef1", ""); resp.status != http.StatusConflict || resp.errorCode() != llm.CodeNotAGitRepo {
+		t.Errorf("Expected 409 not_a_git_repo, got %d %q", resp.status, resp.errorCode())
+	}
+}
+
+func TestV1LLMFailuresAreClassified(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	server, port := startServer(t)
+	defer server.Close()
+
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			http.Error(w, `{"error": "model not found"}`, http.StatusNotFound)
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+		fmt.Fprint(w, `{"message": {"role": "assistant", "content": "late"}, "done": true}`)
+	}))
+	defer ollama.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", ollama.URL)
+	t.Setenv("PRBUDDY_NO_CACHE", "1")
+	t.Setenv(llm.LLMTimeoutEnv, "100ms")
+	t.Setenv(llm.NoToolsEnv, "1") // Conversations take the streaming path
+	contextpkg.SetActiveModel("missing-model")
+	defer contextpkg.SetActiveModel("")
+
+	resp := call(t, server, port, "POST", "/v1/conversations/c1/messages", `{"input": "hi"}`)
+	if resp.status != http.StatusServiceUnavailable || resp.errorCode() != llm.CodeModelUnavailable {
+		t.Errorf("Expected 503 model_unavailable, got %d %v", resp.status, resp.body)
+	}
+
+	if err := os.WriteFile("cmd/context.go", []byte("package cmd\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	resp = call(t, server, port, "GET", "/v1/changes/summary", "")
+	if resp.status != http.StatusGatewayTimeout || resp.errorCode() != llm.CodeLLMTimeout {
+		t.Errorf("Expected 504 llm_timeout, got %d %v", resp.status, resp.body)
+	}
+}
+
+func TestV1DraftsAndOpenAPI(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	server, port := startServer(t)
+	defer server.Close()
+
+	path := "/v1/drafts/feature%2Flogin/abcdef1234"
+	if resp := call(t, server, port, "PUT", path, `{"messages": [{"role": "assistant", "content": "Draft"}]}`); resp.status != http.StatusOK {
+		t.Fatalf("PUT draft failed: %d %v", resp.status, resp.body)
+	}
+	resp := call(t, server, port, "GET", path, "")
+	if resp.status != http.StatusOK || resp.body["branch"] != "feature/login" {
+		t.Fatalf("GET draft failed: %d %v", resp.status, resp.body)
+	}
+	if messages, _ := resp.body["messages"].([]interface{}); len(messages) != 1 {
+		t.Errorf("Expected the saved message back, got %v", resp.body["messages"])
+	}
+	if resp := call(t, server, port, "DELETE", "/v1/conversations/c1", ""); resp.status != http.StatusNoContent {
+		t.Errorf("Expected 204 from DELETE, got %d", resp.status)
+	}
+
+	resp = call(t, server, port, "GET", "/v1/openapi.json", "")
+	if resp.status != http.StatusOK || resp.body["openapi"] != "3.0.3" {
+		t.Fatalf("Unexpected OpenAPI response: %d", resp.status)
+	}
+	paths := resp.body["paths"].(map[string]interface{})
+	draft, _ := paths["/v1/drafts/{branch}/{commit}"].(map[string]interface{})
+	if draft["get"] == nil || draft["put"] == nil {
+		t.Errorf("Expected GET and PUT on drafts, got %v", draft)
+	}
+	schemas := resp.body["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"V1ErrorResponse", "APIError", "V1MessageRequest", "Message", "Model"} {
+		if schemas[name] == nil {
+			t.Errorf("Expected schema %s in the OpenAPI document", name)
+		}
+	}
+}
+
+func TestSuggestionSessionsAreCapped(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	server, port := startServer(t)
+	defer server.Close()
+
+	for i := 0; i < 40; i++ {
+		if resp := call(t, server, port, "GET", fmt.Sprintf("/v1/conversations/capped-%d/suggestions", i), ""); resp.status != http.StatusOK {
+			t.Fatalf("GET suggestions failed: %d %v", resp.status, resp.body)
+		}
+	}
+
+	sessions := 0
+	dce.GetDCEContextManager().ForEachContext(func(id string, _ *dce.LittleGuy) {
+		if strings.HasPrefix(id, "capped-") {
+			sessions++
+		}
+	})
+	if sessions != 32 {
+		t.Errorf("Expected suggestion sessions to be capped at 32, got %d", sessions)
+	}
+	if _, ok := dce.GetDCEContextManager().GetContext("capped-0"); ok {
+		t.Error("Expected the least recently used session to be dropped")
+	}
+	if _, ok := dce.GetDCEContextManager().GetContext("capped-39"); !ok {
+		t.Error("Expected the newest session to be kept")
+	}
+}
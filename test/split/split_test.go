@@ -0,0 +1,218 @@
+// test/split/split_test.go
+package split
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/split"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response.
+type fakeLLMClient struct {
+	response string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+func mustGit(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := utils.ExecGit(args...)
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return out
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// numberedLines returns "line 1\n...line n\n".
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+// snapshotWorkingTree captures every file outside .git.
+func snapshotWorkingTree(t *testing.T, root string) map[string][]byte {
+	t.Helper()
+	snapshot := make(map[string][]byte)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			snapshot[path] = data
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to snapshot working tree: %v", err)
+	}
+	return snapshot
+}
+
+// setupMixedChanges stages two hunks of notes.txt, a new Go file and a README edit, then
+// leaves an unstaged edit in notes.txt and an untracked file.
+func setupMixedChanges(t *testing.T) string {
+	t.Helper()
+	repoPath := test.SetupTestRepository(t)
+	t.Cleanup(func() { test.CleanupTestRepository(t, repoPath) })
+
+	lines := numberedLines(40)
+	writeFile(t, "docs/notes.txt", strings.Join(lines, "\n")+"\n")
+	mustGit(t, "add", ".")
+	mustGit(t, "commit", "-q", "-m", "add notes")
+
+	lines[2] = "line 3 edited"
+	lines[35] = "line 36 edited"
+	writeFile(t, "docs/notes.txt", strings.Join(lines, "\n")+"\n")
+	writeFile(t, "internal/dce/extra.go", "package dce\n\nfunc Extra() {}\n")
+	writeFile(t, "README.md", "# Test Repository\nUpdated readme\n")
+	mustGit(t, "add", ".")
+
+	// Unstaged and untracked changes must survive untouched.
+	lines[20] = "line 21 unstaged"
+	writeFile(t, "docs/notes.txt", strings.Join(lines, "\n")+"\n")
+	writeFile(t, "scratch.txt", "not tracked\n")
+	return repoPath
+}
+
+func TestApplySplitKeepsWorkingTreeIdentical(t *testing.T) {
+	repoPath := setupMixedChanges(t)
+	before := snapshotWorkingTree(t, repoPath)
+	stagedTree := mustGit(t, "write-tree")
+
+	diff, err := split.StagedDiff()
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	units := split.CollectUnits(diff)
+	// README.md, two hunks of docs/notes.txt, new internal/dce/extra.go
+	if len(units) != 4 {
+		t.Fatalf("Expected 4 units, got %d: %+v", len(units), units)
+	}
+
+	llm.SetLLMClient(&fakeLLMClient{response: `{"commits": [
+		{"message": "docs: edit notes and readme", "units": ["u3", "u1", "u99"]},
+		{"message": "docs: edit notes header", "units": ["u2", "u3"]}
+	]}`})
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	plan, err := llm.ProposeSplit(units)
+	if err != nil {
+		t.Fatalf("ProposeSplit failed: %v", err)
+	}
+	if len(plan.Groups) != 3 {
+		t.Fatalf("Expected 2 proposed groups plus 1 leftover group, got %+v", plan.Groups)
+	}
+	if got := strings.Join(plan.Groups[0].Units, ","); got != "u1,u3" {
+		t.Errorf("Expected first group in diff order without unknown IDs, got %s", got)
+	}
+	if got := strings.Join(plan.Groups[1].Units, ","); got != "u2" {
+		t.Errorf("Expected duplicate u3 dropped from second group, got %s", got)
+	}
+	if plan.Groups[2].Message != "chore(dce): update internal/dce" {
+		t.Errorf("Unexpected leftover message %q", plan.Groups[2].Message)
+	}
+
+	// A post-commit hook records each commit it runs for.
+	hookLog := filepath.Join(t.TempDir(), "post-commit.log")
+	hook := "#!/bin/sh\ngit rev-parse HEAD >> '" + hookLog + "'\n"
+	if err := os.WriteFile(filepath.Join(repoPath, ".git", "hooks", "post-commit"), []byte(hook), 0755); err != nil {
+		t.Fatalf("Failed to write post-commit hook: %v", err)
+	}
+
+	headBefore := mustGit(t, "rev-parse", "HEAD")
+	shas, err := split.Apply(plan, units)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(shas) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(shas))
+	}
+	runs, _ := os.ReadFile(hookLog)
+	if got, want := strings.TrimSpace(string(runs)), mustGit(t, "rev-parse", "HEAD"); got != want {
+		t.Errorf("Expected post-commit to run once, for %s; it ran for:\n%s", want, runs)
+	}
+	if count := mustGit(t, "rev-list", "--count", headBefore+"..HEAD"); count != "3" {
+		t.Errorf("Expected 3 new commits, got %s", count)
+	}
+	if tree := mustGit(t, "rev-parse", "HEAD^{tree}"); tree != stagedTree {
+		t.Errorf("Final commit tree %s differs from staged tree %s", tree, stagedTree)
+	}
+
+	after := snapshotWorkingTree(t, repoPath)
+	if len(after) != len(before) {
+		t.Fatalf("Working tree file count changed: %d -> %d", len(before), len(after))
+	}
+	for path, data := range before {
+		if !bytes.Equal(after[path], data) {
+			t.Errorf("Working tree file %s changed", path)
+		}
+	}
+
+	if staged := mustGit(t, "diff", "--cached"); staged != "" {
+		t.Errorf("Expected nothing left staged, got:\n%s", staged)
+	}
+	if unstaged := mustGit(t, "diff"); !strings.Contains(unstaged, "+line 21 unstaged") {
+		t.Errorf("Expected the unstaged edit to remain unstaged, got:\n%s", unstaged)
+	}
+	if files := mustGit(t, "show", "--name-only", "--format=", shas[1]); files != "docs/notes.txt" {
+		t.Errorf("Expected second commit to touch only docs/notes.txt, got %q", files)
+	}
+}
+
+func TestApplyRejectsIncompletePlanWithoutCommitting(t *testing.T) {
+	setupMixedChanges(t)
+	stagedTree := mustGit(t, "write-tree")
+	headBefore := mustGit(t, "rev-parse", "HEAD")
+
+	diff, err := split.StagedDiff()
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	units := split.CollectUnits(diff)
+
+	plan := &split.Plan{Groups: []split.Group{{Message: "docs: partial", Units: []string{"u1"}}}}
+	if _, err := split.Apply(plan, units); err == nil {
+		t.Fatal("Expected an error for a plan that does not cover all staged changes")
+	}
+	if head := mustGit(t, "rev-parse", "HEAD"); head != headBefore {
+		t.Error("Expected no commits to be created")
+	}
+	if tree := mustGit(t, "write-tree"); tree != stagedTree {
+		t.Error("Expected the index to be left as it was")
+	}
+}
@@ -110,11 +110,21 @@ func CleanupTestRepository(t *testing.T, repoPath string) {
 	}
 }
 
+// IsolateTaskStore points the DCE task store at a temporary file for the rest of the
+// test, so tests running in the checkout never write to its .git directory.
+func IsolateTaskStore(t *testing.T) {
+	t.Helper()
+	dce.SetTaskStorePath(filepath.Join(t.TempDir(), "tasks.json"))
+	t.Cleanup(func() { dce.SetTaskStorePath("") })
+}
+
 // SetupDCEForTesting initializes a DCE instance for testing
 // SetupDCEForTesting initializes a DCE instance for testing
 func SetupDCEForTesting(t *testing.T, initialTask string) (string, *dce.LittleGuy) {
 	t.Helper()
 
+	IsolateTaskStore(t)
+
 	// Initialize DCE
 	dceInstance := dce.NewDCE()
 	if err := dceInstance.Activate(initialTask); err != nil {
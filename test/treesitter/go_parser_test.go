@@ -0,0 +1,110 @@
+// test/treesitter/go_parser_test.go
+package treesitter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/treesitter"
+)
+
+const storeSource = `package store
+
+import "strings"
+
+type Store struct{}
+
+type Cache[K comparable, V any] struct{}
+
+func New() *Store {
+	return &Store{}
+}
+
+func (s *Store) Save(name string) (n int, err error) {
+	name = strings.TrimSpace(name)
+	s.flush()
+	return encode(name)
+}
+
+func (Store) flush() {}
+
+func (c *Cache[K, V]) Get(key K) V {
+	var zero V
+	return zero
+}
+
+func encode(name string) (int, error) {
+	return len(name), nil
+}
+`
+
+func parse(t *testing.T) map[string]treesitter.FunctionInfo {
+	t.Helper()
+	functions, err := treesitter.ParseGoSource("internal/store/store.go", []byte(storeSource))
+	if err != nil {
+		t.Fatalf("ParseGoSource failed: %v", err)
+	}
+	byName := make(map[string]treesitter.FunctionInfo)
+	for _, fn := range functions {
+		byName[fn.Name] = fn
+	}
+	return byName
+}
+
+func TestParseGoSourceMethods(t *testing.T) {
+	functions := parse(t)
+
+	tests := []struct {
+		name      string
+		receiver  string
+		startLine int
+		returns   string
+	}{
+		{"New", "", 9, "*Store"},
+		{"Save", "Store", 13, "int error"},
+		{"flush", "Store", 19, ""},
+		{"Get", "Cache", 21, "V"},
+		{"encode", "", 26, "int error"},
+	}
+	if len(functions) != len(tests) {
+		t.Errorf("Expected %d functions, got %d", len(tests), len(functions))
+	}
+	for _, tt := range tests {
+		fn, ok := functions[tt.name]
+		if !ok {
+			t.Errorf("%s was not parsed", tt.name)
+			continue
+		}
+		if fn.Receiver != tt.receiver || fn.StartLine != tt.startLine || strings.Join(fn.Returns, " ") != tt.returns {
+			t.Errorf("%s: receiver %q, line %d, returns %v; want %q, %d, %s",
+				tt.name, fn.Receiver, fn.StartLine, fn.Returns, tt.receiver, tt.startLine, tt.returns)
+		}
+	}
+	if sig := functions["Save"].Signature; sig != "func (s *Store) Save(name string) (n int, err error)" {
+		t.Errorf("Unexpected signature %q", sig)
+	}
+}
+
+func TestParseGoSourceInvocations(t *testing.T) {
+	save := parse(t)["Save"]
+
+	// Package-qualified and method calls are recorded by the called name. encode is
+	// declared after Save, so it is an invocation rather than a utility.
+	if got := strings.Join(save.Dependencies.Invocations, " "); got != "TrimSpace flush encode" {
+		t.Errorf("Invocations = %q, want %q", got, "TrimSpace flush encode")
+	}
+}
+
+func TestFunctionInfoJSON(t *testing.T) {
+	functions := parse(t)
+
+	method, _ := json.Marshal(functions["Save"])
+	if !strings.Contains(string(method), `"receiver":"Store"`) {
+		t.Errorf("Expected methods to carry their receiver, got %s", method)
+	}
+	function, _ := json.Marshal(functions["New"])
+	if strings.Contains(string(function), `"receiver"`) {
+		t.Errorf("Expected plain functions to omit the receiver, got %s", function)
+	}
+}
@@ -0,0 +1,54 @@
+// test/utils/diff_test.go
+package utils
+
+import (
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+func TestParseUnifiedDiffPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		diff    string
+		oldPath string
+		newPath string
+	}{
+		{
+			name:    "plain path",
+			diff:    "diff --git a/cmd/root.go b/cmd/root.go\nindex 1..2 100644\n--- a/cmd/root.go\n+++ b/cmd/root.go\n@@ -1 +1 @@\n-x\n+y\n",
+			oldPath: "cmd/root.go", newPath: "cmd/root.go",
+		},
+		{
+			name:    "spaces without a content header",
+			diff:    "diff --git a/docs/my notes.md b/docs/my notes.md\nold mode 100644\nnew mode 100755\n",
+			oldPath: "docs/my notes.md", newPath: "docs/my notes.md",
+		},
+		{
+			name:    "spaces with tab-terminated headers",
+			diff:    "diff --git a/my file.go b/my file.go\nnew file mode 100644\n--- /dev/null\n+++ b/my file.go\t\n@@ -0,0 +1 @@\n+x\n",
+			oldPath: "/dev/null", newPath: "my file.go",
+		},
+		{
+			name:    "quoted non-ASCII path",
+			diff:    "diff --git \"a/t\\303\\251st.go\" \"b/t\\303\\251st.go\"\n--- \"a/t\\303\\251st.go\"\n+++ \"b/t\\303\\251st.go\"\n@@ -1 +1 @@\n-x\n+y\n",
+			oldPath: "tést.go", newPath: "tést.go",
+		},
+		{
+			name:    "rename with spaces",
+			diff:    "diff --git a/old name.go b/new name.go\nsimilarity index 100%\nrename from old name.go\nrename to new name.go\n",
+			oldPath: "old name.go", newPath: "new name.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := utils.ParseUnifiedDiff(tt.diff)
+			if len(files) != 1 {
+				t.Fatalf("Expected one file, got %d", len(files))
+			}
+			if files[0].OldPath != tt.oldPath || files[0].NewPath != tt.newPath {
+				t.Errorf("Got paths %q -> %q, want %q -> %q", files[0].OldPath, files[0].NewPath, tt.oldPath, tt.newPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// test/why/why_test.go
+package why
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/history"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// fakeLLMClient returns a canned response and records the prompts it received.
+type fakeLLMClient struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+	for _, m := range messages {
+		f.prompts = append(f.prompts, m.Content)
+	}
+	return f.response, nil
+}
+
+func (f *fakeLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+	return nil, fmt.Errorf("streaming not supported by fake client")
+}
+
+func commitFile(t *testing.T, file, content, message string) string {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+	if _, err := utils.ExecGit("commit", "-q", "-am", message); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	sha, err := utils.GetLatestCommit()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	return sha
+}
+
+func TestLocate(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"ExampleFunction", "ExampleFunction (cmd/context.go:11-13)"},
+		{"cmd/context.go:12", "ExampleFunction (cmd/context.go:11-13)"},
+		{"./cmd/context.go:8", "init (cmd/context.go:7-9)"},
+		{"cmd/context.go:4", "cmd/context.go:1-9"},
+		{"README.md:1", "README.md:1-2"},
+	}
+	for _, tt := range tests {
+		target, err := history.Locate(repoPath, tt.spec)
+		if err != nil {
+			t.Errorf("Locate(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if got := target.String(); got != tt.want {
+			t.Errorf("Locate(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"cmd/context.go:99", "Missing", "nope.go:1"} {
+		if _, err := history.Locate(repoPath, spec); err == nil {
+			t.Errorf("Expected Locate(%q) to fail", spec)
+		}
+	}
+}
+
+func TestCollectAndExplainHistory(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	content, _ := os.ReadFile("cmd/context.go")
+	withReturn := strings.Replace(string(content), "func ExampleFunction() {\n\t// Example implementation\n}",
+		"func ExampleFunction() error {\n\t// Example implementation\n\treturn nil\n}", 1)
+	first := commitFile(t, "cmd/context.go", withReturn, "Return an error from ExampleFunction\n\nCallers need to stop on failure.")
+	withLog := strings.Replace(withReturn, "\treturn nil\n", "\tfmt.Println(\"example\")\n\treturn nil\n", 1)
+	second := commitFile(t, "cmd/context.go", withLog, "Log from ExampleFunction")
+	// Uncommitted edits are not attributed to any commit.
+	if err := os.WriteFile("cmd/context.go", []byte(strings.Replace(withLog, "\"example\"", "\"changed\"", 1)), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	branch, _ := utils.GetCurrentBranch()
+	draft := []contextpkg.Message{{Role: "assistant", Content: "# Error handling\nExampleFunction now reports failures."}}
+	if err := llm.SaveDraftContext(branch, first, draft); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+
+	target, err := history.Locate(repoPath, "ExampleFunction")
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	commits, err := history.Collect(target, 0)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject)
+	}
+	if got := strings.Join(subjects, " | "); got != "Initial commit | Return an error from ExampleFunction | Log from ExampleFunction" {
+		t.Fatalf("Unexpected history, oldest first: %s", got)
+	}
+	if commits[1].SHA != first || commits[2].SHA != second {
+		t.Errorf("Unexpected commit order: %v", commits)
+	}
+	if commits[1].Body != "Callers need to stop on failure." || !strings.Contains(commits[1].Diff, "+func ExampleFunction() error {") {
+		t.Errorf("Expected the body and line diff, got %+v", commits[1])
+	}
+	if commits[0].BlamedLines != 2 || commits[1].BlamedLines != 2 || commits[2].BlamedLines != 0 {
+		t.Errorf("Unexpected blame counts: %d, %d, %d", commits[0].BlamedLines, commits[1].BlamedLines, commits[2].BlamedLines)
+	}
+
+	// With a single commit of log history, blame still brings in the older commits.
+	limited, err := history.Collect(target, 1)
+	if err != nil || len(limited) != 3 || limited[0].Subject != "Initial commit" {
+		t.Errorf("Expected blamed commits beyond the limit, got %+v, %v", limited, err)
+	}
+
+	fake := &fakeLLMClient{response: "It grew error handling in " + first[:7] + "."}
+	llm.SetLLMClient(fake)
+	defer llm.SetLLMClient(&llm.DefaultLLMClient{})
+
+	source, err := history.Source(repoPath, target)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+	narrative, err := llm.ExplainHistory(target, source, commits)
+	if err != nil || narrative != fake.response {
+		t.Fatalf("ExplainHistory = %q, %v", narrative, err)
+	}
+	if commits[1].Draft == "" || commits[0].Draft != "" {
+		t.Errorf("Expected only the second commit to carry a draft")
+	}
+	prompt := fake.prompts[len(fake.prompts)-1]
+	for _, want := range []string{
+		"ExampleFunction (cmd/context.go:11-15)",
+		"fmt.Println(\"changed\")",
+		"### " + first[:7],
+		"Callers need to stop on failure.",
+		"PR draft:\n# Error handling",
+		"Last touched 2 of the current lines.",
+		"+\tfmt.Println(\"example\")",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestCollectWithUncommittedLinesAbove(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	// Ten new lines push ExampleFunction past the end of the file in HEAD.
+	content, _ := os.ReadFile("cmd/context.go")
+	added := strings.Repeat("// Uncommitted\n", 10)
+	dirty := strings.Replace(string(content), "func ExampleFunction", added+"func ExampleFunction", 1)
+	if err := os.WriteFile("cmd/context.go", []byte(dirty), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	readme, _ := os.ReadFile("README.md")
+	if err := os.WriteFile("README.md", []byte(added+string(readme)), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	for _, spec := range []string{"ExampleFunction", "README.md:12"} {
+		target, err := history.Locate(repoPath, spec)
+		if err != nil {
+			t.Fatalf("Locate(%q) failed: %v", spec, err)
+		}
+		commits, err := history.Collect(target, 0)
+		if err != nil {
+			t.Fatalf("Collect(%s) failed: %v", target, err)
+		}
+		if len(commits) != 1 || commits[0].Subject != "Initial commit" || commits[0].Diff == "" {
+			t.Errorf("Expected the initial commit with its line diff for %s, got %+v", target, commits)
+		}
+	}
+}
+
+func TestFindDraftForCommitPrefersLatest(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	sha, err := utils.GetLatestCommit()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+	for _, branch := range []string{"alpha", "zulu"} {
+		draft := []contextpkg.Message{{Role: "assistant", Content: "Draft from " + branch}}
+		if err := llm.SaveDraftContext(branch, sha, draft); err != nil {
+			t.Fatalf("Failed to save draft: %v", err)
+		}
+	}
+	// The alphabetically first draft is the older one.
+	old := time.Now().Add(-time.Hour)
+	alpha := filepath.Join(repoPath, ".git", "pr_buddy_db", "alpha-"+sha[:7], "draft_context.json")
+	if err := os.Chtimes(alpha, old, old); err != nil {
+		t.Fatalf("Failed to age draft: %v", err)
+	}
+
+	if draft, ok := llm.FindDraftForCommit(sha); !ok || draft != "Draft from zulu" {
+		t.Errorf("Expected the latest draft, got %q (found %v)", draft, ok)
+	}
+}